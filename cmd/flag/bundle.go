@@ -4,6 +4,7 @@ import "github.com/spf13/cobra"
 
 type BundleFlagValues struct {
 	Extract          bool
+	Create           bool
 	BulkRegistration bool
 	DataType         string
 }
@@ -14,6 +15,8 @@ var (
 
 func SetBundleFlags(command *cobra.Command) {
 	command.Flags().BoolVarP(&bundleFlagValues.Extract, "extract", "x", false, "Extract")
+	// -c is taken by --config
+	command.Flags().BoolVar(&bundleFlagValues.Create, "create", false, "Create an archive from a collection")
 	command.Flags().BoolVarP(&bundleFlagValues.BulkRegistration, "bulk", "b", false, "Enable bulk registration")
 	command.Flags().StringVarP(&bundleFlagValues.DataType, "data_type", "D", "", "Set data type (tar, zip ...)")
 }
@@ -21,6 +21,8 @@ type BundleConfigFlagValues struct {
 	MaxFileNum         int
 	MaxFileSize        int64
 	NoBulkRegistration bool
+	CompareThreadNum   int
+	BundleFormat       string
 	maxFileSizeInput   string
 }
 
@@ -35,6 +37,11 @@ func SetBundleTempFlags(command *cobra.Command) {
 	command.Flags().StringVar(&bundleTempFlagValues.IRODSTempPath, "irods_temp", "", "Specify iRODS temp collection path to upload bundle files to")
 }
 
+// SetLocalTempFlags sets the local temp dir flag only, for commands not transferring bundles
+func SetLocalTempFlags(command *cobra.Command) {
+	command.Flags().StringVar(&bundleTempFlagValues.LocalTempPath, "local_temp", os.TempDir(), "Specify local temp directory path to create upload status files for resuming uploads")
+}
+
 func GetBundleTempFlagValues() *BundleTempFlagValues {
 	return &bundleTempFlagValues
 }
@@ -51,6 +58,8 @@ func SetBundleConfigFlags(command *cobra.Command) {
 	command.Flags().IntVar(&bundleConfigFlagValues.MaxFileNum, "max_file_num", commons.MaxBundleFileNumDefault, "Specify max file number in a bundle file")
 	command.Flags().StringVar(&bundleConfigFlagValues.maxFileSizeInput, "max_file_size", strconv.FormatInt(commons.MaxBundleFileSizeDefault, 10), "Specify max file size of a bundle file")
 	command.Flags().BoolVar(&bundleConfigFlagValues.NoBulkRegistration, "no_bulk_reg", false, "Disable bulk registration")
+	command.Flags().StringVar(&bundleConfigFlagValues.BundleFormat, "bundle_format", commons.BundleFormatTar, "Specify bundle file format (tar, tgz, tbz2, zip)")
+	command.Flags().IntVar(&bundleConfigFlagValues.CompareThreadNum, "compare_thread_num", commons.CompareThreadNumDefault, "Specify the number of threads to compare local files with iRODS for --diff")
 }
 
 func GetBundleConfigFlagValues() *BundleConfigFlagValues {
@@ -0,0 +1,39 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type ChecksumFlagValues struct {
+	Verify bool
+}
+
+var (
+	checksumFlagValues ChecksumFlagValues
+)
+
+func SetChecksumFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&checksumFlagValues.Verify, "verify", false, "Verify replicas against registered checksums")
+}
+
+func GetChecksumFlagValues() *ChecksumFlagValues {
+	return &checksumFlagValues
+}
+
+type VerifyChecksumFlagValues struct {
+	VerifyChecksum bool
+	KeepMismatched bool
+}
+
+var (
+	verifyChecksumFlagValues VerifyChecksumFlagValues
+)
+
+func SetVerifyChecksumFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&verifyChecksumFlagValues.VerifyChecksum, "verify_checksum", false, "Verify checksums of transferred files, mismatched copies are removed")
+	command.Flags().BoolVar(&verifyChecksumFlagValues.KeepMismatched, "keep_mismatched", false, "Keep copies failed in checksum verification")
+}
+
+func GetVerifyChecksumFlagValues() *VerifyChecksumFlagValues {
+	return &verifyChecksumFlagValues
+}
@@ -74,7 +74,6 @@ func ProcessCommonFlags(command *cobra.Command) (bool, error) {
 	})
 
 	myCommonFlagValues := GetCommonFlagValues(command)
-	retryFlagValues := GetRetryFlagValues()
 
 	if myCommonFlagValues.DebugMode {
 		log.SetLevel(log.DebugLevel)
@@ -152,14 +151,6 @@ func ProcessCommonFlags(command *cobra.Command) (bool, error) {
 		}
 	}
 
-	if retryFlagValues.RetryChild {
-		// read from stdin
-		err := commons.InputMissingFieldsFromStdin()
-		if err != nil {
-			return false, xerrors.Errorf("failed to load config from stdin: %w", err) // stop here
-		}
-	}
-
 	appConfig := commons.GetConfig()
 
 	syncAccount := false
@@ -0,0 +1,23 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type ContinueOnErrorFlagValues struct {
+	ContinueOnError bool
+	FailedListPath  string
+}
+
+var (
+	continueOnErrorFlagValues ContinueOnErrorFlagValues
+)
+
+func SetContinueOnErrorFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&continueOnErrorFlagValues.ContinueOnError, "continue_on_error", false, "Continue transferring other files on errors, and report failures at the end")
+	command.Flags().StringVar(&continueOnErrorFlagValues.FailedListPath, "failed_list", "", "Write paths failed to the file, to be used with --from_list, requires --continue_on_error")
+}
+
+func GetContinueOnErrorFlagValues() *ContinueOnErrorFlagValues {
+	return &continueOnErrorFlagValues
+}
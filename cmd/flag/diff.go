@@ -0,0 +1,23 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type DiffFlagValues struct {
+	JSON bool
+	TSV  bool
+}
+
+var (
+	diffFlagValues DiffFlagValues
+)
+
+func SetDiffFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&diffFlagValues.JSON, "json", false, "Print differences in JSON format")
+	command.Flags().BoolVar(&diffFlagValues.TSV, "tsv", false, "Print differences in TSV format")
+}
+
+func GetDiffFlagValues() *DiffFlagValues {
+	return &diffFlagValues
+}
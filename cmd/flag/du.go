@@ -0,0 +1,25 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type DuFlagValues struct {
+	MaxDepth           int
+	ByResource         bool
+	HumanReadableSizes bool
+}
+
+var (
+	duFlagValues DuFlagValues
+)
+
+func SetDuFlags(command *cobra.Command) {
+	command.Flags().IntVar(&duFlagValues.MaxDepth, "max_depth", -1, "Display totals of sub-collections only N or fewer levels below the given collection")
+	command.Flags().BoolVar(&duFlagValues.ByResource, "by_resource", false, "Split totals by resource")
+	command.Flags().BoolVarP(&duFlagValues.HumanReadableSizes, "human_readable", "H", false, "Display sizes in human-readable format")
+}
+
+func GetDuFlagValues() *DuFlagValues {
+	return &duFlagValues
+}
@@ -0,0 +1,31 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type FindFlagValues struct {
+	MetaConditions []string
+	NamePattern    string
+	Size           string
+	ModifyTime     string
+	Type           string
+	Print0         bool
+}
+
+var (
+	findFlagValues FindFlagValues
+)
+
+func SetFindFlags(command *cobra.Command) {
+	command.Flags().StringArrayVar(&findFlagValues.MetaConditions, "meta", []string{}, "Find data-objects having the metadata [name(=|!=|<|<=|>|>=|~=)value], numbers are compared as numbers with <, <=, > and >=, can be given multiple times")
+	command.Flags().StringVar(&findFlagValues.NamePattern, "name", "", "Find entries with names matching the given glob pattern (e.g., '*.cram')")
+	command.Flags().StringVar(&findFlagValues.Size, "size", "", "Find data-objects with the size, larger with + prefix, smaller with - prefix (e.g., +1G)")
+	command.Flags().StringVar(&findFlagValues.ModifyTime, "mtime", "", "Find entries modified the given days ago, within with - prefix, before with + prefix, units s|m|h|d can be given (e.g., -7, +12h)")
+	command.Flags().StringVar(&findFlagValues.Type, "type", "", "Find only data-objects (f) or collections (d)")
+	command.Flags().BoolVarP(&findFlagValues.Print0, "print0", "0", false, "Print paths separated by NUL characters, for xargs -0")
+}
+
+func GetFindFlagValues() *FindFlagValues {
+	return &findFlagValues
+}
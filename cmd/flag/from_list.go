@@ -0,0 +1,21 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type FromListFlagValues struct {
+	FromListPath string
+}
+
+var (
+	fromListFlagValues FromListFlagValues
+)
+
+func SetFromListFlags(command *cobra.Command) {
+	command.Flags().StringVar(&fromListFlagValues.FromListPath, "from_list", "", "Transfer only files listed in the file, one path per line, e.g., a list written with --failed_list")
+}
+
+func GetFromListFlagValues() *FromListFlagValues {
+	return &fromListFlagValues
+}
@@ -0,0 +1,26 @@
+package flag
+
+import (
+	"github.com/cyverse/gocommands/commons"
+	"github.com/spf13/cobra"
+)
+
+type HashCacheFlagValues struct {
+	UseHashCache   bool
+	HashCachePath  string
+	PruneHashCache bool
+}
+
+var (
+	hashCacheFlagValues HashCacheFlagValues
+)
+
+func SetHashCacheFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&hashCacheFlagValues.UseHashCache, "hash_cache", false, "Cache hashes of local files to not rehash unchanged files")
+	command.Flags().StringVar(&hashCacheFlagValues.HashCachePath, "hash_cache_path", commons.HashCacheFilePathDefault, "Specify hash cache file path")
+	command.Flags().BoolVar(&hashCacheFlagValues.PruneHashCache, "prune_hash_cache", false, "Drop cached hashes of local files that no longer exist, this checks all cached files")
+}
+
+func GetHashCacheFlagValues() *HashCacheFlagValues {
+	return &hashCacheFlagValues
+}
@@ -0,0 +1,48 @@
+package flag
+
+import (
+	"github.com/cyverse/gocommands/commons"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+type LimitRateFlagValues struct {
+	LimitRate      int64
+	limitRateInput string
+}
+
+var (
+	limitRateFlagValues LimitRateFlagValues
+)
+
+func SetLimitRateFlags(command *cobra.Command) {
+	command.Flags().StringVar(&limitRateFlagValues.limitRateInput, "limit_rate", "", "Limit transfer bandwidth per second shared by all threads, e.g., 200MB (default from config gocmd_limit_rate, no limit if not set)")
+}
+
+func GetLimitRateFlagValues() (*LimitRateFlagValues, error) {
+	limitRateInput := limitRateFlagValues.limitRateInput
+	source := "--limit_rate"
+	if len(limitRateInput) == 0 {
+		config := commons.GetConfig()
+		if config != nil {
+			limitRateInput = config.LimitRate
+			source = "gocmd_limit_rate"
+		}
+	}
+
+	limitRateFlagValues.LimitRate = 0
+	if len(limitRateInput) > 0 {
+		rate, err := commons.ParseSize(limitRateInput)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to parse %s '%s': %w", source, limitRateInput, err)
+		}
+
+		if rate < 0 {
+			return nil, xerrors.Errorf("invalid %s '%s', must not be negative", source, limitRateInput)
+		}
+
+		limitRateFlagValues.LimitRate = rate
+	}
+
+	return &limitRateFlagValues, nil
+}
@@ -1,7 +1,12 @@
 package flag
 
 import (
+	"strings"
+	"time"
+
+	"github.com/cyverse/gocommands/commons"
 	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
 )
 
 type ListFormat string
@@ -12,6 +17,14 @@ const (
 	ListFormatVeryLong ListFormat = "verylong"
 )
 
+type ListSortOrder string
+
+const (
+	ListSortOrderName ListSortOrder = "name"
+	ListSortOrderSize ListSortOrder = "size"
+	ListSortOrderTime ListSortOrder = "time"
+)
+
 type ListFlagValues struct {
 	Format              ListFormat
 	longFormatInput     bool
@@ -19,8 +32,29 @@ type ListFlagValues struct {
 	HumanReadableSizes  bool
 }
 
+type ListACLFlagValues struct {
+	ShowACL bool
+}
+
+type ListSortFlagValues struct {
+	SortOrder      ListSortOrder
+	sortOrderInput string
+	Reverse        bool
+}
+
+type ListFilterFlagValues struct {
+	MinSize        int64
+	minSizeInput   string
+	NewerThan      time.Time
+	newerThanInput string
+	NamePattern    string
+}
+
 var (
-	listFlagValues ListFlagValues
+	listFlagValues       ListFlagValues
+	listACLFlagValues    ListACLFlagValues
+	listSortFlagValues   ListSortFlagValues
+	listFilterFlagValues ListFilterFlagValues
 )
 
 func SetListFlags(command *cobra.Command) {
@@ -40,3 +74,59 @@ func GetListFlagValues() *ListFlagValues {
 
 	return &listFlagValues
 }
+
+func SetListACLFlags(command *cobra.Command) {
+	command.Flags().BoolVarP(&listACLFlagValues.ShowACL, "access", "A", false, "Display access control lists")
+}
+
+func GetListACLFlagValues() *ListACLFlagValues {
+	return &listACLFlagValues
+}
+
+func SetListSortFlags(command *cobra.Command) {
+	command.Flags().StringVar(&listSortFlagValues.sortOrderInput, "sort", string(ListSortOrderName), "Sort entries by [name|size|time]")
+	command.Flags().BoolVar(&listSortFlagValues.Reverse, "reverse", false, "Sort entries in reverse order")
+}
+
+func GetListSortFlagValues() *ListSortFlagValues {
+	switch ListSortOrder(strings.ToLower(listSortFlagValues.sortOrderInput)) {
+	case ListSortOrderSize:
+		listSortFlagValues.SortOrder = ListSortOrderSize
+	case ListSortOrderTime:
+		listSortFlagValues.SortOrder = ListSortOrderTime
+	default:
+		listSortFlagValues.SortOrder = ListSortOrderName
+	}
+
+	return &listSortFlagValues
+}
+
+func SetListFilterFlags(command *cobra.Command) {
+	command.Flags().StringVar(&listFilterFlagValues.minSizeInput, "min_size", "0", "List data-objects larger than or equal to the given size")
+	command.Flags().StringVar(&listFilterFlagValues.newerThanInput, "newer_than", "", "List data-objects modified after the given time, 'YYYY-MM-DD hh:mm:ss' or a duration (e.g., 7d, 12h)")
+	command.Flags().StringVar(&listFilterFlagValues.NamePattern, "name", "", "List data-objects with names matching the given glob pattern (e.g., '*.fastq')")
+}
+
+func GetListFilterFlagValues() (*ListFilterFlagValues, error) {
+	size, err := commons.ParseSize(listFilterFlagValues.minSizeInput)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse --min_size '%s': %w", listFilterFlagValues.minSizeInput, err)
+	}
+	listFilterFlagValues.MinSize = size
+
+	listFilterFlagValues.NewerThan = time.Time{}
+	if len(listFilterFlagValues.newerThanInput) > 0 {
+		age, err := commons.ParseTime(listFilterFlagValues.newerThanInput)
+		if err == nil {
+			listFilterFlagValues.NewerThan = time.Now().Add(-time.Duration(age) * time.Second)
+		} else {
+			newerThan, err := commons.MakeDateTimeFromString(listFilterFlagValues.newerThanInput)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to parse --newer_than '%s', must be a duration or 'YYYY-MM-DD hh:mm:ss': %w", listFilterFlagValues.newerThanInput, err)
+			}
+			listFilterFlagValues.NewerThan = newerThan
+		}
+	}
+
+	return &listFilterFlagValues, nil
+}
@@ -0,0 +1,66 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type MetaTargetType string
+
+const (
+	MetaTargetTypePath     MetaTargetType = "path"
+	MetaTargetTypeUser     MetaTargetType = "user"
+	MetaTargetTypeResource MetaTargetType = "resource"
+)
+
+type MetaTargetFlagValues struct {
+	Type          MetaTargetType
+	userInput     bool
+	resourceInput bool
+}
+
+type MetaUpdateFlagValues struct {
+	Name         string
+	NameUpdated  bool
+	Value        string
+	ValueUpdated bool
+	Units        string
+	UnitsUpdated bool
+}
+
+var (
+	metaTargetFlagValues MetaTargetFlagValues
+	metaUpdateFlagValues MetaUpdateFlagValues
+)
+
+func SetMetaTargetFlags(command *cobra.Command) {
+	command.Flags().BoolVarP(&metaTargetFlagValues.userInput, "user", "u", false, "Treat the target as a user")
+	command.Flags().BoolVar(&metaTargetFlagValues.resourceInput, "resc", false, "Treat the target as a resource")
+
+	command.MarkFlagsMutuallyExclusive("user", "resc")
+}
+
+func GetMetaTargetFlagValues() *MetaTargetFlagValues {
+	if metaTargetFlagValues.userInput {
+		metaTargetFlagValues.Type = MetaTargetTypeUser
+	} else if metaTargetFlagValues.resourceInput {
+		metaTargetFlagValues.Type = MetaTargetTypeResource
+	} else {
+		metaTargetFlagValues.Type = MetaTargetTypePath
+	}
+
+	return &metaTargetFlagValues
+}
+
+func SetMetaUpdateFlags(command *cobra.Command) {
+	command.Flags().StringVar(&metaUpdateFlagValues.Name, "new_name", "", "Set new attribute name")
+	command.Flags().StringVar(&metaUpdateFlagValues.Value, "new_value", "", "Set new value")
+	command.Flags().StringVar(&metaUpdateFlagValues.Units, "new_units", "", "Set new units, empty to clear units")
+}
+
+func GetMetaUpdateFlagValues(command *cobra.Command) *MetaUpdateFlagValues {
+	metaUpdateFlagValues.NameUpdated = command.Flags().Changed("new_name")
+	metaUpdateFlagValues.ValueUpdated = command.Flags().Changed("new_value")
+	metaUpdateFlagValues.UnitsUpdated = command.Flags().Changed("new_units")
+
+	return &metaUpdateFlagValues
+}
@@ -0,0 +1,62 @@
+package flag
+
+import (
+	"github.com/cyverse/gocommands/commons"
+	"github.com/spf13/cobra"
+)
+
+type ReadRangeFlagValues struct {
+	Offset      int64
+	offsetInput string
+	Length      int64
+	lengthInput string
+}
+
+type HeadTailFlagValues struct {
+	Bytes      int64
+	bytesInput string
+	Lines      int64
+}
+
+var (
+	readRangeFlagValues ReadRangeFlagValues
+	headTailFlagValues  HeadTailFlagValues
+)
+
+func SetReadRangeFlags(command *cobra.Command) {
+	command.Flags().StringVar(&readRangeFlagValues.offsetInput, "offset", "0", "Start reading from the given offset")
+	command.Flags().StringVar(&readRangeFlagValues.lengthInput, "length", "", "Read only the given length (default to the end)")
+}
+
+func GetReadRangeFlagValues() *ReadRangeFlagValues {
+	offset, _ := commons.ParseSize(readRangeFlagValues.offsetInput)
+	readRangeFlagValues.Offset = offset
+
+	readRangeFlagValues.Length = -1
+	if len(readRangeFlagValues.lengthInput) > 0 {
+		length, err := commons.ParseSize(readRangeFlagValues.lengthInput)
+		if err == nil {
+			readRangeFlagValues.Length = length
+		}
+	}
+
+	return &readRangeFlagValues
+}
+
+func SetHeadTailFlags(command *cobra.Command) {
+	// -c is used by config flag
+	command.Flags().StringVar(&headTailFlagValues.bytesInput, "bytes", "", "Display the given number of bytes instead of lines")
+	command.Flags().Int64VarP(&headTailFlagValues.Lines, "lines", "n", 10, "Display the given number of lines")
+}
+
+func GetHeadTailFlagValues() *HeadTailFlagValues {
+	headTailFlagValues.Bytes = -1
+	if len(headTailFlagValues.bytesInput) > 0 {
+		bytes, err := commons.ParseSize(headTailFlagValues.bytesInput)
+		if err == nil {
+			headTailFlagValues.Bytes = bytes
+		}
+	}
+
+	return &headTailFlagValues
+}
@@ -0,0 +1,34 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type ReplicaSourceFlagValues struct {
+	SourceResource string
+}
+
+type TrimFlagValues struct {
+	MinCopies int
+}
+
+var (
+	replicaSourceFlagValues ReplicaSourceFlagValues
+	trimFlagValues          TrimFlagValues
+)
+
+func SetReplicaSourceFlags(command *cobra.Command) {
+	command.Flags().StringVarP(&replicaSourceFlagValues.SourceResource, "source_resource", "S", "", "Specify source resource of replicas")
+}
+
+func GetReplicaSourceFlagValues() *ReplicaSourceFlagValues {
+	return &replicaSourceFlagValues
+}
+
+func SetTrimFlags(command *cobra.Command) {
+	command.Flags().IntVarP(&trimFlagValues.MinCopies, "keep_copies", "N", 0, "Specify minimum number of replicas to keep")
+}
+
+func GetTrimFlagValues() *TrimFlagValues {
+	return &trimFlagValues
+}
@@ -0,0 +1,21 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type ResourceTreeFlagValues struct {
+	ShowTree bool
+}
+
+var (
+	resourceTreeFlagValues ResourceTreeFlagValues
+)
+
+func SetResourceTreeFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&resourceTreeFlagValues.ShowTree, "tree", false, "Display resources in a hierarchy tree")
+}
+
+func GetResourceTreeFlagValues() *ResourceTreeFlagValues {
+	return &resourceTreeFlagValues
+}
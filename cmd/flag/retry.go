@@ -7,7 +7,6 @@ import (
 type RetryFlagValues struct {
 	RetryNumber          int
 	RetryIntervalSeconds int
-	RetryChild           bool
 }
 
 var (
@@ -15,12 +14,8 @@ var (
 )
 
 func SetRetryFlags(command *cobra.Command) {
-	command.Flags().IntVar(&retryFlagValues.RetryNumber, "retry", 0, "Retry if fails")
-	command.Flags().IntVar(&retryFlagValues.RetryIntervalSeconds, "retry_interval", 60, "Retry interval in seconds")
-
-	// this is hidden
-	command.Flags().BoolVar(&retryFlagValues.RetryChild, "retry_child", false, "Set this to retry child process")
-	command.Flags().MarkHidden("retry_child")
+	command.Flags().IntVar(&retryFlagValues.RetryNumber, "retry", 0, "Retry failed transfers of files with connection errors")
+	command.Flags().IntVar(&retryFlagValues.RetryIntervalSeconds, "retry_interval", 5, "Initial retry interval in seconds, doubled on each retry")
 }
 
 func GetRetryFlagValues() *RetryFlagValues {
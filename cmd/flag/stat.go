@@ -0,0 +1,23 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type StatFlagValues struct {
+	JSON     bool
+	ShowMeta bool
+}
+
+var (
+	statFlagValues StatFlagValues
+)
+
+func SetStatFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&statFlagValues.JSON, "json", false, "Print in JSON format")
+	command.Flags().BoolVarP(&statFlagValues.ShowMeta, "meta", "M", false, "Display metadata")
+}
+
+func GetStatFlagValues() *StatFlagValues {
+	return &statFlagValues
+}
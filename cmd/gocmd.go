@@ -8,6 +8,7 @@ import (
 	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
 	"github.com/cyverse/gocommands/cmd/flag"
 	"github.com/cyverse/gocommands/cmd/subcmd"
+	"github.com/cyverse/gocommands/commons"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -79,6 +80,8 @@ func main() {
 	subcmd.AddCpCommand(rootCmd)
 	subcmd.AddMvCommand(rootCmd)
 	subcmd.AddCatCommand(rootCmd)
+	subcmd.AddHeadCommand(rootCmd)
+	subcmd.AddTailCommand(rootCmd)
 	subcmd.AddGetCommand(rootCmd)
 	subcmd.AddPutCommand(rootCmd)
 	subcmd.AddSyncCommand(rootCmd)
@@ -87,6 +90,7 @@ func main() {
 	subcmd.AddRmdirCommand(rootCmd)
 	subcmd.AddBunCommand(rootCmd)
 	subcmd.AddBputCommand(rootCmd)
+	subcmd.AddBgetCommand(rootCmd)
 	subcmd.AddSvrinfoCommand(rootCmd)
 	subcmd.AddPsCommand(rootCmd)
 	subcmd.AddCopySftpIdCommand(rootCmd)
@@ -95,10 +99,26 @@ func main() {
 	subcmd.AddMkticketCommand(rootCmd)
 	subcmd.AddModticketCommand(rootCmd)
 	subcmd.AddBcleanCommand(rootCmd)
+	subcmd.AddMetaCommand(rootCmd)
+	subcmd.AddFindCommand(rootCmd)
+	subcmd.AddChmodCommand(rootCmd)
+	subcmd.AddChksumCommand(rootCmd)
+	subcmd.AddReplCommand(rootCmd)
+	subcmd.AddTrimCommand(rootCmd)
+	subcmd.AddPhymvCommand(rootCmd)
+	subcmd.AddLsrescCommand(rootCmd)
+	subcmd.AddDuCommand(rootCmd)
+	subcmd.AddStatCommand(rootCmd)
+	subcmd.AddDiffCommand(rootCmd)
 	subcmd.AddUpgradeCommand(rootCmd)
 
 	err := Execute()
 	if err != nil {
+		if commons.IsDifferencesFoundError(err) {
+			// differences are already printed
+			os.Exit(1)
+		}
+
 		logger.Errorf("%+v", err)
 
 		if os.IsNotExist(err) {
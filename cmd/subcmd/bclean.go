@@ -13,8 +13,9 @@ var bcleanCmd = &cobra.Command{
 	Use:     "bclean [collection]",
 	Aliases: []string{"bundle_clean"},
 	Short:   "Clean bundle staging directories",
-	Long:    `This cleans bundle files created by 'bput' or 'sync' for uploading data to the given iRODS collection.`,
-	RunE:    processBcleanCommand,
+	Long: `This cleans bundle files created by 'bput' or 'sync' for uploading data to the given iRODS collection, and staging collections created by 'bget' for downloading data.
+Upload status files left by 'put' in the local temp directory are also removed.`,
+	RunE: processBcleanCommand,
 }
 
 func AddBcleanCommand(rootCmd *cobra.Command) {
@@ -68,8 +69,8 @@ func processBcleanCommand(command *cobra.Command, args []string) error {
 		logger.Debugf("clearing irods temp dir %s", bundleTempFlagValues.IRODSTempPath)
 		commons.CleanUpOldIRODSBundles(filesystem, bundleTempFlagValues.IRODSTempPath, true, forceFlagValues.Force)
 	} else {
-		userHome := commons.GetHomeDir()
-		homeStagingDir := commons.GetDefaultStagingDir(userHome)
+		// the staging dir in the home dir is also used by 'bget' for staging collections
+		homeStagingDir := commons.GetDefaultStagingDirForDownload()
 		commons.CleanUpOldIRODSBundles(filesystem, homeStagingDir, true, forceFlagValues.Force)
 	}
 
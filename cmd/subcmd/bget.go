@@ -0,0 +1,241 @@
+package subcmd
+
+import (
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var bgetCmd = &cobra.Command{
+	Use:     "bget [data-object1] [data-object2] [collection1] ... [local dir]",
+	Aliases: []string{"bundle_get"},
+	Short:   "Bundle-download data-objects or collections",
+	Long: `This downloads data-objects or collections to the given local path. Small data-objects are copied to a staging collection and bundled with TAR in the iRODS to maximize data transfer bandwidth, then extracted locally.
+Large data-objects are downloaded directly.`,
+	RunE: processBgetCommand,
+	Args: cobra.MinimumNArgs(1),
+}
+
+func AddBgetCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(bgetCmd)
+
+	flag.SetBundleTempFlags(bgetCmd)
+	flag.SetBundleClearFlags(bgetCmd)
+	flag.SetBundleConfigFlags(bgetCmd)
+	flag.SetParallelTransferFlags(bgetCmd, true)
+	flag.SetProgressFlags(bgetCmd)
+	flag.SetRetryFlags(bgetCmd)
+	flag.SetDifferentialTransferFlags(bgetCmd, true)
+	flag.SetNoRootFlags(bgetCmd)
+	flag.SetHashCacheFlags(bgetCmd)
+	flag.SetLimitRateFlags(bgetCmd)
+	flag.SetContinueOnErrorFlags(bgetCmd)
+	flag.SetFromListFlags(bgetCmd)
+
+	rootCmd.AddCommand(bgetCmd)
+}
+
+func processBgetCommand(command *cobra.Command, args []string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "processBgetCommand",
+	})
+
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	bundleTempFlagValues := flag.GetBundleTempFlagValues()
+	bundleClearFlagValues := flag.GetBundleClearFlagValues()
+	bundleConfigFlagValues := flag.GetBundleConfigFlagValues()
+	parallelTransferFlagValues := flag.GetParallelTransferFlagValues()
+	progressFlagValues := flag.GetProgressFlagValues()
+	retryFlagValues := flag.GetRetryFlagValues()
+	differentialTransferFlagValues := flag.GetDifferentialTransferFlagValues()
+	noRootFlagValues := flag.GetNoRootFlagValues()
+	hashCacheFlagValues := flag.GetHashCacheFlagValues()
+	limitRateFlagValues, err := flag.GetLimitRateFlagValues()
+	if err != nil {
+		return xerrors.Errorf("failed to get limit rate: %w", err)
+	}
+	continueOnErrorFlagValues := flag.GetContinueOnErrorFlagValues()
+	fromListFlagValues := flag.GetFromListFlagValues()
+
+	if bundleConfigFlagValues.BundleFormat != commons.BundleFormatTar {
+		return xerrors.Errorf("bget supports only %s bundle format", commons.BundleFormatTar)
+	}
+
+	maxConnectionNum := parallelTransferFlagValues.ThreadNumber + 2 + commons.BundleStageThreadNumDefault // 2 for metadata op
+
+	// clear local
+	if bundleClearFlagValues.Clear {
+		commons.CleanUpOldLocalBundles(bundleTempFlagValues.LocalTempPath, true)
+	}
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClientAdvanced(account, maxConnectionNum, parallelTransferFlagValues.TCPBufferSize)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	targetPath := "./"
+	sourcePaths := args[:]
+
+	if len(args) >= 2 {
+		targetPath = args[len(args)-1]
+		sourcePaths = args[:len(args)-1]
+	}
+
+	if noRootFlagValues.NoRoot && len(sourcePaths) > 1 {
+		return xerrors.Errorf("failed to bget multiple source collections without creating root directory")
+	}
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	for idx, sourcePath := range sourcePaths {
+		sourcePaths[idx] = commons.MakeIRODSPath(cwd, home, zone, sourcePath)
+	}
+
+	targetPath = commons.MakeLocalPath(targetPath)
+
+	logger.Info("determining staging dir...")
+	if len(bundleTempFlagValues.IRODSTempPath) > 0 {
+		bundleTempFlagValues.IRODSTempPath = commons.MakeIRODSPath(cwd, home, zone, bundleTempFlagValues.IRODSTempPath)
+	} else {
+		// set default staging dir
+		logger.Debug("get default staging dir")
+
+		bundleTempFlagValues.IRODSTempPath = commons.GetDefaultStagingDirForDownload()
+	}
+
+	err = commons.CheckSafeStagingDir(bundleTempFlagValues.IRODSTempPath)
+	if err != nil {
+		return xerrors.Errorf("failed to get safe staging dir: %w", err)
+	}
+
+	logger.Infof("use staging dir - %s", bundleTempFlagValues.IRODSTempPath)
+
+	if bundleClearFlagValues.Clear {
+		logger.Debugf("clearing irods temp dir %s", bundleTempFlagValues.IRODSTempPath)
+		commons.CleanUpOldIRODSBundles(filesystem, bundleTempFlagValues.IRODSTempPath, false, true)
+	}
+
+	hashCache, err := newHashCache(hashCacheFlagValues)
+	if err != nil {
+		return xerrors.Errorf("failed to load hash cache: %w", err)
+	}
+	defer saveHashCache(hashCache, hashCacheFlagValues.PruneHashCache)
+
+	failureReport, err := newFailureReport(continueOnErrorFlagValues)
+	if err != nil {
+		return xerrors.Errorf("failed to set continue on error: %w", err)
+	}
+
+	pathFilter, err := newPathFilter(fromListFlagValues, false, false, func(p string) string {
+		return commons.MakeIRODSPath(commons.GetCWD(), commons.GetHomeDir(), commons.GetZone(), p)
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to set from list: %w", err)
+	}
+
+	bundleDownloadManager := commons.NewBundleDownloadManager(filesystem, targetPath, bundleConfigFlagValues.MaxFileNum, bundleConfigFlagValues.MaxFileSize, parallelTransferFlagValues.SingleTread, parallelTransferFlagValues.ThreadNumber, bundleTempFlagValues.LocalTempPath, bundleTempFlagValues.IRODSTempPath, differentialTransferFlagValues.DifferentialTransfer, differentialTransferFlagValues.NoHash, progressFlagValues.ShowProgress)
+	bundleDownloadManager.SetHashCache(hashCache)
+	bundleDownloadManager.SetCompareThreadNum(bundleConfigFlagValues.CompareThreadNum)
+	bundleDownloadManager.SetRetryPolicy(commons.NewRetryPolicy(retryFlagValues.RetryNumber, retryFlagValues.RetryIntervalSeconds))
+	bundleDownloadManager.SetFailureReport(failureReport)
+	bundleDownloadManager.SetRateLimiter(commons.NewRateLimiter(limitRateFlagValues.LimitRate))
+
+	if noRootFlagValues.NoRoot && len(sourcePaths) == 1 {
+		bundleRootPath, err := commons.GetCommonRootIRODSDirPathForSync(filesystem, sourcePaths)
+		if err != nil {
+			return xerrors.Errorf("failed to get common root dir for source paths: %w", err)
+		}
+
+		bundleDownloadManager.SetBundleRootPath(bundleRootPath)
+	} else {
+		bundleRootPath, err := commons.GetCommonRootIRODSDirPath(filesystem, sourcePaths)
+		if err != nil {
+			return xerrors.Errorf("failed to get common root dir for source paths: %w", err)
+		}
+
+		bundleDownloadManager.SetBundleRootPath(bundleRootPath)
+	}
+
+	bundleDownloadManager.Start()
+
+	for _, sourcePath := range sourcePaths {
+		err = bgetOne(bundleDownloadManager, pathFilter, sourcePath)
+		err = failureReport.Continue(sourcePath, commons.FailurePhaseScan, err)
+		if err != nil {
+			return xerrors.Errorf("failed to perform bget %s to %s: %w", sourcePath, targetPath, err)
+		}
+	}
+
+	bundleDownloadManager.DoneScheduling()
+	err = bundleDownloadManager.Wait()
+	if err != nil {
+		return xerrors.Errorf("failed to perform bundle transfer: %w", err)
+	}
+
+	err = checkFailureReport(failureReport, continueOnErrorFlagValues.FailedListPath)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func bgetOne(bundleManager *commons.BundleDownloadManager, pathFilter *commons.PathFilter, sourcePath string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "bgetOne",
+	})
+
+	filesystem := bundleManager.GetFilesystem()
+
+	logger.Debugf("bundle-downloading %s", sourcePath)
+
+	// list the collection tree with bulk queries, checksums are listed together
+	files, err := commons.ListIRODSDiffFiles(filesystem, sourcePath)
+	if err != nil {
+		return xerrors.Errorf("failed to list %s: %w", sourcePath, err)
+	}
+
+	for _, file := range files {
+		if file.Dir {
+			if !pathFilter.MatchDir(file.Path) {
+				continue
+			}
+		} else {
+			if !pathFilter.Match(file.Path) {
+				continue
+			}
+		}
+
+		err = bundleManager.Schedule(file.Path, file.Dir, file.Size, file.ChecksumAlgorithm, file.Checksum)
+		err = bundleManager.GetFailureReport().Continue(file.Path, commons.FailurePhaseScan, err)
+		if err != nil {
+			return xerrors.Errorf("failed to schedule %s: %w", file.Path, err)
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package subcmd
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
 	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
@@ -36,6 +37,11 @@ func AddBputCommand(rootCmd *cobra.Command) {
 	flag.SetDifferentialTransferFlags(bputCmd, true)
 	flag.SetNoRootFlags(bputCmd)
 	flag.SetSyncFlags(bputCmd)
+	flag.SetVerifyChecksumFlags(bputCmd)
+	flag.SetHashCacheFlags(bputCmd)
+	flag.SetLimitRateFlags(bputCmd)
+	flag.SetContinueOnErrorFlags(bputCmd)
+	flag.SetFromListFlags(bputCmd)
 
 	rootCmd.AddCommand(bputCmd)
 }
@@ -70,6 +76,14 @@ func processBputCommand(command *cobra.Command, args []string) error {
 	differentialTransferFlagValues := flag.GetDifferentialTransferFlagValues()
 	noRootFlagValues := flag.GetNoRootFlagValues()
 	syncFlagValues := flag.GetSyncFlagValues()
+	verifyChecksumFlagValues := flag.GetVerifyChecksumFlagValues()
+	hashCacheFlagValues := flag.GetHashCacheFlagValues()
+	limitRateFlagValues, err := flag.GetLimitRateFlagValues()
+	if err != nil {
+		return xerrors.Errorf("failed to get limit rate: %w", err)
+	}
+	continueOnErrorFlagValues := flag.GetContinueOnErrorFlagValues()
+	fromListFlagValues := flag.GetFromListFlagValues()
 
 	maxConnectionNum := parallelTransferFlagValues.ThreadNumber + 2 + 2 // 2 for metadata op, 2 for extraction
 
@@ -78,14 +92,6 @@ func processBputCommand(command *cobra.Command, args []string) error {
 		commons.CleanUpOldLocalBundles(bundleTempFlagValues.LocalTempPath, true)
 	}
 
-	if retryFlagValues.RetryNumber > 0 && !retryFlagValues.RetryChild {
-		err = commons.RunWithRetry(retryFlagValues.RetryNumber, retryFlagValues.RetryIntervalSeconds)
-		if err != nil {
-			return xerrors.Errorf("failed to run with retry %d: %w", retryFlagValues.RetryNumber, err)
-		}
-		return nil
-	}
-
 	// Create a file system
 	account := commons.GetAccount()
 	filesystem, err := commons.GetIRODSFSClientAdvanced(account, maxConnectionNum, parallelTransferFlagValues.TCPBufferSize)
@@ -122,14 +128,18 @@ func processBputCommand(command *cobra.Command, args []string) error {
 		logger.Debugf("validating staging dir - %s", bundleTempFlagValues.IRODSTempPath)
 
 		bundleTempFlagValues.IRODSTempPath = commons.MakeIRODSPath(cwd, home, zone, bundleTempFlagValues.IRODSTempPath)
-		ok, err := commons.ValidateStagingDir(filesystem, targetPath, bundleTempFlagValues.IRODSTempPath)
+		ok, stagingResources, err := commons.ValidateStagingDir(filesystem, targetPath, bundleTempFlagValues.IRODSTempPath)
 		if err != nil {
 			return xerrors.Errorf("failed to validate staging dir - %s: %w", bundleTempFlagValues.IRODSTempPath, err)
 		}
 
 		if !ok {
-			logger.Debugf("unable to use the given staging dir %s since it is in a different resource server, using default staging dir", bundleTempFlagValues.IRODSTempPath)
-			return xerrors.Errorf("staging dir %s is in a different resource server", bundleTempFlagValues.IRODSTempPath)
+			logger.Debugf("unable to use the given staging dir %s since it is in a different resource server", bundleTempFlagValues.IRODSTempPath)
+			if len(stagingResources) == 0 {
+				return xerrors.Errorf("staging dir %s is in a different resource server, staging dir must be in the same root resource as the target path %s, but no resource is available for staging, use 'lsresc --tree' command to list resources", bundleTempFlagValues.IRODSTempPath, targetPath)
+			}
+
+			return xerrors.Errorf("staging dir %s is in a different resource server, staging dir must be in the same root resource as the target path %s, valid staging resources are %s", bundleTempFlagValues.IRODSTempPath, targetPath, strings.Join(stagingResources, ", "))
 		}
 	} else {
 		// set default staging dir
@@ -150,7 +160,42 @@ func processBputCommand(command *cobra.Command, args []string) error {
 		commons.CleanUpOldIRODSBundles(filesystem, bundleTempFlagValues.IRODSTempPath, false, true)
 	}
 
+	hashCache, err := newHashCache(hashCacheFlagValues)
+	if err != nil {
+		return xerrors.Errorf("failed to load hash cache: %w", err)
+	}
+	defer saveHashCache(hashCache, hashCacheFlagValues.PruneHashCache)
+
+	failureReport, err := newFailureReport(continueOnErrorFlagValues)
+	if err != nil {
+		return xerrors.Errorf("failed to set continue on error: %w", err)
+	}
+
+	pathFilter, err := newPathFilter(fromListFlagValues, syncFlagValues.Delete, true, commons.MakeLocalPath)
+	if err != nil {
+		return xerrors.Errorf("failed to set from list: %w", err)
+	}
+
 	bundleTransferManager := commons.NewBundleTransferManager(filesystem, targetPath, bundleConfigFlagValues.MaxFileNum, bundleConfigFlagValues.MaxFileSize, parallelTransferFlagValues.SingleTread, parallelTransferFlagValues.ThreadNumber, bundleTempFlagValues.LocalTempPath, bundleTempFlagValues.IRODSTempPath, differentialTransferFlagValues.DifferentialTransfer, differentialTransferFlagValues.NoHash, bundleConfigFlagValues.NoBulkRegistration, progressFlagValues.ShowProgress)
+	bundleTransferManager.SetVerifyChecksum(verifyChecksumFlagValues.VerifyChecksum, verifyChecksumFlagValues.KeepMismatched)
+	bundleTransferManager.SetHashCache(hashCache)
+	bundleTransferManager.SetCompareThreadNum(bundleConfigFlagValues.CompareThreadNum)
+	bundleTransferManager.SetRetryPolicy(commons.NewRetryPolicy(retryFlagValues.RetryNumber, retryFlagValues.RetryIntervalSeconds))
+	bundleTransferManager.SetFailureReport(failureReport)
+	bundleTransferManager.SetRateLimiter(commons.NewRateLimiter(limitRateFlagValues.LimitRate))
+
+	err = bundleTransferManager.SetBundleFormat(bundleConfigFlagValues.BundleFormat)
+	if err != nil {
+		return xerrors.Errorf("failed to set bundle format: %w", err)
+	}
+
+	// record bundles to resume them when the transfer fails
+	bundleManifest, err := commons.NewBundleManifest(bundleTempFlagValues.LocalTempPath, targetPath)
+	if err != nil {
+		return xerrors.Errorf("failed to load bundle manifest: %w", err)
+	}
+	bundleTransferManager.SetManifest(bundleManifest)
+
 	bundleTransferManager.Start()
 
 	if noRootFlagValues.NoRoot && len(sourcePaths) == 1 {
@@ -170,7 +215,8 @@ func processBputCommand(command *cobra.Command, args []string) error {
 	}
 
 	for _, sourcePath := range sourcePaths {
-		err = bputOne(bundleTransferManager, sourcePath)
+		err = bputOne(bundleTransferManager, pathFilter, sourcePath)
+		err = failureReport.Continue(commons.MakeLocalPath(sourcePath), commons.FailurePhaseScan, err)
 		if err != nil {
 			return xerrors.Errorf("failed to perform bput %s to %s: %w", sourcePath, targetPath, err)
 		}
@@ -182,6 +228,11 @@ func processBputCommand(command *cobra.Command, args []string) error {
 		return xerrors.Errorf("failed to perform bundle transfer: %w", err)
 	}
 
+	err = checkFailureReport(failureReport, continueOnErrorFlagValues.FailedListPath)
+	if err != nil {
+		return err
+	}
+
 	// delete extra
 	if syncFlagValues.Delete {
 		logger.Infof("deleting extra files and dirs under %s", targetPath)
@@ -195,7 +246,7 @@ func processBputCommand(command *cobra.Command, args []string) error {
 	return nil
 }
 
-func bputOne(bundleManager *commons.BundleTransferManager, sourcePath string) error {
+func bputOne(bundleManager *commons.BundleTransferManager, pathFilter *commons.PathFilter, sourcePath string) error {
 	logger := log.WithFields(log.Fields{
 		"package":  "main",
 		"function": "bputOne",
@@ -214,24 +265,40 @@ func bputOne(bundleManager *commons.BundleTransferManager, sourcePath string) er
 
 	if !sourceStat.IsDir() {
 		// file
+		if !pathFilter.Match(sourcePath) {
+			return nil
+		}
+
 		bundleManager.Schedule(sourcePath, false, sourceStat.Size(), sourceStat.ModTime().Local())
 	} else {
 		// dir
 		logger.Debugf("bundle-uploading a local directory %s", sourcePath)
 
+		failureReport := bundleManager.GetFailureReport()
+
 		walkFunc := func(path string, entry os.DirEntry, err2 error) error {
 			if err2 != nil {
-				return xerrors.Errorf("failed to walk for %s: %w", path, err2)
+				return failureReport.Continue(path, commons.FailurePhaseScan, xerrors.Errorf("failed to walk for %s: %w", path, err2))
+			}
+
+			if entry.IsDir() {
+				if !pathFilter.MatchDir(path) {
+					return filepath.SkipDir
+				}
+			} else {
+				if !pathFilter.Match(path) {
+					return nil
+				}
 			}
 
 			info, err := entry.Info()
 			if err != nil {
-				return xerrors.Errorf("failed to get info for %s: %w", path, err)
+				return failureReport.Continue(path, commons.FailurePhaseScan, xerrors.Errorf("failed to get info for %s: %w", path, err))
 			}
 
 			err = bundleManager.Schedule(path, info.IsDir(), info.Size(), info.ModTime())
 			if err != nil {
-				return xerrors.Errorf("failed to schedule %s: %w", path, err)
+				return failureReport.Continue(path, commons.FailurePhaseScan, xerrors.Errorf("failed to schedule %s: %w", path, err))
 			}
 			return nil
 		}
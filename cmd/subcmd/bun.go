@@ -13,12 +13,14 @@ import (
 )
 
 var bunCmd = &cobra.Command{
-	Use:     "bun [data-object1] [data-object2] ... [target collection]",
+	Use:     "bun -x [data-object1] [data-object2] ... [target collection] or bun --create [data-object] [source collection]",
 	Aliases: []string{"bundle", "ibun"},
-	Short:   "Extract iRODS data-objects in a structured file format to target collection",
-	Long:    `This extracts iRODS data-objects in a structured file format (e.g., zip and tar) to the given target collection.`,
-	RunE:    processBunCommand,
-	Args:    cobra.MinimumNArgs(2),
+	Short:   "Extract iRODS data-objects in a structured file format to target collection, or create one from a collection",
+	Long: `This extracts iRODS data-objects in a structured file format (e.g., zip and tar) to the given target collection.
+With --create flag, this creates a data-object in a structured file format from all data-objects in the given source collection. The data-object is created in the server side, data is not transferred to the client.
+The data-object is created in the default resource, use -R flag to create it in a different resource.`,
+	RunE: processBunCommand,
+	Args: cobra.MinimumNArgs(2),
 }
 
 func AddBunCommand(rootCmd *cobra.Command) {
@@ -50,8 +52,12 @@ func processBunCommand(command *cobra.Command, args []string) error {
 	forceFlagValues := flag.GetForceFlagValues()
 	bundleFlagValues := flag.GetBundleFlagValues()
 
-	if !bundleFlagValues.Extract {
-		return xerrors.Errorf("support only extract mode")
+	if bundleFlagValues.Extract == bundleFlagValues.Create {
+		return xerrors.Errorf("either extract or create flag must be given")
+	}
+
+	if bundleFlagValues.Create && len(args) != 2 {
+		return xerrors.Errorf("create mode requires a data-object and a source collection")
 	}
 
 	// Create a file system
@@ -63,6 +69,15 @@ func processBunCommand(command *cobra.Command, args []string) error {
 
 	defer filesystem.Release()
 
+	if bundleFlagValues.Create {
+		err = createOne(filesystem, args[0], args[1], bundleFlagValues.DataType, forceFlagValues.Force)
+		if err != nil {
+			return xerrors.Errorf("failed to perform bun %s from %s: %w", args[0], args[1], err)
+		}
+
+		return nil
+	}
+
 	targetPath := args[len(args)-1]
 	for _, sourcePath := range args[:len(args)-1] {
 		if bundleFlagValues.Extract {
@@ -154,3 +169,55 @@ func extractOne(filesystem *irodsclient_fs.FileSystem, sourcePath string, target
 	}
 	return nil
 }
+
+func createOne(filesystem *irodsclient_fs.FileSystem, targetPath string, sourcePath string, dataType string, force bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "createOne",
+	})
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	sourcePath = commons.MakeIRODSPath(cwd, home, zone, sourcePath)
+	targetPath = commons.MakeIRODSPath(cwd, home, zone, targetPath)
+
+	sourceEntry, err := filesystem.Stat(sourcePath)
+	if err != nil {
+		return xerrors.Errorf("failed to stat %s: %w", sourcePath, err)
+	}
+
+	if sourceEntry.Type != irodsclient_fs.DirectoryEntry {
+		return xerrors.Errorf("source %s must be a collection", sourcePath)
+	}
+
+	targetEntry, err := filesystem.Stat(targetPath)
+	if err != nil {
+		if !irodsclient_types.IsFileNotFoundError(err) {
+			return xerrors.Errorf("failed to stat %s: %w", targetPath, err)
+		}
+	} else {
+		if targetEntry.Type != irodsclient_fs.FileEntry {
+			return xerrors.Errorf("%s is not a data object", targetPath)
+		}
+
+		if !force {
+			return xerrors.Errorf("data object %s already exists, use force flag to overwrite", targetPath)
+		}
+	}
+
+	dt, err := getDataType(targetPath, dataType)
+	if err != nil {
+		return xerrors.Errorf("failed to get type %s: %w", targetPath, err)
+	}
+
+	// the server does not report progress, so no progress bar is shown
+	logger.Debugf("creating a data object %s from %s", targetPath, sourcePath)
+	err = commons.CreateStructFile(commons.GetAccount(), targetPath, sourcePath, "", dt, force)
+	if err != nil {
+		return xerrors.Errorf("failed to create file %s from %s: %w", targetPath, sourcePath, err)
+	}
+
+	logger.Debugf("created a data object %s from %s", targetPath, sourcePath)
+	return nil
+}
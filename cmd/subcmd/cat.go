@@ -1,8 +1,7 @@
 package subcmd
 
 import (
-	"fmt"
-	"io"
+	"os"
 
 	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
 	"github.com/cyverse/gocommands/cmd/flag"
@@ -16,7 +15,7 @@ var catCmd = &cobra.Command{
 	Use:     "cat [data-object]",
 	Aliases: []string{"icat"},
 	Short:   "Display the content of an iRODS data-object",
-	Long:    `This displays the content of an iRODS data-object.`,
+	Long:    `This displays the content of an iRODS data-object. A part of the content can be displayed with --offset and --length flags.`,
 	RunE:    processCatCommand,
 	Args:    cobra.MinimumNArgs(1),
 }
@@ -26,6 +25,7 @@ func AddCatCommand(rootCmd *cobra.Command) {
 	flag.SetCommonFlags(catCmd)
 
 	flag.SetTicketAccessFlags(catCmd)
+	flag.SetReadRangeFlags(catCmd)
 
 	rootCmd.AddCommand(catCmd)
 }
@@ -52,6 +52,7 @@ func processCatCommand(command *cobra.Command, args []string) error {
 	}
 
 	ticketAccessFlagValues := flag.GetTicketAccessFlagValues()
+	readRangeFlagValues := flag.GetReadRangeFlagValues()
 
 	appConfig := commons.GetConfig()
 	syncAccount := false
@@ -78,7 +79,7 @@ func processCatCommand(command *cobra.Command, args []string) error {
 	defer filesystem.Release()
 
 	for _, sourcePath := range args {
-		err = catOne(filesystem, sourcePath)
+		err = catOne(filesystem, sourcePath, readRangeFlagValues.Offset, readRangeFlagValues.Length)
 		if err != nil {
 			return xerrors.Errorf("failed to perform cat %s: %w", sourcePath, err)
 		}
@@ -86,12 +87,31 @@ func processCatCommand(command *cobra.Command, args []string) error {
 	return nil
 }
 
-func catOne(filesystem *irodsclient_fs.FileSystem, targetPath string) error {
+func catOne(filesystem *irodsclient_fs.FileSystem, targetPath string, offset int64, length int64) error {
 	logger := log.WithFields(log.Fields{
 		"package":  "main",
 		"function": "catOne",
 	})
 
+	fh, _, err := openDataObjectForRead(filesystem, targetPath)
+	if err != nil {
+		return err
+	}
+
+	defer fh.Close()
+
+	logger.Debugf("showing the content of a data object %s, offset %d, length %d", fh.GetEntry().Path, offset, length)
+
+	_, err = commons.CopyRange(os.Stdout, fh, offset, length)
+	if err != nil {
+		return xerrors.Errorf("failed to read %s: %w", fh.GetEntry().Path, err)
+	}
+
+	return nil
+}
+
+// openDataObjectForRead opens the data object at the path for read
+func openDataObjectForRead(filesystem *irodsclient_fs.FileSystem, targetPath string) (*irodsclient_fs.FileHandle, *irodsclient_fs.Entry, error) {
 	cwd := commons.GetCWD()
 	home := commons.GetHomeDir()
 	zone := commons.GetZone()
@@ -99,35 +119,18 @@ func catOne(filesystem *irodsclient_fs.FileSystem, targetPath string) error {
 
 	targetEntry, err := filesystem.Stat(targetPath)
 	if err != nil {
-		return xerrors.Errorf("failed to stat %s: %w", targetPath, err)
+		return nil, nil, xerrors.Errorf("failed to stat %s: %w", targetPath, err)
 	}
 
-	if targetEntry.Type == irodsclient_fs.FileEntry {
-		// file
-		logger.Debugf("showing the content of a data object %s", targetPath)
-		fh, err := filesystem.OpenFile(targetPath, "", "r")
-		if err != nil {
-			return xerrors.Errorf("failed to open file %s: %w", targetPath, err)
-		}
-
-		defer fh.Close()
-
-		buf := make([]byte, 10240) // 10KB buffer
-		for {
-			readLen, err := fh.Read(buf)
-			if readLen > 0 {
-				fmt.Printf("%s", string(buf[:readLen]))
-			}
-
-			if err == io.EOF {
-				// EOF
-				break
-			}
-		}
-
-	} else {
+	if targetEntry.Type != irodsclient_fs.FileEntry {
 		// dir
-		return xerrors.Errorf("cannot show the content of a collection")
+		return nil, nil, xerrors.Errorf("cannot show the content of a collection")
 	}
-	return nil
+
+	fh, err := filesystem.OpenFile(targetPath, "", "r")
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to open file %s: %w", targetPath, err)
+	}
+
+	return fh, targetEntry, nil
 }
@@ -0,0 +1,304 @@
+package subcmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_irodsfs "github.com/cyverse/go-irodsclient/irods/fs"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var chksumCmd = &cobra.Command{
+	Use:     "chksum [data-object1] [data-object2] [collection1] ...",
+	Aliases: []string{"ichksum", "checksum"},
+	Short:   "Compute, register or verify checksums of iRODS data-objects",
+	Long: `This asks the server to compute and register checksums of iRODS data-objects, or to verify replicas against registered checksums.
+All replicas are verified, or only the replica in the resource given with -R. Replicas without registered checksums fail verification.`,
+	RunE: processChksumCommand,
+	Args: cobra.MinimumNArgs(1),
+}
+
+func AddChksumCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(chksumCmd)
+
+	flag.SetForceFlags(chksumCmd, false)
+	flag.SetRecursiveFlags(chksumCmd)
+	flag.SetChecksumFlags(chksumCmd)
+
+	rootCmd.AddCommand(chksumCmd)
+}
+
+// chksumStats counts problems found while checking checksums
+type chksumStats struct {
+	Mismatched int
+	Missing    int
+	Failed     int
+}
+
+func processChksumCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	forceFlagValues := flag.GetForceFlagValues()
+	recursiveFlagValues := flag.GetRecursiveFlagValues()
+	checksumFlagValues := flag.GetChecksumFlagValues()
+	commonFlagValues := flag.GetCommonFlagValues(command)
+
+	// the default resource in config is not used to not skip other replicas
+	resource := ""
+	if commonFlagValues.ResourceUpdated {
+		resource = commonFlagValues.Resource
+	}
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	stats := &chksumStats{}
+	for _, sourcePath := range args {
+		err = chksumOne(filesystem, sourcePath, forceFlagValues.Force, recursiveFlagValues.Recursive, checksumFlagValues.Verify, resource, stats)
+		if err != nil {
+			return xerrors.Errorf("failed to perform chksum %s: %w", sourcePath, err)
+		}
+	}
+
+	if stats.Mismatched > 0 || stats.Missing > 0 || stats.Failed > 0 {
+		return xerrors.Errorf("found %d data-objects with mismatching checksums, %d data-objects without checksums, failed to process %d data-objects", stats.Mismatched, stats.Missing, stats.Failed)
+	}
+
+	return nil
+}
+
+func chksumOne(fs *irodsclient_fs.FileSystem, sourcePath string, force bool, recurse bool, verify bool, resource string, stats *chksumStats) error {
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	sourcePath = commons.MakeIRODSPath(cwd, home, zone, sourcePath)
+
+	entries, err := getDataObjects(fs, sourcePath, recurse)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if verify {
+			verifyChecksumOne(fs, entry, resource, stats)
+		} else {
+			computeChecksumOne(fs, entry, force, stats)
+		}
+	}
+
+	return nil
+}
+
+// getDataObjects returns the data-object at the path, or all data-objects under the collection if recurse is set
+func getDataObjects(fs *irodsclient_fs.FileSystem, sourcePath string, recurse bool) ([]*irodsclient_types.IRODSDataObject, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "getDataObjects",
+	})
+
+	sourceEntry, err := fs.Stat(sourcePath)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to stat %s: %w", sourcePath, err)
+	}
+
+	if sourceEntry.Type == irodsclient_fs.FileEntry {
+		entry, err := getDataObject(fs, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*irodsclient_types.IRODSDataObject{entry}, nil
+	}
+
+	// dir
+	if !recurse {
+		return nil, xerrors.Errorf("cannot process a collection %s, recurse is not set", sourcePath)
+	}
+
+	logger.Debugf("listing data-objects in %s", sourcePath)
+	entries, err := commons.SearchDataObjects(fs, sourcePath, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to search data-objects in %s: %w", sourcePath, err)
+	}
+
+	return entries, nil
+}
+
+func getDataObject(fs *irodsclient_fs.FileSystem, sourcePath string) (*irodsclient_types.IRODSDataObject, error) {
+	connection, err := fs.GetMetadataConnection()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnMetadataConnection(connection)
+
+	parentSourcePath := path.Dir(sourcePath)
+
+	parentCollection, err := irodsclient_irodsfs.GetCollection(connection, parentSourcePath)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get collection %s: %w", parentSourcePath, err)
+	}
+
+	entry, err := irodsclient_irodsfs.GetDataObject(connection, parentCollection, path.Base(sourcePath))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get data-object %s: %w", sourcePath, err)
+	}
+
+	return entry, nil
+}
+
+// getReplicaChecksums returns distinct registered checksums of replicas and whether any replica has no checksum
+func getReplicaChecksums(replicas []*irodsclient_types.IRODSReplica) ([]string, bool) {
+	checksums := []string{}
+	missing := false
+
+	for _, replica := range replicas {
+		if replica.Checksum == nil || len(replica.Checksum.OriginalChecksum) == 0 {
+			missing = true
+			continue
+		}
+
+		found := false
+		for _, checksum := range checksums {
+			if checksum == replica.Checksum.OriginalChecksum {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			checksums = append(checksums, replica.Checksum.OriginalChecksum)
+		}
+	}
+
+	return checksums, missing
+}
+
+func computeChecksumOne(fs *irodsclient_fs.FileSystem, entry *irodsclient_types.IRODSDataObject, force bool, stats *chksumStats) {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "computeChecksumOne",
+	})
+
+	checksums, missing := getReplicaChecksums(entry.Replicas)
+	if !force && !missing && len(checksums) == 1 {
+		// already registered
+		printChecksumStatus("OK", entry.Path, checksums[0])
+		return
+	}
+
+	logger.Debugf("computing checksum of %s", entry.Path)
+
+	checksum, err := commons.ComputeChecksum(fs, entry.Path, force)
+	if err != nil {
+		logger.WithError(err).Debugf("failed to compute checksum of %s", entry.Path)
+		if commons.IsChecksumMismatchError(err) {
+			stats.Mismatched++
+			printChecksumStatus("MISMATCH", entry.Path, "replicas have different checksums")
+			return
+		}
+
+		stats.Failed++
+		printChecksumStatus("ERROR", entry.Path, err.Error())
+		return
+	}
+
+	if missing {
+		printChecksumStatus("NEW", entry.Path, checksum.OriginalChecksum)
+		return
+	}
+
+	printChecksumStatus("OK", entry.Path, checksum.OriginalChecksum)
+}
+
+func verifyChecksumOne(fs *irodsclient_fs.FileSystem, entry *irodsclient_types.IRODSDataObject, resource string, stats *chksumStats) {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "verifyChecksumOne",
+	})
+
+	replicas := getResourceReplicas(entry, resource)
+	if len(replicas) == 0 {
+		stats.Failed++
+		printChecksumStatus("ERROR", entry.Path, fmt.Sprintf("no replica in resource %s", resource))
+		return
+	}
+
+	checksums, missing := getReplicaChecksums(replicas)
+	if missing {
+		stats.Missing++
+		printChecksumStatus("MISSING", entry.Path, "no checksum registered")
+		return
+	}
+
+	if len(checksums) > 1 {
+		stats.Mismatched++
+		printChecksumStatus("MISMATCH", entry.Path, "replicas have different checksums")
+		return
+	}
+
+	logger.Debugf("verifying checksum of %s", entry.Path)
+
+	err := commons.VerifyChecksum(fs, entry.Path, resource)
+	if err != nil {
+		logger.WithError(err).Debugf("failed to verify checksum of %s", entry.Path)
+		if commons.IsChecksumMismatchError(err) {
+			stats.Mismatched++
+			printChecksumStatus("MISMATCH", entry.Path, checksums[0])
+			return
+		}
+
+		stats.Failed++
+		printChecksumStatus("ERROR", entry.Path, err.Error())
+		return
+	}
+
+	printChecksumStatus("OK", entry.Path, checksums[0])
+}
+
+// getResourceReplicas returns replicas in the resource, the resource can be a root or a leaf resource. All replicas are returned if resource is empty.
+func getResourceReplicas(entry *irodsclient_types.IRODSDataObject, resource string) []*irodsclient_types.IRODSReplica {
+	if len(resource) == 0 {
+		return entry.Replicas
+	}
+
+	replicas := []*irodsclient_types.IRODSReplica{}
+	for _, replica := range entry.Replicas {
+		hierarchy := strings.Split(replica.ResourceHierarchy, ";")
+		if replica.ResourceName == resource || hierarchy[0] == resource {
+			replicas = append(replicas, replica)
+		}
+	}
+
+	return replicas
+}
+
+func printChecksumStatus(status string, targetPath string, message string) {
+	fmt.Printf("%s\t%s\t%s\n", status, targetPath, message)
+}
@@ -0,0 +1,155 @@
+package subcmd
+
+import (
+	"strings"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var chmodCmd = &cobra.Command{
+	Use:     "chmod [read|write|own|null] [user or group] [data-object1] [collection1] ... OR chmod [inherit|noinherit] [collection1] ...",
+	Aliases: []string{"ichmod", "chacl"},
+	Short:   "Change access control of iRODS data-objects or collections",
+	Long:    `This changes access control lists of iRODS data-objects or collections for a user or group, or access inheritance of iRODS collections.`,
+	RunE:    processChmodCommand,
+	Args:    cobra.MinimumNArgs(2),
+}
+
+func AddChmodCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(chmodCmd)
+
+	flag.SetRecursiveFlags(chmodCmd)
+
+	rootCmd.AddCommand(chmodCmd)
+}
+
+func processChmodCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	recursiveFlagValues := flag.GetRecursiveFlagValues()
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	mode := strings.ToLower(args[0])
+	if mode == "inherit" || mode == "noinherit" {
+		for _, targetPath := range args[1:] {
+			err = chmodInheritOne(filesystem, targetPath, mode == "inherit", recursiveFlagValues.Recursive)
+			if err != nil {
+				return xerrors.Errorf("failed to perform chmod %s %s: %w", mode, targetPath, err)
+			}
+		}
+		return nil
+	}
+
+	if len(args) < 3 {
+		return xerrors.Errorf("not enough input arguments")
+	}
+
+	accessLevel, err := commons.ParseAccessLevel(args[0])
+	if err != nil {
+		return xerrors.Errorf("failed to parse access level: %w", err)
+	}
+
+	user := args[1]
+	userZone := ""
+	if strings.Contains(user, "#") {
+		userParts := strings.SplitN(user, "#", 2)
+		user = userParts[0]
+		userZone = userParts[1]
+	}
+
+	for _, targetPath := range args[2:] {
+		err = chmodOne(filesystem, targetPath, accessLevel, user, userZone, recursiveFlagValues.Recursive)
+		if err != nil {
+			return xerrors.Errorf("failed to perform chmod %s %s %s: %w", args[0], args[1], targetPath, err)
+		}
+	}
+
+	return nil
+}
+
+func chmodOne(fs *irodsclient_fs.FileSystem, targetPath string, accessLevel irodsclient_types.IRODSAccessLevelType, user string, userZone string, recurse bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "chmodOne",
+	})
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	targetPath = commons.MakeIRODSPath(cwd, home, zone, targetPath)
+
+	targetEntry, err := fs.Stat(targetPath)
+	if err != nil {
+		return xerrors.Errorf("failed to stat %s: %w", targetPath, err)
+	}
+
+	if targetEntry.Type == irodsclient_fs.FileEntry {
+		// recursion is only meaningful for collections
+		recurse = false
+	}
+
+	logger.Debugf("changing access of %s for %s to %s", targetPath, user, accessLevel.ChmodString())
+
+	err = commons.ChangeAccess(fs, targetPath, accessLevel, user, userZone, recurse)
+	if err != nil {
+		return xerrors.Errorf("failed to change access of %s: %w", targetPath, err)
+	}
+	return nil
+}
+
+func chmodInheritOne(fs *irodsclient_fs.FileSystem, targetPath string, inherit bool, recurse bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "chmodInheritOne",
+	})
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	targetPath = commons.MakeIRODSPath(cwd, home, zone, targetPath)
+
+	targetEntry, err := fs.Stat(targetPath)
+	if err != nil {
+		return xerrors.Errorf("failed to stat %s: %w", targetPath, err)
+	}
+
+	if targetEntry.Type != irodsclient_fs.DirectoryEntry {
+		return xerrors.Errorf("cannot change access inheritance of %s, not a collection", targetPath)
+	}
+
+	logger.Debugf("changing access inheritance of %s to %t", targetPath, inherit)
+
+	err = commons.ChangeAccessInherit(fs, targetPath, inherit, recurse)
+	if err != nil {
+		return xerrors.Errorf("failed to change access inheritance of %s: %w", targetPath, err)
+	}
+	return nil
+}
@@ -33,6 +33,9 @@ func AddCpCommand(rootCmd *cobra.Command) {
 	flag.SetDifferentialTransferFlags(cpCmd, true)
 	flag.SetNoRootFlags(cpCmd)
 	flag.SetSyncFlags(cpCmd)
+	flag.SetVerifyChecksumFlags(cpCmd)
+	flag.SetContinueOnErrorFlags(cpCmd)
+	flag.SetFromListFlags(cpCmd)
 
 	rootCmd.AddCommand(cpCmd)
 }
@@ -65,14 +68,9 @@ func processCpCommand(command *cobra.Command, args []string) error {
 	differentialTransferFlagValues := flag.GetDifferentialTransferFlagValues()
 	noRootFlagValues := flag.GetNoRootFlagValues()
 	syncFlagValues := flag.GetSyncFlagValues()
-
-	if retryFlagValues.RetryNumber > 0 && !retryFlagValues.RetryChild {
-		err = commons.RunWithRetry(retryFlagValues.RetryNumber, retryFlagValues.RetryIntervalSeconds)
-		if err != nil {
-			return xerrors.Errorf("failed to run with retry %d: %w", retryFlagValues.RetryNumber, err)
-		}
-		return nil
-	}
+	verifyChecksumFlagValues := flag.GetVerifyChecksumFlagValues()
+	continueOnErrorFlagValues := flag.GetContinueOnErrorFlagValues()
+	fromListFlagValues := flag.GetFromListFlagValues()
 
 	// Create a file system
 	account := commons.GetAccount()
@@ -90,18 +88,39 @@ func processCpCommand(command *cobra.Command, args []string) error {
 		return xerrors.Errorf("failed to copy multiple source collections without creating root directory")
 	}
 
+	failureReport, err := newFailureReport(continueOnErrorFlagValues)
+	if err != nil {
+		return xerrors.Errorf("failed to set continue on error: %w", err)
+	}
+
+	pathFilter, err := newPathFilter(fromListFlagValues, syncFlagValues.Delete, false, func(p string) string {
+		return commons.MakeIRODSPath(commons.GetCWD(), commons.GetHomeDir(), commons.GetZone(), p)
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to set from list: %w", err)
+	}
+
 	parallelJobManager := commons.NewParallelJobManager(filesystem, commons.TransferTreadNumDefault, progressFlagValues.ShowProgress)
+	parallelJobManager.SetRetryPolicy(commons.NewRetryPolicy(retryFlagValues.RetryNumber, retryFlagValues.RetryIntervalSeconds))
+	parallelJobManager.SetFailureReport(failureReport)
 	parallelJobManager.Start()
 
 	inputPathMap := map[string]bool{}
 
 	for _, sourcePath := range sourcePaths {
+		failedPath := commons.MakeIRODSPath(commons.GetCWD(), commons.GetHomeDir(), commons.GetZone(), sourcePath)
+
 		newTargetDirPath, err := makeCopyTargetDirPath(filesystem, sourcePath, targetPath, noRootFlagValues.NoRoot)
 		if err != nil {
-			return xerrors.Errorf("failed to make new target path for copy %s to %s: %w", sourcePath, targetPath, err)
+			err = failureReport.Continue(failedPath, commons.FailurePhaseScan, err)
+			if err != nil {
+				return xerrors.Errorf("failed to make new target path for copy %s to %s: %w", sourcePath, targetPath, err)
+			}
+			continue
 		}
 
-		err = copyOne(parallelJobManager, inputPathMap, sourcePath, newTargetDirPath, recursiveFlagValues.Recursive, forceFlagValues.Force, differentialTransferFlagValues.DifferentialTransfer, differentialTransferFlagValues.NoHash)
+		err = copyOne(parallelJobManager, inputPathMap, pathFilter, sourcePath, newTargetDirPath, recursiveFlagValues.Recursive, forceFlagValues.Force, differentialTransferFlagValues.DifferentialTransfer, differentialTransferFlagValues.NoHash, verifyChecksumFlagValues.VerifyChecksum, verifyChecksumFlagValues.KeepMismatched)
+		err = failureReport.Continue(failedPath, commons.FailurePhaseScan, err)
 		if err != nil {
 			return xerrors.Errorf("failed to perform copy %s to %s: %w", sourcePath, targetPath, err)
 		}
@@ -113,6 +132,11 @@ func processCpCommand(command *cobra.Command, args []string) error {
 		return xerrors.Errorf("failed to perform parallel job: %w", err)
 	}
 
+	err = checkFailureReport(failureReport, continueOnErrorFlagValues.FailedListPath)
+	if err != nil {
+		return err
+	}
+
 	// delete extra
 	if syncFlagValues.Delete {
 		logger.Infof("deleting extra files and dirs under %s", targetPath)
@@ -126,7 +150,7 @@ func processCpCommand(command *cobra.Command, args []string) error {
 	return nil
 }
 
-func copyOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[string]bool, sourcePath string, targetPath string, recurse bool, force bool, diff bool, noHash bool) error {
+func copyOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[string]bool, pathFilter *commons.PathFilter, sourcePath string, targetPath string, recurse bool, force bool, diff bool, noHash bool, verifyChecksum bool, keepMismatched bool) error {
 	logger := log.WithFields(log.Fields{
 		"package":  "main",
 		"function": "copyOne",
@@ -147,6 +171,10 @@ func copyOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[st
 
 	if sourceEntry.Type == irodsclient_fs.FileEntry {
 		// file
+		if !pathFilter.Match(sourcePath) {
+			return nil
+		}
+
 		targetFilePath := commons.MakeTargetIRODSFilePath(filesystem, sourcePath, targetPath)
 		commons.MarkPathMap(inputPathMap, targetFilePath)
 
@@ -174,6 +202,21 @@ func copyOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[st
 			}
 
 			logger.Debugf("copied a data object %s to %s", sourcePath, targetFilePath)
+
+			if verifyChecksum {
+				logger.Debugf("verifying checksum of %s", targetFilePath)
+				err = commons.VerifyDataObjectChecksum(fs, sourcePath, targetFilePath)
+				if err != nil {
+					job.Progress(-1, 1, true)
+
+					if commons.IsChecksumMismatchError(err) && !keepMismatched {
+						fs.RemoveFile(targetFilePath, true)
+					}
+
+					return xerrors.Errorf("failed to verify checksum of %s: %w", targetFilePath, err)
+				}
+			}
+
 			job.Progress(1, 1, false)
 			return nil
 		}
@@ -225,6 +268,10 @@ func copyOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[st
 			targetDirPath := targetPath
 			if entry.Type == irodsclient_fs.DirectoryEntry {
 				// dir
+				if !pathFilter.MatchDir(entry.Path) {
+					continue
+				}
+
 				targetDirPath = commons.MakeTargetIRODSFilePath(filesystem, entry.Path, targetPath)
 				err = filesystem.MakeDir(targetDirPath, true)
 				if err != nil {
@@ -234,7 +281,8 @@ func copyOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[st
 
 			commons.MarkPathMap(inputPathMap, targetDirPath)
 
-			err = copyOne(parallelJobManager, inputPathMap, entry.Path, targetDirPath, recurse, force, diff, noHash)
+			err = copyOne(parallelJobManager, inputPathMap, pathFilter, entry.Path, targetDirPath, recurse, force, diff, noHash, verifyChecksum, keepMismatched)
+			err = parallelJobManager.GetFailureReport().Continue(entry.Path, commons.FailurePhaseScan, err)
 			if err != nil {
 				return xerrors.Errorf("failed to perform copy %s to %s: %w", entry.Path, targetPath, err)
 			}
@@ -0,0 +1,255 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var diffCmd = &cobra.Command{
+	Use:     "diff [local dir] i:[collection] or diff i:[collection1] i:[collection2]",
+	Aliases: []string{"idiff"},
+	Short:   "Report differences between a local directory and an iRODS collection",
+	Long: `This reports files that only exist on one side, have different sizes or have different checksums between a local directory and an iRODS collection, or between two iRODS collections.
+iRODS paths must start with "i:". Local files are hashed with the algorithm of the iRODS checksum. Files with the same size but without comparable checksums are reported as unverified.
+This does not change anything. It exits with a non-zero status if differences are found, unverified files are not counted as differences.`,
+	RunE: processDiffCommand,
+	Args: cobra.ExactArgs(2),
+}
+
+func AddDiffCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(diffCmd)
+
+	flag.SetDifferentialTransferFlags(diffCmd, false)
+	flag.SetDiffFlags(diffCmd)
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func processDiffCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	differentialTransferFlagValues := flag.GetDifferentialTransferFlagValues()
+	diffFlagValues := flag.GetDiffFlagValues()
+
+	if diffFlagValues.JSON && diffFlagValues.TSV {
+		return xerrors.Errorf("json and tsv flags cannot be used together")
+	}
+
+	sourcePath := args[0]
+	targetPath := args[1]
+
+	if !strings.HasPrefix(sourcePath, "i:") && !strings.HasPrefix(targetPath, "i:") {
+		return xerrors.Errorf("comparing local to local is not supported")
+	}
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	diffs, err := diffOne(filesystem, sourcePath, targetPath, differentialTransferFlagValues.NoHash)
+	if err != nil {
+		return xerrors.Errorf("failed to perform diff %s and %s: %w", sourcePath, targetPath, err)
+	}
+
+	switch {
+	case diffFlagValues.JSON:
+		jsonBytes, err := json.MarshalIndent(diffs, "", "  ")
+		if err != nil {
+			return xerrors.Errorf("failed to marshal to json: %w", err)
+		}
+
+		fmt.Printf("%s\n", string(jsonBytes))
+	case diffFlagValues.TSV:
+		printDiffEntriesTSV(diffs)
+	default:
+		printDiffEntries(diffs)
+	}
+
+	// exit with a non-zero status if different, like diff
+	differences := commons.CountDifferences(diffs)
+	if differences > 0 {
+		return commons.NewDifferencesFoundError(differences)
+	}
+
+	return nil
+}
+
+func diffOne(fs *irodsclient_fs.FileSystem, sourcePath string, targetPath string, noHash bool) ([]*commons.DiffEntry, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "diffOne",
+	})
+
+	sourcePath, sourceLocal := makeDiffPath(sourcePath)
+	targetPath, targetLocal := makeDiffPath(targetPath)
+
+	sourceExist, sourceDir, err := statDiffPath(fs, sourcePath, sourceLocal)
+	if err != nil {
+		return nil, err
+	}
+
+	if !sourceExist {
+		return nil, xerrors.Errorf("source %s does not exist", sourcePath)
+	}
+
+	targetExist, targetDir, err := statDiffPath(fs, targetPath, targetLocal)
+	if err != nil {
+		return nil, err
+	}
+
+	// comparing a file with a directory compares the file with the one in the directory
+	if !sourceDir && targetDir {
+		if targetLocal {
+			targetPath = filepath.Join(targetPath, path.Base(sourcePath))
+		} else {
+			targetPath = path.Join(targetPath, filepath.Base(sourcePath))
+		}
+
+		targetExist, _, err = statDiffPath(fs, targetPath, targetLocal)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debugf("comparing %s and %s", sourcePath, targetPath)
+
+	sourceFiles, err := listDiffFiles(fs, sourcePath, sourceLocal)
+	if err != nil {
+		return nil, err
+	}
+
+	// missing target gives no files, all source files are reported
+	targetFiles := []*commons.DiffFile{}
+	if targetExist {
+		targetFiles, err = listDiffFiles(fs, targetPath, targetLocal)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	diffs, err := commons.DiffFiles(sourceFiles, targetFiles, noHash)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to compare: %w", err)
+	}
+
+	return diffs, nil
+}
+
+// makeDiffPath returns an absolute path and true if the path is local
+func makeDiffPath(p string) (string, bool) {
+	if strings.HasPrefix(p, "i:") {
+		cwd := commons.GetCWD()
+		home := commons.GetHomeDir()
+		zone := commons.GetZone()
+		return commons.MakeIRODSPath(cwd, home, zone, p[2:]), false
+	}
+
+	return commons.MakeLocalPath(p), true
+}
+
+// statDiffPath returns if the path exists and if it is a directory
+func statDiffPath(fs *irodsclient_fs.FileSystem, p string, local bool) (bool, bool, error) {
+	if local {
+		st, err := os.Stat(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return false, false, nil
+			}
+
+			return false, false, xerrors.Errorf("failed to stat %s: %w", p, err)
+		}
+
+		return true, st.IsDir(), nil
+	}
+
+	entry, err := fs.Stat(p)
+	if err != nil {
+		if irodsclient_types.IsFileNotFoundError(err) {
+			return false, false, nil
+		}
+
+		return false, false, xerrors.Errorf("failed to stat %s: %w", p, err)
+	}
+
+	return true, entry.Type == irodsclient_fs.DirectoryEntry, nil
+}
+
+func listDiffFiles(fs *irodsclient_fs.FileSystem, p string, local bool) ([]*commons.DiffFile, error) {
+	if local {
+		files, err := commons.ListLocalDiffFiles(p)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to list local files in %s: %w", p, err)
+		}
+
+		return files, nil
+	}
+
+	files, err := commons.ListIRODSDiffFiles(fs, p)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to list iRODS files in %s: %w", p, err)
+	}
+
+	return files, nil
+}
+
+func printDiffEntries(diffs []*commons.DiffEntry) {
+	if len(diffs) == 0 {
+		fmt.Printf("Found no differences\n")
+		return
+	}
+
+	for _, diff := range diffs {
+		switch diff.Type {
+		case commons.DiffTypeOnlySource:
+			fmt.Printf("only in source: %s\n", diff.SourcePath)
+		case commons.DiffTypeOnlyTarget:
+			fmt.Printf("only in target: %s\n", diff.TargetPath)
+		case commons.DiffTypeType:
+			fmt.Printf("different type: %s, %s\n", diff.SourcePath, diff.TargetPath)
+		case commons.DiffTypeSize:
+			fmt.Printf("different size: %s (%d), %s (%d)\n", diff.SourcePath, diff.SourceSize, diff.TargetPath, diff.TargetSize)
+		case commons.DiffTypeChecksum:
+			fmt.Printf("different checksum: %s (%s), %s (%s)\n", diff.SourcePath, diff.SourceChecksum, diff.TargetPath, diff.TargetChecksum)
+		case commons.DiffTypeUnverified:
+			fmt.Printf("unverified, no comparable checksum: %s, %s\n", diff.SourcePath, diff.TargetPath)
+		}
+	}
+}
+
+func printDiffEntriesTSV(diffs []*commons.DiffEntry) {
+	fmt.Printf("type\trel_path\tsource_path\ttarget_path\tsource_size\ttarget_size\tsource_checksum\ttarget_checksum\n")
+	for _, diff := range diffs {
+		fmt.Printf("%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n", diff.Type, diff.RelPath, diff.SourcePath, diff.TargetPath, diff.SourceSize, diff.TargetSize, diff.SourceChecksum, diff.TargetChecksum)
+	}
+}
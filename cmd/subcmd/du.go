@@ -0,0 +1,131 @@
+package subcmd
+
+import (
+	"fmt"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	"github.com/dustin/go-humanize"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var duCmd = &cobra.Command{
+	Use:     "du [collection1] [collection2] ...",
+	Aliases: []string{"idu", "disk_usage"},
+	Short:   "Display total sizes of iRODS collections",
+	Long: `This displays total sizes and numbers of data-objects in iRODS collections and their sub-collections.
+Totals are computed by the iRODS server. A data-object is counted once by its first replica (replica number 0), unless totals are split by resource.`,
+	RunE: processDuCommand,
+	Args: cobra.ArbitraryArgs,
+}
+
+func AddDuCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(duCmd)
+
+	flag.SetDuFlags(duCmd)
+
+	rootCmd.AddCommand(duCmd)
+}
+
+func processDuCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	duFlagValues := flag.GetDuFlagValues()
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	sourcePaths := args[:]
+
+	if len(args) == 0 {
+		sourcePaths = []string{"."}
+	}
+
+	for _, sourcePath := range sourcePaths {
+		err = duOne(filesystem, sourcePath, duFlagValues.MaxDepth, duFlagValues.ByResource, duFlagValues.HumanReadableSizes)
+		if err != nil {
+			return xerrors.Errorf("failed to perform du %s: %w", sourcePath, err)
+		}
+	}
+
+	return nil
+}
+
+func duOne(fs *irodsclient_fs.FileSystem, sourcePath string, maxDepth int, byResource bool, humanReadableSizes bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "duOne",
+	})
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	sourcePath = commons.MakeIRODSPath(cwd, home, zone, sourcePath)
+
+	sourceEntry, err := fs.Stat(sourcePath)
+	if err != nil {
+		return xerrors.Errorf("failed to stat %s: %w", sourcePath, err)
+	}
+
+	if sourceEntry.Type != irodsclient_fs.DirectoryEntry {
+		return xerrors.Errorf("%s is not a collection", sourcePath)
+	}
+
+	logger.Debugf("computing usages of collection %s", sourcePath)
+
+	usages, err := commons.GetCollectionUsages(fs, sourcePath, byResource)
+	if err != nil {
+		return xerrors.Errorf("failed to get usages of collection %s: %w", sourcePath, err)
+	}
+
+	summaries := commons.SummarizeCollectionUsages(sourcePath, usages, maxDepth)
+	if len(summaries) == 0 {
+		// empty collection
+		summaries = append(summaries, &commons.CollectionUsage{
+			Path: sourcePath,
+		})
+	}
+
+	for _, summary := range summaries {
+		printCollectionUsage(summary, byResource, humanReadableSizes)
+	}
+
+	return nil
+}
+
+func printCollectionUsage(usage *commons.CollectionUsage, byResource bool, humanReadableSizes bool) {
+	size := fmt.Sprintf("%d", usage.Size)
+	if humanReadableSizes {
+		size = humanize.Bytes(uint64(usage.Size))
+	}
+
+	if byResource {
+		fmt.Printf("%s\t%d\t%s\t%s\n", size, usage.Count, usage.Path, usage.Resource)
+		return
+	}
+
+	fmt.Printf("%s\t%d\t%s\n", size, usage.Count, usage.Path)
+}
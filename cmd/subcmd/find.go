@@ -0,0 +1,346 @@
+package subcmd
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var findCmd = &cobra.Command{
+	Use:     "find [collection1] [collection2] ...",
+	Aliases: []string{"search"},
+	Short:   "Find data-objects and collections in iRODS collections",
+	Long:    `This finds data-objects and collections that match given conditions in iRODS collections recursively. Conditions are evaluated by the iRODS server as much as possible.`,
+	RunE:    processFindCommand,
+	Args:    cobra.ArbitraryArgs,
+}
+
+func AddFindCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(findCmd)
+
+	flag.SetListFlags(findCmd)
+	flag.SetFindFlags(findCmd)
+
+	rootCmd.AddCommand(findCmd)
+}
+
+func processFindCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	listFlagValues := flag.GetListFlagValues()
+	findFlagValues := flag.GetFindFlagValues()
+
+	predicates, err := makeFindPredicates(findFlagValues)
+	if err != nil {
+		return err
+	}
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	sourcePaths := args[:]
+
+	if len(args) == 0 {
+		sourcePaths = []string{"."}
+	}
+
+	for _, sourcePath := range sourcePaths {
+		err = findOne(filesystem, sourcePath, predicates, listFlagValues.Format, listFlagValues.HumanReadableSizes, findFlagValues.Print0)
+		if err != nil {
+			return xerrors.Errorf("failed to perform find %s: %w", sourcePath, err)
+		}
+	}
+
+	return nil
+}
+
+// findPredicates are conditions that found entries must satisfy
+type findPredicates struct {
+	MetaConditions []*commons.MetaCondition
+	NamePattern    string
+	SizeCondition  *commons.SizeCondition
+	TimeCondition  *commons.TimeCondition
+	DataObjects    bool
+	Collections    bool
+}
+
+func makeFindPredicates(findFlagValues *flag.FindFlagValues) (*findPredicates, error) {
+	predicates := &findPredicates{
+		MetaConditions: []*commons.MetaCondition{},
+		NamePattern:    findFlagValues.NamePattern,
+	}
+
+	for _, metaConditionString := range findFlagValues.MetaConditions {
+		metaCondition, err := commons.ParseMetaCondition(metaConditionString)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to parse metadata condition %s: %w", metaConditionString, err)
+		}
+
+		predicates.MetaConditions = append(predicates.MetaConditions, metaCondition)
+	}
+
+	if len(findFlagValues.NamePattern) > 0 {
+		_, err := path.Match(findFlagValues.NamePattern, "")
+		if err != nil {
+			return nil, xerrors.Errorf("failed to parse name pattern %s: %w", findFlagValues.NamePattern, err)
+		}
+	}
+
+	if len(findFlagValues.Size) > 0 {
+		sizeCondition, err := commons.ParseSizeCondition(findFlagValues.Size)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to parse size condition %s: %w", findFlagValues.Size, err)
+		}
+
+		predicates.SizeCondition = sizeCondition
+	}
+
+	if len(findFlagValues.ModifyTime) > 0 {
+		timeCondition, err := commons.ParseTimeCondition(findFlagValues.ModifyTime, time.Now())
+		if err != nil {
+			return nil, xerrors.Errorf("failed to parse time condition %s: %w", findFlagValues.ModifyTime, err)
+		}
+
+		predicates.TimeCondition = timeCondition
+	}
+
+	switch findFlagValues.Type {
+	case "":
+		predicates.DataObjects = true
+		// collections do not have sizes and metadata of data-objects
+		predicates.Collections = len(predicates.MetaConditions) == 0 && predicates.SizeCondition == nil
+	case "f":
+		predicates.DataObjects = true
+	case "d":
+		if len(predicates.MetaConditions) > 0 || predicates.SizeCondition != nil {
+			return nil, xerrors.Errorf("metadata and size conditions cannot be used for collections")
+		}
+		predicates.Collections = true
+	default:
+		return nil, xerrors.Errorf("unknown type %s, must be f or d", findFlagValues.Type)
+	}
+
+	return predicates, nil
+}
+
+// getQueryConditions returns GenQuery conditions for predicates on data-objects except metadata conditions
+func (predicates *findPredicates) getQueryConditions() []commons.QueryCondition {
+	conditions := []commons.QueryCondition{}
+
+	if len(predicates.NamePattern) > 0 {
+		conditions = append(conditions, commons.GetDataObjectNameCondition(predicates.NamePattern))
+	}
+
+	if predicates.SizeCondition != nil {
+		conditions = append(conditions, predicates.SizeCondition.GetQueryCondition())
+	}
+
+	if predicates.TimeCondition != nil {
+		conditions = append(conditions, predicates.TimeCondition.GetQueryConditions()...)
+	}
+
+	return conditions
+}
+
+// matchDataObject checks predicates that GenQuery cannot evaluate exactly
+func (predicates *findPredicates) matchDataObject(entry *irodsclient_types.IRODSDataObject) bool {
+	if len(predicates.NamePattern) > 0 {
+		matched, err := path.Match(predicates.NamePattern, entry.Name)
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	if predicates.SizeCondition != nil && !predicates.SizeCondition.Match(entry.Size) {
+		return false
+	}
+
+	if predicates.TimeCondition != nil && !predicates.TimeCondition.Match(getDataObjectModifyTime(entry)) {
+		return false
+	}
+
+	return true
+}
+
+func (predicates *findPredicates) matchCollection(entry *irodsclient_types.IRODSCollection) bool {
+	if len(predicates.NamePattern) > 0 {
+		matched, err := path.Match(predicates.NamePattern, entry.Name)
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	if predicates.TimeCondition != nil && !predicates.TimeCondition.Match(entry.ModifyTime) {
+		return false
+	}
+
+	return true
+}
+
+func findOne(fs *irodsclient_fs.FileSystem, sourcePath string, predicates *findPredicates, format flag.ListFormat, humanReadableSizes bool, print0 bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "findOne",
+	})
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	sourcePath = commons.MakeIRODSPath(cwd, home, zone, sourcePath)
+
+	foundEntries := []*irodsclient_fs.Entry{}
+	foundDataObjects := map[string]*irodsclient_types.IRODSDataObject{}
+
+	if predicates.DataObjects {
+		logger.Debugf("finding data-objects in %s", sourcePath)
+
+		entries, err := searchDataObjectsByMeta(fs, sourcePath, predicates.MetaConditions, predicates.getQueryConditions())
+		if err != nil {
+			return xerrors.Errorf("failed to search data-objects in %s: %w", sourcePath, err)
+		}
+
+		for _, entry := range entries {
+			if !predicates.matchDataObject(entry) {
+				continue
+			}
+
+			foundEntries = append(foundEntries, &irodsclient_fs.Entry{
+				Type: irodsclient_fs.FileEntry,
+				Path: entry.Path,
+			})
+			foundDataObjects[entry.Path] = entry
+		}
+	}
+
+	if predicates.Collections {
+		logger.Debugf("finding collections in %s", sourcePath)
+
+		entries, err := commons.SearchCollections(fs, sourcePath)
+		if err != nil {
+			return xerrors.Errorf("failed to search collections in %s: %w", sourcePath, err)
+		}
+
+		for _, entry := range entries {
+			if !predicates.matchCollection(entry) {
+				continue
+			}
+
+			foundEntries = append(foundEntries, &irodsclient_fs.Entry{
+				Type: irodsclient_fs.DirectoryEntry,
+				Path: entry.Path,
+			})
+		}
+	}
+
+	// sort by path
+	sort.SliceStable(foundEntries, func(i int, j int) bool {
+		return foundEntries[i].Path < foundEntries[j].Path
+	})
+
+	for _, foundEntry := range foundEntries {
+		if print0 {
+			fmt.Printf("%s\x00", foundEntry.Path)
+			continue
+		}
+
+		if foundEntry.Type == irodsclient_fs.DirectoryEntry {
+			printFoundCollection(foundEntry.Path, format)
+			continue
+		}
+
+		printFoundDataObject(foundDataObjects[foundEntry.Path], format, humanReadableSizes)
+	}
+
+	return nil
+}
+
+// searchDataObjectsByMeta returns data-objects satisfying all metadata conditions and other conditions.
+// Each metadata condition is queried separately as conditions in a single query would have to match the same AVU.
+func searchDataObjectsByMeta(fs *irodsclient_fs.FileSystem, sourcePath string, metaConditions []*commons.MetaCondition, conditions []commons.QueryCondition) ([]*irodsclient_types.IRODSDataObject, error) {
+	if len(metaConditions) == 0 {
+		return commons.SearchDataObjects(fs, sourcePath, conditions)
+	}
+
+	var entries []*irodsclient_types.IRODSDataObject
+	for idx, metaCondition := range metaConditions {
+		queryConditions := append(metaCondition.GetDataObjectQueryConditions(), conditions...)
+		matchedEntries, err := commons.SearchDataObjects(fs, sourcePath, queryConditions)
+		if err != nil {
+			return nil, err
+		}
+
+		if idx == 0 {
+			entries = matchedEntries
+			continue
+		}
+
+		matchedIDs := map[int64]bool{}
+		for _, matchedEntry := range matchedEntries {
+			matchedIDs[matchedEntry.ID] = true
+		}
+
+		intersection := []*irodsclient_types.IRODSDataObject{}
+		for _, entry := range entries {
+			if matchedIDs[entry.ID] {
+				intersection = append(intersection, entry)
+			}
+		}
+
+		entries = intersection
+		if len(entries) == 0 {
+			break
+		}
+	}
+
+	return entries, nil
+}
+
+func printFoundDataObject(entry *irodsclient_types.IRODSDataObject, format flag.ListFormat, humanReadableSizes bool) {
+	switch format {
+	case flag.ListFormatLong, flag.ListFormatVeryLong:
+		// show full path in place of name
+		displayEntry := *entry
+		displayEntry.Name = entry.Path
+		printDataObject(&displayEntry, format, humanReadableSizes)
+	default:
+		fmt.Printf("%s\n", entry.Path)
+	}
+}
+
+func printFoundCollection(collectionPath string, format flag.ListFormat) {
+	switch format {
+	case flag.ListFormatLong, flag.ListFormatVeryLong:
+		fmt.Printf("  C- %s\n", collectionPath)
+	default:
+		fmt.Printf("%s\n", collectionPath)
+	}
+}
@@ -20,9 +20,10 @@ var getCmd = &cobra.Command{
 	Use:     "get [data-object1] [data-object2] [collection1] ... [local dir]",
 	Aliases: []string{"iget", "download"},
 	Short:   "Download iRODS data-objects or collections",
-	Long:    `This downloads iRODS data-objects or collections to the given local path.`,
-	RunE:    processGetCommand,
-	Args:    cobra.MinimumNArgs(1),
+	Long: `This downloads iRODS data-objects or collections to the given local path.
+If the local path is "-", data-objects are written to stdout.`,
+	RunE: processGetCommand,
+	Args: cobra.MinimumNArgs(1),
 }
 
 func AddGetCommand(rootCmd *cobra.Command) {
@@ -37,6 +38,11 @@ func AddGetCommand(rootCmd *cobra.Command) {
 	flag.SetDifferentialTransferFlags(getCmd, true)
 	flag.SetNoRootFlags(getCmd)
 	flag.SetSyncFlags(getCmd)
+	flag.SetVerifyChecksumFlags(getCmd)
+	flag.SetHashCacheFlags(getCmd)
+	flag.SetLimitRateFlags(getCmd)
+	flag.SetContinueOnErrorFlags(getCmd)
+	flag.SetFromListFlags(getCmd)
 
 	rootCmd.AddCommand(getCmd)
 }
@@ -70,15 +76,29 @@ func processGetCommand(command *cobra.Command, args []string) error {
 	differentialTransferFlagValues := flag.GetDifferentialTransferFlagValues()
 	noRootFlagValues := flag.GetNoRootFlagValues()
 	syncFlagValues := flag.GetSyncFlagValues()
+	verifyChecksumFlagValues := flag.GetVerifyChecksumFlagValues()
+	limitRateFlagValues, err := flag.GetLimitRateFlagValues()
+	if err != nil {
+		return xerrors.Errorf("failed to get limit rate: %w", err)
+	}
+	continueOnErrorFlagValues := flag.GetContinueOnErrorFlagValues()
+	fromListFlagValues := flag.GetFromListFlagValues()
+	hashCacheFlagValues := flag.GetHashCacheFlagValues()
 
 	maxConnectionNum := parallelTransferFlagValues.ThreadNumber + 2 // 2 for metadata op
 
-	if retryFlagValues.RetryNumber > 0 && !retryFlagValues.RetryChild {
-		err = commons.RunWithRetry(retryFlagValues.RetryNumber, retryFlagValues.RetryIntervalSeconds)
-		if err != nil {
-			return xerrors.Errorf("failed to run with retry %d: %w", retryFlagValues.RetryNumber, err)
-		}
-		return nil
+	targetPath := "./"
+	sourcePaths := args[:]
+
+	if len(args) >= 2 {
+		targetPath = args[len(args)-1]
+		sourcePaths = args[:len(args)-1]
+	}
+
+	getStdout := targetPath == commons.StdioPath
+	if getStdout && retryFlagValues.RetryNumber > 0 {
+		// data already written to stdout cannot be taken back
+		return xerrors.Errorf("failed to get to stdout, retry is not supported for stdout")
 	}
 
 	appConfig := commons.GetConfig()
@@ -105,30 +125,60 @@ func processGetCommand(command *cobra.Command, args []string) error {
 
 	defer filesystem.Release()
 
-	targetPath := "./"
-	sourcePaths := args[:]
-
-	if len(args) >= 2 {
-		targetPath = args[len(args)-1]
-		sourcePaths = args[:len(args)-1]
+	if getStdout {
+		for _, sourcePath := range sourcePaths {
+			err = getStdoutOne(filesystem, sourcePath)
+			if err != nil {
+				return xerrors.Errorf("failed to perform get %s to stdout: %w", sourcePath, err)
+			}
+		}
+		return nil
 	}
 
 	if noRootFlagValues.NoRoot && len(sourcePaths) > 1 {
 		return xerrors.Errorf("failed to get multiple source collections without creating root directory")
 	}
 
+	failureReport, err := newFailureReport(continueOnErrorFlagValues)
+	if err != nil {
+		return xerrors.Errorf("failed to set continue on error: %w", err)
+	}
+
+	pathFilter, err := newPathFilter(fromListFlagValues, syncFlagValues.Delete, false, func(p string) string {
+		return commons.MakeIRODSPath(commons.GetCWD(), commons.GetHomeDir(), commons.GetZone(), p)
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to set from list: %w", err)
+	}
+
 	parallelJobManager := commons.NewParallelJobManager(filesystem, parallelTransferFlagValues.ThreadNumber, progressFlagValues.ShowProgress)
+	parallelJobManager.SetRetryPolicy(commons.NewRetryPolicy(retryFlagValues.RetryNumber, retryFlagValues.RetryIntervalSeconds))
+	parallelJobManager.SetFailureReport(failureReport)
+	parallelJobManager.SetRateLimiter(commons.NewRateLimiter(limitRateFlagValues.LimitRate))
 	parallelJobManager.Start()
 
+	hashCache, err := newHashCache(hashCacheFlagValues)
+	if err != nil {
+		return xerrors.Errorf("failed to load hash cache: %w", err)
+	}
+	defer saveHashCache(hashCache, hashCacheFlagValues.PruneHashCache)
+
 	inputPathMap := map[string]bool{}
 
 	for _, sourcePath := range sourcePaths {
+		failedPath := commons.MakeIRODSPath(commons.GetCWD(), commons.GetHomeDir(), commons.GetZone(), sourcePath)
+
 		newTargetDirPath, err := makeGetTargetDirPath(filesystem, sourcePath, targetPath, noRootFlagValues.NoRoot)
 		if err != nil {
-			return xerrors.Errorf("failed to make new target path for get %s to %s: %w", sourcePath, targetPath, err)
+			err = failureReport.Continue(failedPath, commons.FailurePhaseScan, err)
+			if err != nil {
+				return xerrors.Errorf("failed to make new target path for get %s to %s: %w", sourcePath, targetPath, err)
+			}
+			continue
 		}
 
-		err = getOne(parallelJobManager, inputPathMap, sourcePath, newTargetDirPath, forceFlagValues.Force, differentialTransferFlagValues.DifferentialTransfer, differentialTransferFlagValues.NoHash)
+		err = getOne(parallelJobManager, inputPathMap, pathFilter, sourcePath, newTargetDirPath, forceFlagValues.Force, differentialTransferFlagValues.DifferentialTransfer, differentialTransferFlagValues.NoHash, hashCache, verifyChecksumFlagValues.VerifyChecksum, verifyChecksumFlagValues.KeepMismatched)
+		err = failureReport.Continue(failedPath, commons.FailurePhaseScan, err)
 		if err != nil {
 			return xerrors.Errorf("failed to perform get %s to %s: %w", sourcePath, targetPath, err)
 		}
@@ -140,6 +190,11 @@ func processGetCommand(command *cobra.Command, args []string) error {
 		return xerrors.Errorf("failed to perform parallel jobs: %w", err)
 	}
 
+	err = checkFailureReport(failureReport, continueOnErrorFlagValues.FailedListPath)
+	if err != nil {
+		return err
+	}
+
 	// delete extra
 	if syncFlagValues.Delete {
 		logger.Infof("deleting extra files and dirs under %s", targetPath)
@@ -153,7 +208,38 @@ func processGetCommand(command *cobra.Command, args []string) error {
 	return nil
 }
 
-func getOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[string]bool, sourcePath string, targetPath string, force bool, diff bool, noHash bool) error {
+// getStdoutOne writes the content of the data object to stdout
+func getStdoutOne(filesystem *irodsclient_fs.FileSystem, sourcePath string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "getStdoutOne",
+	})
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	sourcePath = commons.MakeIRODSPath(cwd, home, zone, sourcePath)
+
+	sourceEntry, err := filesystem.Stat(sourcePath)
+	if err != nil {
+		return xerrors.Errorf("failed to stat %s: %w", sourcePath, err)
+	}
+
+	if sourceEntry.Type != irodsclient_fs.FileEntry {
+		return xerrors.Errorf("cannot write a collection %s to stdout", sourcePath)
+	}
+
+	logger.Debugf("downloading a data object %s to stdout", sourcePath)
+
+	_, err = commons.DownloadToWriter(filesystem, sourcePath, os.Stdout)
+	if err != nil {
+		return xerrors.Errorf("failed to download %s to stdout: %w", sourcePath, err)
+	}
+
+	return nil
+}
+
+func getOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[string]bool, pathFilter *commons.PathFilter, sourcePath string, targetPath string, force bool, diff bool, noHash bool, hashCache *commons.HashCache, verifyChecksum bool, keepMismatched bool) error {
 	logger := log.WithFields(log.Fields{
 		"package":  "main",
 		"function": "getOne",
@@ -174,6 +260,10 @@ func getOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 
 	if sourceEntry.Type == irodsclient_fs.FileEntry {
 		// file
+		if !pathFilter.Match(sourcePath) {
+			return nil
+		}
+
 		targetFilePath := commons.MakeTargetLocalFilePath(sourcePath, targetPath)
 		commons.MarkPathMap(inputPathMap, targetFilePath)
 
@@ -195,6 +285,9 @@ func getOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 				job.Progress(processed, total, false)
 			}
 
+			// data downloaded before is not counted for the rate limit when resuming
+			callbackGet = manager.GetRateLimiter().WrapCallback(callbackGet, commons.GetDownloadResumedSize(targetFilePath, sourceEntry.Size))
+
 			job.Progress(0, sourceEntry.Size, false)
 
 			logger.Debugf("downloading a data object %s to %s", sourcePath, targetFilePath)
@@ -205,6 +298,21 @@ func getOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 			}
 
 			logger.Debugf("downloaded a data object %s to %s", sourcePath, targetFilePath)
+
+			if verifyChecksum {
+				logger.Debugf("verifying checksum of %s", targetFilePath)
+				err = commons.VerifyLocalFileChecksum(fs, targetFilePath, sourcePath)
+				if err != nil {
+					job.Progress(-1, sourceEntry.Size, true)
+
+					if commons.IsChecksumMismatchError(err) && !keepMismatched {
+						os.Remove(targetFilePath)
+					}
+
+					return xerrors.Errorf("failed to verify checksum of %s: %w", targetFilePath, err)
+				}
+			}
+
 			job.Progress(sourceEntry.Size, sourceEntry.Size, false)
 			return nil
 		}
@@ -235,7 +343,7 @@ func getOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 					if targetEntry.Size() == sourceEntry.Size {
 						if len(sourceEntry.CheckSum) > 0 {
 							// compare hash
-							hash, err := commons.HashLocalFile(targetFilePath, sourceEntry.CheckSumAlgorithm)
+							hash, err := hashCache.HashLocalFile(targetFilePath, sourceEntry.CheckSumAlgorithm)
 							if err != nil {
 								return xerrors.Errorf("failed to get hash of %s: %w", targetFilePath, err)
 							}
@@ -281,6 +389,10 @@ func getOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 			targetDirPath := targetPath
 			if entry.Type != irodsclient_fs.FileEntry {
 				// dir
+				if !pathFilter.MatchDir(entry.Path) {
+					continue
+				}
+
 				targetDirPath = commons.MakeTargetLocalFilePath(entry.Path, targetPath)
 				err = os.MkdirAll(targetDirPath, 0766)
 				if err != nil {
@@ -290,7 +402,8 @@ func getOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 
 			commons.MarkPathMap(inputPathMap, targetDirPath)
 
-			err = getOne(parallelJobManager, inputPathMap, entry.Path, targetDirPath, force, diff, noHash)
+			err = getOne(parallelJobManager, inputPathMap, pathFilter, entry.Path, targetDirPath, force, diff, noHash, hashCache, verifyChecksum, keepMismatched)
+			err = parallelJobManager.GetFailureReport().Continue(entry.Path, commons.FailurePhaseScan, err)
 			if err != nil {
 				return xerrors.Errorf("failed to perform get %s to %s: %w", entry.Path, targetDirPath, err)
 			}
@@ -0,0 +1,106 @@
+package subcmd
+
+import (
+	"fmt"
+	"os"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var headCmd = &cobra.Command{
+	Use:   "head [data-object1] [data-object2] ...",
+	Short: "Display the first part of iRODS data-objects",
+	Long:  `This displays the first lines of iRODS data-objects, or the first bytes with --bytes flag.`,
+	RunE:  processHeadCommand,
+	Args:  cobra.MinimumNArgs(1),
+}
+
+func AddHeadCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(headCmd)
+
+	flag.SetHeadTailFlags(headCmd)
+
+	rootCmd.AddCommand(headCmd)
+}
+
+func processHeadCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	headTailFlagValues := flag.GetHeadTailFlagValues()
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	for idx, sourcePath := range args {
+		if len(args) > 1 {
+			printHeadTailHeader(sourcePath, idx == 0)
+		}
+
+		err = headOne(filesystem, sourcePath, headTailFlagValues.Bytes, headTailFlagValues.Lines)
+		if err != nil {
+			return xerrors.Errorf("failed to perform head %s: %w", sourcePath, err)
+		}
+	}
+	return nil
+}
+
+func headOne(filesystem *irodsclient_fs.FileSystem, targetPath string, bytes int64, lines int64) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "headOne",
+	})
+
+	fh, _, err := openDataObjectForRead(filesystem, targetPath)
+	if err != nil {
+		return err
+	}
+
+	defer fh.Close()
+
+	if bytes >= 0 {
+		logger.Debugf("showing the first %d bytes of a data object %s", bytes, fh.GetEntry().Path)
+		_, err = commons.CopyRange(os.Stdout, fh, 0, bytes)
+	} else {
+		logger.Debugf("showing the first %d lines of a data object %s", lines, fh.GetEntry().Path)
+		_, err = commons.CopyLines(os.Stdout, fh, lines)
+	}
+
+	if err != nil {
+		return xerrors.Errorf("failed to read %s: %w", fh.GetEntry().Path, err)
+	}
+
+	return nil
+}
+
+func printHeadTailHeader(targetPath string, first bool) {
+	if !first {
+		fmt.Printf("\n")
+	}
+
+	fmt.Printf("==> %s <==\n", targetPath)
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"path"
 	"sort"
+	"strings"
+	"time"
 
 	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_conn "github.com/cyverse/go-irodsclient/irods/connection"
 	irodsclient_irodsfs "github.com/cyverse/go-irodsclient/irods/fs"
 	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
 	"github.com/cyverse/gocommands/cmd/flag"
@@ -30,6 +33,10 @@ func AddLsCommand(rootCmd *cobra.Command) {
 	flag.SetCommonFlags(lsCmd)
 
 	flag.SetListFlags(lsCmd)
+	flag.SetListACLFlags(lsCmd)
+	flag.SetListSortFlags(lsCmd)
+	flag.SetListFilterFlags(lsCmd)
+	flag.SetRecursiveFlags(lsCmd)
 	flag.SetTicketAccessFlags(lsCmd)
 
 	rootCmd.AddCommand(lsCmd)
@@ -58,6 +65,13 @@ func processLsCommand(command *cobra.Command, args []string) error {
 
 	ticketAccessFlagValues := flag.GetTicketAccessFlagValues()
 	listFlagValues := flag.GetListFlagValues()
+	listACLFlagValues := flag.GetListACLFlagValues()
+	listSortFlagValues := flag.GetListSortFlagValues()
+	listFilterFlagValues, err := flag.GetListFilterFlagValues()
+	if err != nil {
+		return xerrors.Errorf("failed to get list filter: %w", err)
+	}
+	recursiveFlagValues := flag.GetRecursiveFlagValues()
 
 	appConfig := commons.GetConfig()
 	syncAccount := false
@@ -90,7 +104,7 @@ func processLsCommand(command *cobra.Command, args []string) error {
 	}
 
 	for _, sourcePath := range sourcePaths {
-		err = listOne(filesystem, sourcePath, listFlagValues.Format, listFlagValues.HumanReadableSizes)
+		err = listOne(filesystem, sourcePath, listFlagValues, listACLFlagValues.ShowACL, recursiveFlagValues.Recursive, listSortFlagValues, listFilterFlagValues)
 		if err != nil {
 			return xerrors.Errorf("failed to perform ls %s: %w", sourcePath, err)
 		}
@@ -99,7 +113,7 @@ func processLsCommand(command *cobra.Command, args []string) error {
 	return nil
 }
 
-func listOne(fs *irodsclient_fs.FileSystem, sourcePath string, format flag.ListFormat, humanReadableSizes bool) error {
+func listOne(fs *irodsclient_fs.FileSystem, sourcePath string, listFlagValues *flag.ListFlagValues, showACL bool, recurse bool, sortFlagValues *flag.ListSortFlagValues, filterFlagValues *flag.ListFilterFlagValues) error {
 	cwd := commons.GetCWD()
 	home := commons.GetHomeDir()
 	zone := commons.GetZone()
@@ -119,6 +133,10 @@ func listOne(fs *irodsclient_fs.FileSystem, sourcePath string, format flag.ListF
 	}
 
 	if err == nil {
+		if recurse {
+			return listCollectionRecursively(fs, connection, sourcePath, listFlagValues, showACL, sortFlagValues, filterFlagValues)
+		}
+
 		colls, err := irodsclient_irodsfs.ListSubCollections(connection, sourcePath)
 		if err != nil {
 			return xerrors.Errorf("failed to list sub-collections in %s: %w", sourcePath, err)
@@ -129,8 +147,22 @@ func listOne(fs *irodsclient_fs.FileSystem, sourcePath string, format flag.ListF
 			return xerrors.Errorf("failed to list data-objects in %s: %w", sourcePath, err)
 		}
 
-		printDataObjects(objs, format, humanReadableSizes)
-		printCollections(colls)
+		var objAccesses map[string][]*irodsclient_types.IRODSAccess
+		var collAccesses map[string][]*irodsclient_types.IRODSAccess
+		if showACL {
+			objAccesses, collAccesses, err = listAccessesInCollection(connection, collection)
+			if err != nil {
+				return err
+			}
+		}
+
+		objs = filterDataObjects(objs, filterFlagValues)
+
+		sortDataObjects(objs, sortFlagValues.SortOrder, sortFlagValues.Reverse)
+		sortCollections(colls, sortFlagValues.SortOrder, sortFlagValues.Reverse)
+
+		printDataObjects(objs, objAccesses, listFlagValues.Format, listFlagValues.HumanReadableSizes)
+		printCollections(colls, collAccesses)
 		return nil
 	}
 
@@ -147,18 +179,203 @@ func listOne(fs *irodsclient_fs.FileSystem, sourcePath string, format flag.ListF
 		return xerrors.Errorf("failed to get data-object %s: %w", sourcePath, err)
 	}
 
-	printDataObject(entry, format, humanReadableSizes)
+	if len(filterDataObjects([]*irodsclient_types.IRODSDataObject{entry}, filterFlagValues)) == 0 {
+		// filtered out
+		return nil
+	}
+
+	printDataObject(entry, listFlagValues.Format, listFlagValues.HumanReadableSizes)
+
+	if showACL {
+		accesses, err := irodsclient_irodsfs.ListDataObjectAccesses(connection, parentCollection, path.Base(sourcePath))
+		if err != nil {
+			return xerrors.Errorf("failed to list accesses for data-object %s: %w", sourcePath, err)
+		}
+
+		printACLs(accesses)
+	}
+	return nil
+}
+
+// listCollectionRecursively lists all entries in the collection tree, entries are fetched with bulk queries
+func listCollectionRecursively(fs *irodsclient_fs.FileSystem, connection *irodsclient_conn.IRODSConnection, sourcePath string, listFlagValues *flag.ListFlagValues, showACL bool, sortFlagValues *flag.ListSortFlagValues, filterFlagValues *flag.ListFilterFlagValues) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "listCollectionRecursively",
+	})
+
+	logger.Debugf("listing collections in %s", sourcePath)
+
+	colls, err := commons.SearchCollections(fs, sourcePath)
+	if err != nil {
+		return xerrors.Errorf("failed to search collections in %s: %w", sourcePath, err)
+	}
+
+	logger.Debugf("listing data-objects in %s", sourcePath)
+
+	objs, err := commons.SearchDataObjects(fs, sourcePath, getDataObjectFilterConditions(filterFlagValues))
+	if err != nil {
+		return xerrors.Errorf("failed to search data-objects in %s: %w", sourcePath, err)
+	}
+
+	objs = filterDataObjects(objs, filterFlagValues)
+
+	objsMap := map[string][]*irodsclient_types.IRODSDataObject{}
+	for _, obj := range objs {
+		collPath := path.Dir(obj.Path)
+		objsMap[collPath] = append(objsMap[collPath], obj)
+	}
+
+	collsMap := map[string][]*irodsclient_types.IRODSCollection{}
+	for _, coll := range colls {
+		if coll.Path == sourcePath {
+			continue
+		}
+
+		parentPath := path.Dir(coll.Path)
+		collsMap[parentPath] = append(collsMap[parentPath], coll)
+	}
+
+	// colls are sorted by path, so parents come before their children
+	for _, coll := range colls {
+		subObjs := objsMap[coll.Path]
+		subColls := collsMap[coll.Path]
+
+		var objAccesses map[string][]*irodsclient_types.IRODSAccess
+		var collAccesses map[string][]*irodsclient_types.IRODSAccess
+		if showACL {
+			objAccesses, collAccesses, err = listAccessesInCollection(connection, coll)
+			if err != nil {
+				return err
+			}
+		}
+
+		sortDataObjects(subObjs, sortFlagValues.SortOrder, sortFlagValues.Reverse)
+		sortCollections(subColls, sortFlagValues.SortOrder, sortFlagValues.Reverse)
+
+		fmt.Printf("%s:\n", coll.Path)
+		printDataObjects(subObjs, objAccesses, listFlagValues.Format, listFlagValues.HumanReadableSizes)
+		printCollections(subColls, collAccesses)
+	}
+
 	return nil
 }
 
-func printDataObjects(entries []*irodsclient_types.IRODSDataObject, format flag.ListFormat, humanReadableSizes bool) {
-	// sort by name
+func listAccessesInCollection(connection *irodsclient_conn.IRODSConnection, collection *irodsclient_types.IRODSCollection) (map[string][]*irodsclient_types.IRODSAccess, map[string][]*irodsclient_types.IRODSAccess, error) {
+	accesses, err := irodsclient_irodsfs.ListAccessesForDataObjects(connection, collection)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to list accesses for data-objects in %s: %w", collection.Path, err)
+	}
+	objAccesses := makeAccessMap(accesses)
+
+	accesses, err = irodsclient_irodsfs.ListAccessesForSubCollections(connection, collection.Path)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to list accesses for sub-collections in %s: %w", collection.Path, err)
+	}
+	collAccesses := makeAccessMap(accesses)
+
+	return objAccesses, collAccesses, nil
+}
+
+// getDataObjectFilterConditions returns GenQuery conditions for the filter, so the server can drop data-objects early
+func getDataObjectFilterConditions(filterFlagValues *flag.ListFilterFlagValues) []commons.QueryCondition {
+	conditions := []commons.QueryCondition{}
+
+	if filterFlagValues.MinSize > 0 {
+		conditions = append(conditions, commons.GetDataObjectSizeCondition(">=", filterFlagValues.MinSize))
+	}
+
+	if !filterFlagValues.NewerThan.IsZero() {
+		conditions = append(conditions, commons.GetDataObjectModifyTimeCondition(">=", filterFlagValues.NewerThan))
+	}
+
+	if len(filterFlagValues.NamePattern) > 0 {
+		conditions = append(conditions, commons.GetDataObjectNameCondition(filterFlagValues.NamePattern))
+	}
+
+	return conditions
+}
+
+func filterDataObjects(entries []*irodsclient_types.IRODSDataObject, filterFlagValues *flag.ListFilterFlagValues) []*irodsclient_types.IRODSDataObject {
+	filteredEntries := []*irodsclient_types.IRODSDataObject{}
+	for _, entry := range entries {
+		if entry.Size < filterFlagValues.MinSize {
+			continue
+		}
+
+		if !filterFlagValues.NewerThan.IsZero() && getDataObjectModifyTime(entry).Before(filterFlagValues.NewerThan) {
+			continue
+		}
+
+		if len(filterFlagValues.NamePattern) > 0 {
+			matched, err := path.Match(filterFlagValues.NamePattern, entry.Name)
+			if err != nil || !matched {
+				continue
+			}
+		}
+
+		filteredEntries = append(filteredEntries, entry)
+	}
+
+	return filteredEntries
+}
+
+// getDataObjectModifyTime returns the latest modification time of replicas
+func getDataObjectModifyTime(entry *irodsclient_types.IRODSDataObject) time.Time {
+	modTime := time.Time{}
+	for _, replica := range entry.Replicas {
+		if replica.ModifyTime.After(modTime) {
+			modTime = replica.ModifyTime
+		}
+	}
+	return modTime
+}
+
+func sortDataObjects(entries []*irodsclient_types.IRODSDataObject, sortOrder flag.ListSortOrder, reverse bool) {
+	sort.SliceStable(entries, func(i int, j int) bool {
+		if reverse {
+			i, j = j, i
+		}
+
+		switch sortOrder {
+		case flag.ListSortOrderSize:
+			if entries[i].Size != entries[j].Size {
+				return entries[i].Size < entries[j].Size
+			}
+		case flag.ListSortOrderTime:
+			iTime := getDataObjectModifyTime(entries[i])
+			jTime := getDataObjectModifyTime(entries[j])
+			if !iTime.Equal(jTime) {
+				return iTime.Before(jTime)
+			}
+		}
+
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+func sortCollections(entries []*irodsclient_types.IRODSCollection, sortOrder flag.ListSortOrder, reverse bool) {
 	sort.SliceStable(entries, func(i int, j int) bool {
+		if reverse {
+			i, j = j, i
+		}
+
+		// collections do not have sizes
+		if sortOrder == flag.ListSortOrderTime && !entries[i].ModifyTime.Equal(entries[j].ModifyTime) {
+			return entries[i].ModifyTime.Before(entries[j].ModifyTime)
+		}
+
 		return entries[i].Name < entries[j].Name
 	})
+}
 
+func printDataObjects(entries []*irodsclient_types.IRODSDataObject, accesses map[string][]*irodsclient_types.IRODSAccess, format flag.ListFormat, humanReadableSizes bool) {
 	for _, entry := range entries {
 		printDataObject(entry, format, humanReadableSizes)
+
+		if accesses != nil {
+			printACLs(accesses[entry.Path])
+		}
 	}
 }
 
@@ -184,17 +401,33 @@ func printDataObject(entry *irodsclient_types.IRODSDataObject, format flag.ListF
 	}
 }
 
-func printCollections(entries []*irodsclient_types.IRODSCollection) {
-	// sort by name
-	sort.SliceStable(entries, func(i int, j int) bool {
-		return entries[i].Name < entries[j].Name
-	})
-
+func printCollections(entries []*irodsclient_types.IRODSCollection, accesses map[string][]*irodsclient_types.IRODSAccess) {
 	for _, entry := range entries {
 		fmt.Printf("  C- %s\n", entry.Path)
+
+		if accesses != nil {
+			printACLs(accesses[entry.Path])
+		}
 	}
 }
 
+func makeAccessMap(accesses []*irodsclient_types.IRODSAccess) map[string][]*irodsclient_types.IRODSAccess {
+	accessMap := map[string][]*irodsclient_types.IRODSAccess{}
+	for _, access := range accesses {
+		accessMap[access.Path] = append(accessMap[access.Path], access)
+	}
+	return accessMap
+}
+
+func printACLs(accesses []*irodsclient_types.IRODSAccess) {
+	accessStrings := []string{}
+	for _, access := range accesses {
+		accessStrings = append(accessStrings, commons.GetAccessString(access))
+	}
+
+	fmt.Printf("        ACL - %s\n", strings.Join(accessStrings, "   "))
+}
+
 func getStatusMark(status string) string {
 	switch status {
 	case "0":
@@ -0,0 +1,222 @@
+package subcmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	"github.com/dustin/go-humanize"
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var lsrescCmd = &cobra.Command{
+	Use:     "lsresc [resource_name1] [resource_name2] ...",
+	Aliases: []string{"ilsresc", "ls_resc", "list_resource"},
+	Short:   "List iRODS resources",
+	Long:    `This lists iRODS storage resources with their type, host, vault path, status, free space and children.`,
+	RunE:    processLsrescCommand,
+	Args:    cobra.ArbitraryArgs,
+}
+
+func AddLsrescCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(lsrescCmd)
+
+	flag.SetListFlags(lsrescCmd)
+	flag.SetResourceTreeFlags(lsrescCmd)
+
+	rootCmd.AddCommand(lsrescCmd)
+}
+
+func processLsrescCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	listFlagValues := flag.GetListFlagValues()
+	resourceTreeFlagValues := flag.GetResourceTreeFlagValues()
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	err = listResources(filesystem, args, listFlagValues.Format, listFlagValues.HumanReadableSizes, resourceTreeFlagValues.ShowTree)
+	if err != nil {
+		return xerrors.Errorf("failed to perform list resources: %w", err)
+	}
+
+	return nil
+}
+
+func listResources(fs *irodsclient_fs.FileSystem, resourceNames []string, format flag.ListFormat, humanReadableSizes bool, showTree bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "listResources",
+	})
+
+	logger.Debug("listing resources")
+
+	resources, err := commons.ListResources(fs)
+	if err != nil {
+		return xerrors.Errorf("failed to list resources: %w", err)
+	}
+
+	targetResources := []*commons.Resource{}
+	if len(resourceNames) == 0 {
+		if showTree {
+			targetResources = commons.GetRootResources(resources)
+		} else {
+			targetResources = resources
+		}
+	} else {
+		for _, resourceName := range resourceNames {
+			resource := commons.FindResource(resources, resourceName)
+			if resource == nil {
+				return xerrors.Errorf("failed to find resource %s", resourceName)
+			}
+
+			targetResources = append(targetResources, resource)
+		}
+	}
+
+	if len(targetResources) == 0 {
+		fmt.Printf("Found no resources\n")
+		return nil
+	}
+
+	// flatten the tree, depth is used for indentation
+	rows := []resourceRow{}
+	for _, resource := range targetResources {
+		if showTree {
+			rows = appendResourceTreeRows(rows, resource, 0)
+		} else {
+			rows = append(rows, resourceRow{Resource: resource})
+		}
+	}
+
+	switch format {
+	case flag.ListFormatLong, flag.ListFormatVeryLong:
+		printResourceTable(rows, format, humanReadableSizes)
+	default:
+		for _, row := range rows {
+			fmt.Printf("%s%s\n", getResourceTreeIndent(row.Depth), row.Resource.Name)
+		}
+	}
+
+	return nil
+}
+
+type resourceRow struct {
+	Resource *commons.Resource
+	Depth    int
+}
+
+func appendResourceTreeRows(rows []resourceRow, resource *commons.Resource, depth int) []resourceRow {
+	rows = append(rows, resourceRow{
+		Resource: resource,
+		Depth:    depth,
+	})
+
+	for _, child := range resource.Children {
+		rows = appendResourceTreeRows(rows, child, depth+1)
+	}
+
+	return rows
+}
+
+func getResourceTreeIndent(depth int) string {
+	if depth == 0 {
+		return ""
+	}
+
+	return strings.Repeat("  ", depth-1) + "└─ "
+}
+
+func printResourceTable(rows []resourceRow, format flag.ListFormat, humanReadableSizes bool) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+
+	header := table.Row{
+		"Name",
+		"Type",
+		"Host",
+		"Vault Path",
+		"Status",
+		"Free Space",
+		"Children",
+	}
+
+	if format == flag.ListFormatVeryLong {
+		header = append(header, "Zone", "Class", "Context", "Comment", "Modify Time")
+	}
+
+	t.AppendHeader(header, table.RowConfig{})
+
+	for _, row := range rows {
+		resource := row.Resource
+
+		children := []string{}
+		for _, child := range resource.Children {
+			children = append(children, child.Name)
+		}
+
+		tableRow := table.Row{
+			getResourceTreeIndent(row.Depth) + resource.Name,
+			resource.Type,
+			resource.Location,
+			resource.Path,
+			resource.Status,
+			getResourceFreeSpaceString(resource, humanReadableSizes),
+			strings.Join(children, ","),
+		}
+
+		if format == flag.ListFormatVeryLong {
+			tableRow = append(tableRow,
+				resource.Zone,
+				resource.Class,
+				resource.Context,
+				resource.Comment,
+				commons.MakeDateTimeString(resource.ModifyTime),
+			)
+		}
+
+		t.AppendRow(tableRow, table.RowConfig{})
+	}
+
+	t.Render()
+}
+
+func getResourceFreeSpaceString(resource *commons.Resource, humanReadableSizes bool) string {
+	freeSpace := resource.GetFreeSpace()
+	if freeSpace < 0 {
+		return resource.FreeSpace
+	}
+
+	if humanReadableSizes {
+		return humanize.Bytes(uint64(freeSpace))
+	}
+
+	return fmt.Sprintf("%d", freeSpace)
+}
@@ -0,0 +1,387 @@
+package subcmd
+
+import (
+	"fmt"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_irodsfs "github.com/cyverse/go-irodsclient/irods/fs"
+	"github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var metaCmd = &cobra.Command{
+	Use:     "meta [subcommand]",
+	Aliases: []string{"imeta", "metadata"},
+	Short:   "Manage AVU metadata of iRODS data-objects, collections, users and resources",
+	Long:    `This manages attribute-value-unit (AVU) metadata of iRODS data-objects, collections, users and resources.`,
+	RunE:    processMetaCommand,
+	Args:    cobra.NoArgs,
+}
+
+var metaAddCmd = &cobra.Command{
+	Use:   "add [data-object|collection|user|resource] [attribute] [value] [units]",
+	Short: "Add an AVU metadata",
+	Long:  `This adds an AVU metadata to the given target.`,
+	RunE:  processMetaAddCommand,
+	Args:  cobra.RangeArgs(3, 4),
+}
+
+var metaLsCmd = &cobra.Command{
+	Use:     "ls [data-object|collection|user|resource] ...",
+	Aliases: []string{"list"},
+	Short:   "List AVU metadata",
+	Long:    `This lists AVU metadata of the given targets.`,
+	RunE:    processMetaLsCommand,
+	Args:    cobra.MinimumNArgs(1),
+}
+
+var metaModCmd = &cobra.Command{
+	Use:   "mod [data-object|collection|user|resource] [attribute] [value] [units]",
+	Short: "Modify an AVU metadata",
+	Long:  `This modifies an existing AVU metadata of the given target.`,
+	RunE:  processMetaModCommand,
+	Args:  cobra.RangeArgs(3, 4),
+}
+
+var metaRmCmd = &cobra.Command{
+	Use:     "rm [data-object|collection|user|resource] [attribute] [value] [units]",
+	Aliases: []string{"remove", "del"},
+	Short:   "Remove AVU metadata",
+	Long:    `This removes AVU metadata from the given target. If value is not given, all AVUs having the attribute are removed.`,
+	RunE:    processMetaRmCommand,
+	Args:    cobra.RangeArgs(2, 4),
+}
+
+func AddMetaCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(metaCmd)
+
+	for _, subCmd := range []*cobra.Command{metaAddCmd, metaLsCmd, metaModCmd, metaRmCmd} {
+		flag.SetCommonFlags(subCmd)
+		flag.SetMetaTargetFlags(subCmd)
+
+		metaCmd.AddCommand(subCmd)
+	}
+
+	flag.SetMetaUpdateFlags(metaModCmd)
+
+	rootCmd.AddCommand(metaCmd)
+}
+
+func processMetaCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// if nothing is given
+	command.Usage()
+
+	return nil
+}
+
+func getMetaFSClient(command *cobra.Command) (*irodsclient_fs.FileSystem, bool, error) {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil, false, nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	return filesystem, true, nil
+}
+
+func processMetaAddCommand(command *cobra.Command, args []string) error {
+	filesystem, cont, err := getMetaFSClient(command)
+	if err != nil || !cont {
+		return err
+	}
+
+	defer filesystem.Release()
+
+	metaTargetFlagValues := flag.GetMetaTargetFlagValues()
+
+	meta := makeMetaFromArgs(args[1:])
+
+	err = addMetaOne(filesystem, metaTargetFlagValues.Type, args[0], meta)
+	if err != nil {
+		return xerrors.Errorf("failed to perform add meta to %s: %w", args[0], err)
+	}
+
+	return nil
+}
+
+func processMetaLsCommand(command *cobra.Command, args []string) error {
+	filesystem, cont, err := getMetaFSClient(command)
+	if err != nil || !cont {
+		return err
+	}
+
+	defer filesystem.Release()
+
+	metaTargetFlagValues := flag.GetMetaTargetFlagValues()
+
+	for _, target := range args {
+		err = listMetaOne(filesystem, metaTargetFlagValues.Type, target)
+		if err != nil {
+			return xerrors.Errorf("failed to perform list meta of %s: %w", target, err)
+		}
+	}
+
+	return nil
+}
+
+func processMetaModCommand(command *cobra.Command, args []string) error {
+	filesystem, cont, err := getMetaFSClient(command)
+	if err != nil || !cont {
+		return err
+	}
+
+	defer filesystem.Release()
+
+	metaTargetFlagValues := flag.GetMetaTargetFlagValues()
+	metaUpdateFlagValues := flag.GetMetaUpdateFlagValues(command)
+
+	if !metaUpdateFlagValues.NameUpdated && !metaUpdateFlagValues.ValueUpdated && !metaUpdateFlagValues.UnitsUpdated {
+		return xerrors.Errorf("nothing to modify, one of new_name, new_value or new_units must be given")
+	}
+
+	oldMeta := makeMetaFromArgs(args[1:])
+
+	update := &commons.MetadataUpdate{
+		Name:         metaUpdateFlagValues.Name,
+		NameUpdated:  metaUpdateFlagValues.NameUpdated,
+		Value:        metaUpdateFlagValues.Value,
+		ValueUpdated: metaUpdateFlagValues.ValueUpdated,
+		Units:        metaUpdateFlagValues.Units,
+		UnitsUpdated: metaUpdateFlagValues.UnitsUpdated,
+	}
+
+	err = modMetaOne(filesystem, metaTargetFlagValues.Type, args[0], oldMeta, update)
+	if err != nil {
+		return xerrors.Errorf("failed to perform modify meta of %s: %w", args[0], err)
+	}
+
+	return nil
+}
+
+func processMetaRmCommand(command *cobra.Command, args []string) error {
+	filesystem, cont, err := getMetaFSClient(command)
+	if err != nil || !cont {
+		return err
+	}
+
+	defer filesystem.Release()
+
+	metaTargetFlagValues := flag.GetMetaTargetFlagValues()
+
+	meta := makeMetaFromArgs(args[1:])
+
+	err = removeMetaOne(filesystem, metaTargetFlagValues.Type, args[0], meta)
+	if err != nil {
+		return xerrors.Errorf("failed to perform remove meta from %s: %w", args[0], err)
+	}
+
+	return nil
+}
+
+// makeMetaFromArgs makes an AVU from [attribute] [value] [units] args
+func makeMetaFromArgs(args []string) *types.IRODSMeta {
+	meta := &types.IRODSMeta{}
+
+	if len(args) > 0 {
+		meta.Name = args[0]
+	}
+
+	if len(args) > 1 {
+		meta.Value = args[1]
+	}
+
+	if len(args) > 2 {
+		meta.Units = args[2]
+	}
+
+	return meta
+}
+
+// resolveMetaTarget returns the metadata item type and the item name of the target
+func resolveMetaTarget(filesystem *irodsclient_fs.FileSystem, targetType flag.MetaTargetType, target string) (types.IRODSMetaItemType, string, error) {
+	switch targetType {
+	case flag.MetaTargetTypeUser:
+		return types.IRODSUserMetaItemType, target, nil
+	case flag.MetaTargetTypeResource:
+		return types.IRODSResourceMetaItemType, target, nil
+	}
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	targetPath := commons.MakeIRODSPath(cwd, home, zone, target)
+
+	targetEntry, err := filesystem.Stat(targetPath)
+	if err != nil {
+		return "", "", xerrors.Errorf("failed to stat %s: %w", targetPath, err)
+	}
+
+	if targetEntry.Type == irodsclient_fs.FileEntry {
+		return types.IRODSDataObjectMetaItemType, targetPath, nil
+	}
+
+	return types.IRODSCollectionMetaItemType, targetPath, nil
+}
+
+func addMetaOne(filesystem *irodsclient_fs.FileSystem, targetType flag.MetaTargetType, target string, meta *types.IRODSMeta) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "addMetaOne",
+	})
+
+	itemType, itemName, err := resolveMetaTarget(filesystem, targetType, target)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("adding metadata %s=%s (%s) to %s", meta.Name, meta.Value, meta.Units, itemName)
+
+	switch itemType {
+	case types.IRODSUserMetaItemType:
+		err = filesystem.AddUserMetadata(itemName, 0, meta.Name, meta.Value, meta.Units)
+	case types.IRODSResourceMetaItemType:
+		connection, connErr := filesystem.GetMetadataConnection()
+		if connErr != nil {
+			return xerrors.Errorf("failed to get connection: %w", connErr)
+		}
+		defer filesystem.ReturnMetadataConnection(connection)
+
+		err = irodsclient_irodsfs.AddResourceMeta(connection, itemName, meta)
+	default:
+		err = filesystem.AddMetadata(itemName, meta.Name, meta.Value, meta.Units)
+	}
+
+	if err != nil {
+		return xerrors.Errorf("failed to add metadata to %s: %w", itemName, err)
+	}
+
+	return nil
+}
+
+func listMetaOne(filesystem *irodsclient_fs.FileSystem, targetType flag.MetaTargetType, target string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "listMetaOne",
+	})
+
+	itemType, itemName, err := resolveMetaTarget(filesystem, targetType, target)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("listing metadata of %s", itemName)
+
+	var metas []*types.IRODSMeta
+	switch itemType {
+	case types.IRODSUserMetaItemType:
+		metas, err = filesystem.ListUserMetadata(itemName)
+	case types.IRODSResourceMetaItemType:
+		metas, err = commons.ListResourceMetadata(filesystem, itemName)
+	default:
+		metas, err = filesystem.ListMetadata(itemName)
+	}
+
+	if err != nil {
+		return xerrors.Errorf("failed to list metadata of %s: %w", itemName, err)
+	}
+
+	printMetas(itemName, metas)
+	return nil
+}
+
+func modMetaOne(filesystem *irodsclient_fs.FileSystem, targetType flag.MetaTargetType, target string, oldMeta *types.IRODSMeta, update *commons.MetadataUpdate) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "modMetaOne",
+	})
+
+	itemType, itemName, err := resolveMetaTarget(filesystem, targetType, target)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("modifying metadata %s=%s (%s) of %s", oldMeta.Name, oldMeta.Value, oldMeta.Units, itemName)
+
+	return commons.ModifyMetadata(filesystem, itemType, itemName, oldMeta, update)
+}
+
+func removeMetaOne(filesystem *irodsclient_fs.FileSystem, targetType flag.MetaTargetType, target string, meta *types.IRODSMeta) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "removeMetaOne",
+	})
+
+	itemType, itemName, err := resolveMetaTarget(filesystem, targetType, target)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("removing metadata %s=%s (%s) from %s", meta.Name, meta.Value, meta.Units, itemName)
+
+	switch itemType {
+	case types.IRODSUserMetaItemType:
+		err = filesystem.DeleteUserMetadata(itemName, 0, meta.Name, meta.Value, meta.Units)
+	case types.IRODSResourceMetaItemType:
+		connection, connErr := filesystem.GetMetadataConnection()
+		if connErr != nil {
+			return xerrors.Errorf("failed to get connection: %w", connErr)
+		}
+		defer filesystem.ReturnMetadataConnection(connection)
+
+		err = irodsclient_irodsfs.DeleteResourceMeta(connection, itemName, meta)
+	default:
+		err = filesystem.DeleteMetadata(itemName, meta.Name, meta.Value, meta.Units)
+	}
+
+	if err != nil {
+		return xerrors.Errorf("failed to remove metadata from %s: %w", itemName, err)
+	}
+
+	return nil
+}
+
+func printMetas(itemName string, metas []*types.IRODSMeta) {
+	fmt.Printf("[%s]\n", itemName)
+
+	if len(metas) == 0 {
+		fmt.Printf("  No metadata\n")
+		return
+	}
+
+	for _, meta := range metas {
+		fmt.Printf("  - id: %d\n", meta.AVUID)
+		fmt.Printf("    attribute: %s\n", meta.Name)
+		fmt.Printf("    value: %s\n", meta.Value)
+		fmt.Printf("    units: %s\n", meta.Units)
+	}
+}
@@ -0,0 +1,160 @@
+package subcmd
+
+import (
+	"fmt"
+
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var phymvCmd = &cobra.Command{
+	Use:     "phymv [data-object1] [data-object2] [collection1] ...",
+	Aliases: []string{"iphymv"},
+	Short:   "Move replicas of iRODS data-objects between resources",
+	Long:    `This physically moves replicas of iRODS data-objects from the resource given with -S flag to the resource given with -R flag.`,
+	RunE:    processPhymvCommand,
+	Args:    cobra.MinimumNArgs(1),
+}
+
+func AddPhymvCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(phymvCmd)
+
+	flag.SetRecursiveFlags(phymvCmd)
+	flag.SetProgressFlags(phymvCmd)
+	flag.SetDryRunFlags(phymvCmd)
+	flag.SetReplicaSourceFlags(phymvCmd)
+
+	rootCmd.AddCommand(phymvCmd)
+}
+
+func processPhymvCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	recursiveFlagValues := flag.GetRecursiveFlagValues()
+	progressFlagValues := flag.GetProgressFlagValues()
+	dryRunFlagValues := flag.GetDryRunFlagValues()
+	replicaSourceFlagValues := flag.GetReplicaSourceFlagValues()
+	commonFlagValues := flag.GetCommonFlagValues(command)
+
+	if len(replicaSourceFlagValues.SourceResource) == 0 {
+		return xerrors.Errorf("source resource is not given, use -S flag")
+	}
+
+	// the default resource in config is not used to not move replicas to an unintended resource
+	if !commonFlagValues.ResourceUpdated || len(commonFlagValues.Resource) == 0 {
+		return xerrors.Errorf("target resource is not given, use -R flag")
+	}
+
+	targetResource := commonFlagValues.Resource
+
+	// Create a file system
+	account := commons.GetAccount()
+
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	parallelJobManager := commons.NewParallelJobManager(filesystem, commons.TransferTreadNumDefault, progressFlagValues.ShowProgress)
+	parallelJobManager.Start()
+
+	for _, sourcePath := range args {
+		err = phymvOne(parallelJobManager, sourcePath, replicaSourceFlagValues.SourceResource, targetResource, recursiveFlagValues.Recursive, dryRunFlagValues.DryRun)
+		if err != nil {
+			return xerrors.Errorf("failed to perform phymv %s from %s to %s: %w", sourcePath, replicaSourceFlagValues.SourceResource, targetResource, err)
+		}
+	}
+
+	parallelJobManager.DoneScheduling()
+	err = parallelJobManager.Wait()
+	if err != nil {
+		return xerrors.Errorf("failed to perform parallel job: %w", err)
+	}
+
+	return nil
+}
+
+func phymvOne(parallelJobManager *commons.ParallelJobManager, sourcePath string, sourceResource string, targetResource string, recurse bool, dryRun bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "phymvOne",
+	})
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	sourcePath = commons.MakeIRODSPath(cwd, home, zone, sourcePath)
+
+	filesystem := parallelJobManager.GetFilesystem()
+
+	entries, err := getDataObjects(filesystem, sourcePath, recurse)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !hasReplicaInResource(entry, sourceResource) {
+			logger.Debugf("skip moving a data object %s, no replica in %s", entry.Path, sourceResource)
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("move replica of %s from %s to %s\n", entry.Path, sourceResource, targetResource)
+			continue
+		}
+
+		entryPath := entry.Path
+		phymvTask := func(job *commons.ParallelJob) error {
+			manager := job.GetManager()
+			fs := manager.GetFilesystem()
+
+			job.Progress(0, 1, false)
+
+			logger.Debugf("moving a replica of data object %s from %s to %s", entryPath, sourceResource, targetResource)
+			err := commons.MoveReplica(fs, entryPath, sourceResource, targetResource)
+			if err != nil {
+				job.Progress(-1, 1, true)
+				return xerrors.Errorf("failed to move replica of %s: %w", entryPath, err)
+			}
+
+			logger.Debugf("moved a replica of data object %s from %s to %s", entryPath, sourceResource, targetResource)
+			job.Progress(1, 1, false)
+			return nil
+		}
+
+		parallelJobManager.Schedule(entryPath, phymvTask, 1, progress.UnitsDefault)
+		logger.Debugf("scheduled moving a replica of data object %s from %s to %s", entryPath, sourceResource, targetResource)
+	}
+
+	return nil
+}
+
+func hasReplicaInResource(entry *irodsclient_types.IRODSDataObject, resource string) bool {
+	for _, replica := range entry.Replicas {
+		if commons.IsReplicaInResource(replica, resource) {
+			return true
+		}
+	}
+	return false
+}
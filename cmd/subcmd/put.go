@@ -20,9 +20,10 @@ var putCmd = &cobra.Command{
 	Use:     "put [local file1] [local file2] [local dir1] ... [collection]",
 	Aliases: []string{"iput", "upload"},
 	Short:   "Upload files or directories",
-	Long:    `This uploads files or directories to the given iRODS collection.`,
-	RunE:    processPutCommand,
-	Args:    cobra.MinimumNArgs(1),
+	Long: `This uploads files or directories to the given iRODS collection.
+If the source is "-", data from stdin is uploaded to the given data-object path.`,
+	RunE: processPutCommand,
+	Args: cobra.MinimumNArgs(1),
 }
 
 func AddPutCommand(rootCmd *cobra.Command) {
@@ -37,6 +38,12 @@ func AddPutCommand(rootCmd *cobra.Command) {
 	flag.SetDifferentialTransferFlags(putCmd, true)
 	flag.SetNoRootFlags(putCmd)
 	flag.SetSyncFlags(putCmd)
+	flag.SetVerifyChecksumFlags(putCmd)
+	flag.SetHashCacheFlags(putCmd)
+	flag.SetLimitRateFlags(putCmd)
+	flag.SetContinueOnErrorFlags(putCmd)
+	flag.SetFromListFlags(putCmd)
+	flag.SetLocalTempFlags(putCmd)
 
 	rootCmd.AddCommand(putCmd)
 }
@@ -70,15 +77,30 @@ func processPutCommand(command *cobra.Command, args []string) error {
 	differentialTransferFlagValues := flag.GetDifferentialTransferFlagValues()
 	noRootFlagValues := flag.GetNoRootFlagValues()
 	syncFlagValues := flag.GetSyncFlagValues()
+	verifyChecksumFlagValues := flag.GetVerifyChecksumFlagValues()
+	limitRateFlagValues, err := flag.GetLimitRateFlagValues()
+	if err != nil {
+		return xerrors.Errorf("failed to get limit rate: %w", err)
+	}
+	continueOnErrorFlagValues := flag.GetContinueOnErrorFlagValues()
+	fromListFlagValues := flag.GetFromListFlagValues()
+	hashCacheFlagValues := flag.GetHashCacheFlagValues()
+	bundleTempFlagValues := flag.GetBundleTempFlagValues()
 
 	maxConnectionNum := parallelTransferFlagValues.ThreadNumber + 2 // 2 for metadata op
 
-	if retryFlagValues.RetryNumber > 0 && !retryFlagValues.RetryChild {
-		err = commons.RunWithRetry(retryFlagValues.RetryNumber, retryFlagValues.RetryIntervalSeconds)
-		if err != nil {
-			return xerrors.Errorf("failed to run with retry %d: %w", retryFlagValues.RetryNumber, err)
-		}
-		return nil
+	targetPath := "./"
+	sourcePaths := args[:]
+
+	if len(args) >= 2 {
+		targetPath = args[len(args)-1]
+		sourcePaths = args[:len(args)-1]
+	}
+
+	putStdin := len(sourcePaths) == 1 && sourcePaths[0] == commons.StdioPath
+	if putStdin && retryFlagValues.RetryNumber > 0 {
+		// stdin cannot be read again
+		return xerrors.Errorf("failed to put stdin, retry is not supported for stdin")
 	}
 
 	appConfig := commons.GetConfig()
@@ -105,30 +127,56 @@ func processPutCommand(command *cobra.Command, args []string) error {
 
 	defer filesystem.Release()
 
-	targetPath := "./"
-	sourcePaths := args[:]
-
-	if len(args) >= 2 {
-		targetPath = args[len(args)-1]
-		sourcePaths = args[:len(args)-1]
+	if putStdin {
+		err = putStdinOne(filesystem, targetPath, forceFlagValues.Force)
+		if err != nil {
+			return xerrors.Errorf("failed to perform put stdin to %s: %w", targetPath, err)
+		}
+		return nil
 	}
 
 	if noRootFlagValues.NoRoot && len(sourcePaths) > 1 {
 		return xerrors.Errorf("failed to put multiple source dirs without creating root directory")
 	}
 
+	failureReport, err := newFailureReport(continueOnErrorFlagValues)
+	if err != nil {
+		return xerrors.Errorf("failed to set continue on error: %w", err)
+	}
+
+	pathFilter, err := newPathFilter(fromListFlagValues, syncFlagValues.Delete, true, commons.MakeLocalPath)
+	if err != nil {
+		return xerrors.Errorf("failed to set from list: %w", err)
+	}
+
 	parallelJobManager := commons.NewParallelJobManager(filesystem, parallelTransferFlagValues.ThreadNumber, progressFlagValues.ShowProgress)
+	parallelJobManager.SetRetryPolicy(commons.NewRetryPolicy(retryFlagValues.RetryNumber, retryFlagValues.RetryIntervalSeconds))
+	parallelJobManager.SetFailureReport(failureReport)
+	parallelJobManager.SetRateLimiter(commons.NewRateLimiter(limitRateFlagValues.LimitRate))
 	parallelJobManager.Start()
 
+	hashCache, err := newHashCache(hashCacheFlagValues)
+	if err != nil {
+		return xerrors.Errorf("failed to load hash cache: %w", err)
+	}
+	defer saveHashCache(hashCache, hashCacheFlagValues.PruneHashCache)
+
 	inputPathMap := map[string]bool{}
 
 	for _, sourcePath := range sourcePaths {
+		failedPath := commons.MakeLocalPath(sourcePath)
+
 		newTargetDirPath, err := makePutTargetDirPath(filesystem, sourcePath, targetPath, noRootFlagValues.NoRoot)
 		if err != nil {
-			return xerrors.Errorf("failed to make new target path for put %s to %s: %w", sourcePath, targetPath, err)
+			err = failureReport.Continue(failedPath, commons.FailurePhaseScan, err)
+			if err != nil {
+				return xerrors.Errorf("failed to make new target path for put %s to %s: %w", sourcePath, targetPath, err)
+			}
+			continue
 		}
 
-		err = putOne(parallelJobManager, inputPathMap, sourcePath, newTargetDirPath, forceFlagValues.Force, parallelTransferFlagValues.SingleTread, differentialTransferFlagValues.DifferentialTransfer, differentialTransferFlagValues.NoHash)
+		err = putOne(parallelJobManager, inputPathMap, pathFilter, sourcePath, newTargetDirPath, forceFlagValues.Force, parallelTransferFlagValues.SingleTread, differentialTransferFlagValues.DifferentialTransfer, differentialTransferFlagValues.NoHash, hashCache, verifyChecksumFlagValues.VerifyChecksum, verifyChecksumFlagValues.KeepMismatched, bundleTempFlagValues.LocalTempPath)
+		err = failureReport.Continue(failedPath, commons.FailurePhaseScan, err)
 		if err != nil {
 			return xerrors.Errorf("failed to perform put %s to %s: %w", sourcePath, targetPath, err)
 		}
@@ -140,6 +188,11 @@ func processPutCommand(command *cobra.Command, args []string) error {
 		return xerrors.Errorf("failed to perform parallel jobs: %w", err)
 	}
 
+	err = checkFailureReport(failureReport, continueOnErrorFlagValues.FailedListPath)
+	if err != nil {
+		return err
+	}
+
 	// delete extra
 	if syncFlagValues.Delete {
 		logger.Infof("deleting extra files and dirs under %s", targetPath)
@@ -153,7 +206,7 @@ func processPutCommand(command *cobra.Command, args []string) error {
 	return nil
 }
 
-func putOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[string]bool, sourcePath string, targetPath string, force bool, singleThreaded bool, diff bool, noHash bool) error {
+func putOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[string]bool, pathFilter *commons.PathFilter, sourcePath string, targetPath string, force bool, singleThreaded bool, diff bool, noHash bool, hashCache *commons.HashCache, verifyChecksum bool, keepMismatched bool, localTempPath string) error {
 	logger := log.WithFields(log.Fields{
 		"package":  "main",
 		"function": "putOne",
@@ -178,6 +231,10 @@ func putOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 
 	if !sourceStat.IsDir() {
 		// file
+		if !pathFilter.Match(sourcePath) {
+			return nil
+		}
+
 		targetFilePath := commons.MakeTargetIRODSFilePath(filesystem, sourcePath, targetPath)
 		commons.MarkPathMap(inputPathMap, targetFilePath)
 
@@ -199,13 +256,33 @@ func putOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 				job.Progress(processed, total, false)
 			}
 
+			// chunks uploaded before are not counted for the rate limit when resuming
+			resumedSize := int64(0)
+			if !singleThreaded {
+				resumedSize = commons.GetUploadResumedSize(localTempPath, sourcePath, targetFilePath)
+			}
+			callbackPut = manager.GetRateLimiter().WrapCallback(callbackPut, resumedSize)
+
 			job.Progress(0, sourceStat.Size(), false)
 
 			logger.Debugf("uploading a file %s to %s", sourcePath, targetFilePath)
+
+			// hash data read for the upload to not read the file again for verification
+			hashAlg := ""
+			if verifyChecksum {
+				hashAlg = commons.GetUploadChecksumAlgorithm()
+			}
+
+			var hash string
 			if singleThreaded {
-				err = fs.UploadFile(sourcePath, targetFilePath, "", false, callbackPut)
+				if verifyChecksum {
+					hash, err = commons.UploadFileWithHash(fs, sourcePath, targetFilePath, "", hashAlg, callbackPut)
+				} else {
+					err = fs.UploadFile(sourcePath, targetFilePath, "", false, callbackPut)
+				}
 			} else {
-				err = fs.UploadFileParallel(sourcePath, targetFilePath, "", 0, false, callbackPut)
+				// record chunks written to resume the upload on retry
+				hash, err = commons.UploadFileParallelResumable(fs, sourcePath, targetFilePath, "", 0, localTempPath, hashAlg, callbackPut)
 			}
 
 			if err != nil {
@@ -214,12 +291,33 @@ func putOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 			}
 
 			logger.Debugf("uploaded a file %s to %s", sourcePath, targetFilePath)
+
+			if verifyChecksum {
+				logger.Debugf("verifying checksum of %s", targetFilePath)
+				err = commons.VerifyLocalFileChecksumWithHashes(fs, sourcePath, targetFilePath, map[string]string{hashAlg: hash})
+				if err != nil {
+					job.Progress(-1, sourceStat.Size(), true)
+
+					if commons.IsChecksumMismatchError(err) && !keepMismatched {
+						fs.RemoveFile(targetFilePath, true)
+					}
+
+					return xerrors.Errorf("failed to verify checksum of %s: %w", targetFilePath, err)
+				}
+			}
+
 			job.Progress(sourceStat.Size(), sourceStat.Size(), false)
 			return nil
 		}
 
 		if fileExist {
-			if diff {
+			// the size of an incomplete data object may match as chunks are written in parallel
+			resumable := !singleThreaded && commons.HasUploadStatus(localTempPath, sourcePath, targetFilePath)
+
+			if resumable {
+				// incomplete data object - resume uploading
+				fmt.Printf("resume uploading a file %s\n", targetFilePath)
+			} else if diff {
 				if noHash {
 					if targetEntry.Size == sourceStat.Size() {
 						fmt.Printf("skip uploading a file %s. The file already exists!\n", targetFilePath)
@@ -229,7 +327,7 @@ func putOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 					if targetEntry.Size == sourceStat.Size() {
 						if len(targetEntry.CheckSum) > 0 {
 							// compare hash
-							hash, err := commons.HashLocalFile(sourcePath, targetEntry.CheckSumAlgorithm)
+							hash, err := hashCache.HashLocalFile(sourcePath, targetEntry.CheckSumAlgorithm)
 							if err != nil {
 								return xerrors.Errorf("failed to get hash for %s: %w", sourcePath, err)
 							}
@@ -265,9 +363,15 @@ func putOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 		}
 
 		for _, entry := range entries {
+			newSourcePath := filepath.Join(sourcePath, entry.Name())
+
 			targetDirPath := targetPath
 			if entry.IsDir() {
 				// dir
+				if !pathFilter.MatchDir(newSourcePath) {
+					continue
+				}
+
 				targetDirPath = commons.MakeTargetIRODSFilePath(filesystem, entry.Name(), targetPath)
 				err = filesystem.MakeDir(targetDirPath, true)
 				if err != nil {
@@ -277,8 +381,8 @@ func putOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 
 			commons.MarkPathMap(inputPathMap, targetDirPath)
 
-			newSourcePath := filepath.Join(sourcePath, entry.Name())
-			err = putOne(parallelJobManager, inputPathMap, newSourcePath, targetDirPath, force, singleThreaded, diff, noHash)
+			err = putOne(parallelJobManager, inputPathMap, pathFilter, newSourcePath, targetDirPath, force, singleThreaded, diff, noHash, hashCache, verifyChecksum, keepMismatched, localTempPath)
+			err = parallelJobManager.GetFailureReport().Continue(newSourcePath, commons.FailurePhaseScan, err)
 			if err != nil {
 				return xerrors.Errorf("failed to perform put %s to %s: %w", newSourcePath, targetDirPath, err)
 			}
@@ -287,6 +391,81 @@ func putOne(parallelJobManager *commons.ParallelJobManager, inputPathMap map[str
 	return nil
 }
 
+// putStdinOne uploads data from stdin to the data object and registers its checksum
+func putStdinOne(filesystem *irodsclient_fs.FileSystem, targetPath string, force bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "putStdinOne",
+	})
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	targetPath = commons.MakeIRODSPath(cwd, home, zone, targetPath)
+
+	targetEntry, err := filesystem.Stat(targetPath)
+	if err != nil {
+		if !irodsclient_types.IsFileNotFoundError(err) {
+			return xerrors.Errorf("failed to stat %s: %w", targetPath, err)
+		}
+	} else {
+		if targetEntry.Type == irodsclient_fs.DirectoryEntry {
+			return xerrors.Errorf("target %s is a collection, a data-object path must be given for stdin", targetPath)
+		}
+
+		if !force {
+			// cannot ask as stdin has data
+			return xerrors.Errorf("data object %s already exists, use force flag to overwrite", targetPath)
+		}
+	}
+
+	logger.Debugf("uploading stdin to %s", targetPath)
+
+	size, err := commons.UploadFromReader(filesystem, os.Stdin, targetPath, "")
+	if err != nil {
+		return xerrors.Errorf("failed to upload stdin to %s: %w", targetPath, err)
+	}
+
+	logger.Debugf("uploaded %d bytes from stdin to %s, computing checksum", size, targetPath)
+
+	_, err = commons.ComputeChecksum(filesystem, targetPath, false)
+	if err != nil {
+		return xerrors.Errorf("failed to compute checksum of %s: %w", targetPath, err)
+	}
+
+	return nil
+}
+
+// newHashCache loads a hash cache if it is enabled, returns nil otherwise
+func newHashCache(hashCacheFlagValues *flag.HashCacheFlagValues) (*commons.HashCache, error) {
+	if !hashCacheFlagValues.UseHashCache {
+		return nil, nil
+	}
+
+	return commons.NewHashCache(hashCacheFlagValues.HashCachePath)
+}
+
+// saveHashCache saves the hash cache, a failure is not fatal as the cache only saves time
+func saveHashCache(hashCache *commons.HashCache, prune bool) {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "saveHashCache",
+	})
+
+	if hashCache == nil {
+		return
+	}
+
+	if prune {
+		hashCache.Prune()
+	}
+
+	err := hashCache.Save()
+	if err != nil {
+		logger.WithError(err).Warnf("failed to save hash cache %s", hashCache.GetPath())
+	}
+}
+
 func makePutTargetDirPath(filesystem *irodsclient_fs.FileSystem, sourcePath string, targetPath string, noRoot bool) (string, error) {
 	cwd := commons.GetCWD()
 	home := commons.GetHomeDir()
@@ -406,3 +585,64 @@ func putDeleteExtraInternal(filesystem *irodsclient_fs.FileSystem, inputPathMap
 
 	return nil
 }
+
+// newFailureReport returns a failure report to continue on errors if it is enabled, returns nil otherwise
+func newFailureReport(continueOnErrorFlagValues *flag.ContinueOnErrorFlagValues) (*commons.FailureReport, error) {
+	if !continueOnErrorFlagValues.ContinueOnError {
+		if len(continueOnErrorFlagValues.FailedListPath) > 0 {
+			return nil, xerrors.Errorf("failed list requires continue on error flag")
+		}
+
+		return nil, nil
+	}
+
+	return commons.NewFailureReport(), nil
+}
+
+// checkFailureReport prints failures and writes failed paths to the list file, returns an error if anything failed
+func checkFailureReport(failureReport *commons.FailureReport, failedListPath string) error {
+	if failureReport == nil {
+		return nil
+	}
+
+	if len(failedListPath) > 0 {
+		err := failureReport.WriteFailedPaths(failedListPath)
+		if err != nil {
+			return xerrors.Errorf("failed to write failed list: %w", err)
+		}
+	}
+
+	if !failureReport.HasFailures() {
+		return nil
+	}
+
+	failureReport.PrintSummary(os.Stderr)
+	return xerrors.Errorf("failed to transfer %d file(s)", len(failureReport.GetFailures()))
+}
+
+// newPathFilter returns a filter of paths listed in the file if it is given, returns nil otherwise.
+// makePath makes listed paths absolute, local tells if they are local paths. It fails with deleteExtra since files not listed would be deleted.
+func newPathFilter(fromListFlagValues *flag.FromListFlagValues, deleteExtra bool, local bool, makePath func(p string) string) (*commons.PathFilter, error) {
+	if len(fromListFlagValues.FromListPath) == 0 {
+		return nil, nil
+	}
+
+	if deleteExtra {
+		return nil, xerrors.Errorf("from list flag cannot be used with delete flag")
+	}
+
+	paths, err := commons.ReadPathList(fromListFlagValues.FromListPath)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read path list: %w", err)
+	}
+
+	for idx, p := range paths {
+		paths[idx] = makePath(p)
+	}
+
+	if local {
+		return commons.NewLocalPathFilter(paths), nil
+	}
+
+	return commons.NewPathFilter(paths), nil
+}
@@ -0,0 +1,154 @@
+package subcmd
+
+import (
+	"fmt"
+
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var replCmd = &cobra.Command{
+	Use:     "repl [data-object1] [data-object2] [collection1] ...",
+	Aliases: []string{"irepl", "replicate"},
+	Short:   "Replicate iRODS data-objects to a resource",
+	Long:    `This replicates iRODS data-objects to the resource given with -R flag.`,
+	RunE:    processReplCommand,
+	Args:    cobra.MinimumNArgs(1),
+}
+
+func AddReplCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(replCmd)
+
+	flag.SetRecursiveFlags(replCmd)
+	flag.SetProgressFlags(replCmd)
+	flag.SetDryRunFlags(replCmd)
+
+	rootCmd.AddCommand(replCmd)
+}
+
+func processReplCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	recursiveFlagValues := flag.GetRecursiveFlagValues()
+	progressFlagValues := flag.GetProgressFlagValues()
+	dryRunFlagValues := flag.GetDryRunFlagValues()
+	commonFlagValues := flag.GetCommonFlagValues(command)
+
+	// the default resource in config is not used to not replicate to an unintended resource
+	if !commonFlagValues.ResourceUpdated || len(commonFlagValues.Resource) == 0 {
+		return xerrors.Errorf("target resource is not given, use -R flag")
+	}
+
+	targetResource := commonFlagValues.Resource
+
+	// Create a file system
+	account := commons.GetAccount()
+
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	parallelJobManager := commons.NewParallelJobManager(filesystem, commons.TransferTreadNumDefault, progressFlagValues.ShowProgress)
+	parallelJobManager.Start()
+
+	for _, sourcePath := range args {
+		err = replOne(parallelJobManager, sourcePath, targetResource, recursiveFlagValues.Recursive, dryRunFlagValues.DryRun)
+		if err != nil {
+			return xerrors.Errorf("failed to perform repl %s to %s: %w", sourcePath, targetResource, err)
+		}
+	}
+
+	parallelJobManager.DoneScheduling()
+	err = parallelJobManager.Wait()
+	if err != nil {
+		return xerrors.Errorf("failed to perform parallel job: %w", err)
+	}
+
+	return nil
+}
+
+func replOne(parallelJobManager *commons.ParallelJobManager, sourcePath string, resource string, recurse bool, dryRun bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "replOne",
+	})
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	sourcePath = commons.MakeIRODSPath(cwd, home, zone, sourcePath)
+
+	filesystem := parallelJobManager.GetFilesystem()
+
+	entries, err := getDataObjects(filesystem, sourcePath, recurse)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if hasGoodReplicaInResource(entry, resource) {
+			logger.Debugf("skip replicating a data object %s, a good replica already exists in %s", entry.Path, resource)
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("replicate %s to %s\n", entry.Path, resource)
+			continue
+		}
+
+		entryPath := entry.Path
+		replTask := func(job *commons.ParallelJob) error {
+			manager := job.GetManager()
+			fs := manager.GetFilesystem()
+
+			job.Progress(0, 1, false)
+
+			logger.Debugf("replicating a data object %s to %s", entryPath, resource)
+			err := fs.ReplicateFile(entryPath, resource, true)
+			if err != nil {
+				job.Progress(-1, 1, true)
+				return xerrors.Errorf("failed to replicate %s to %s: %w", entryPath, resource, err)
+			}
+
+			logger.Debugf("replicated a data object %s to %s", entryPath, resource)
+			job.Progress(1, 1, false)
+			return nil
+		}
+
+		parallelJobManager.Schedule(entryPath, replTask, 1, progress.UnitsDefault)
+		logger.Debugf("scheduled a data object replication %s to %s", entryPath, resource)
+	}
+
+	return nil
+}
+
+func hasGoodReplicaInResource(entry *irodsclient_types.IRODSDataObject, resource string) bool {
+	for _, replica := range entry.Replicas {
+		if commons.IsReplicaInResource(replica, resource) && replica.Status == "1" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_irodsfs "github.com/cyverse/go-irodsclient/irods/fs"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var statCmd = &cobra.Command{
+	Use:     "stat [data-object1] [collection1] ...",
+	Aliases: []string{"istat"},
+	Short:   "Display information of iRODS data-objects and collections",
+	Long:    `This displays complete information of iRODS data-objects and collections, including replicas. With --json flag, a JSON array is printed.`,
+	RunE:    processStatCommand,
+	Args:    cobra.MinimumNArgs(1),
+}
+
+func AddStatCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(statCmd)
+
+	flag.SetStatFlags(statCmd)
+	flag.SetListACLFlags(statCmd)
+
+	rootCmd.AddCommand(statCmd)
+}
+
+func processStatCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	statFlagValues := flag.GetStatFlagValues()
+	listACLFlagValues := flag.GetListACLFlagValues()
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	infos := []interface{}{}
+	for _, sourcePath := range args {
+		info, err := statOne(filesystem, sourcePath, listACLFlagValues.ShowACL, statFlagValues.ShowMeta)
+		if err != nil {
+			return xerrors.Errorf("failed to perform stat %s: %w", sourcePath, err)
+		}
+
+		if !statFlagValues.JSON {
+			printEntryInfo(info)
+			continue
+		}
+
+		infos = append(infos, info)
+	}
+
+	if statFlagValues.JSON {
+		jsonBytes, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			return xerrors.Errorf("failed to marshal to json: %w", err)
+		}
+
+		fmt.Printf("%s\n", string(jsonBytes))
+	}
+
+	return nil
+}
+
+// statOne returns *commons.DataObjectInfo or *commons.CollectionInfo
+func statOne(fs *irodsclient_fs.FileSystem, sourcePath string, showACL bool, showMeta bool) (interface{}, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "statOne",
+	})
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	sourcePath = commons.MakeIRODSPath(cwd, home, zone, sourcePath)
+
+	connection, err := fs.GetMetadataConnection()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnMetadataConnection(connection)
+
+	var accesses []*irodsclient_types.IRODSAccess
+	var metas []*irodsclient_types.IRODSMeta
+
+	collection, err := irodsclient_irodsfs.GetCollection(connection, sourcePath)
+	if err != nil {
+		if !irodsclient_types.IsFileNotFoundError(err) {
+			return nil, xerrors.Errorf("failed to get collection %s: %w", sourcePath, err)
+		}
+	}
+
+	if err == nil {
+		logger.Debugf("stat collection %s", sourcePath)
+
+		if showACL {
+			accesses, err = irodsclient_irodsfs.ListCollectionAccesses(connection, sourcePath)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to list accesses for collection %s: %w", sourcePath, err)
+			}
+		}
+
+		if showMeta {
+			metas, err = irodsclient_irodsfs.ListCollectionMeta(connection, sourcePath)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to list metadata for collection %s: %w", sourcePath, err)
+			}
+		}
+
+		return commons.NewCollectionInfo(collection, accesses, metas), nil
+	}
+
+	// data object
+	logger.Debugf("stat data-object %s", sourcePath)
+
+	parentSourcePath := path.Dir(sourcePath)
+
+	parentCollection, err := irodsclient_irodsfs.GetCollection(connection, parentSourcePath)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get collection %s: %w", parentSourcePath, err)
+	}
+
+	entry, err := irodsclient_irodsfs.GetDataObject(connection, parentCollection, path.Base(sourcePath))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get data-object %s: %w", sourcePath, err)
+	}
+
+	if showACL {
+		accesses, err = irodsclient_irodsfs.ListDataObjectAccesses(connection, parentCollection, path.Base(sourcePath))
+		if err != nil {
+			return nil, xerrors.Errorf("failed to list accesses for data-object %s: %w", sourcePath, err)
+		}
+	}
+
+	if showMeta {
+		metas, err = irodsclient_irodsfs.ListDataObjectMeta(connection, parentCollection, path.Base(sourcePath))
+		if err != nil {
+			return nil, xerrors.Errorf("failed to list metadata for data-object %s: %w", sourcePath, err)
+		}
+	}
+
+	return commons.NewDataObjectInfo(entry, accesses, metas), nil
+}
+
+func printEntryInfo(info interface{}) {
+	switch entryInfo := info.(type) {
+	case *commons.DataObjectInfo:
+		fmt.Printf("[%s]\n", entryInfo.Path)
+		fmt.Printf("  type: %s\n", entryInfo.Type)
+		fmt.Printf("  id: %d\n", entryInfo.ID)
+		fmt.Printf("  collection id: %d\n", entryInfo.CollectionID)
+		fmt.Printf("  name: %s\n", entryInfo.Name)
+		fmt.Printf("  size: %d\n", entryInfo.Size)
+		fmt.Printf("  data type: %s\n", entryInfo.DataType)
+		fmt.Printf("  owner: %s\n", entryInfo.Owner)
+		fmt.Printf("  create time: %s\n", commons.MakeDateTimeString(entryInfo.CreateTime))
+		fmt.Printf("  modify time: %s\n", commons.MakeDateTimeString(entryInfo.ModifyTime))
+		fmt.Printf("  replicas:\n")
+		for _, replica := range entryInfo.Replicas {
+			fmt.Printf("    - number: %d\n", replica.Number)
+			fmt.Printf("      owner: %s\n", replica.Owner)
+			fmt.Printf("      status: %s (%s)\n", replica.Status, getStatusMark(replica.Status))
+			fmt.Printf("      resource: %s\n", replica.ResourceName)
+			fmt.Printf("      resource hierarchy: %s\n", replica.ResourceHierarchy)
+			fmt.Printf("      physical path: %s\n", replica.PhysicalPath)
+			fmt.Printf("      checksum: %s\n", replica.Checksum)
+			fmt.Printf("      checksum algorithm: %s\n", replica.ChecksumAlgorithm)
+			fmt.Printf("      create time: %s\n", commons.MakeDateTimeString(replica.CreateTime))
+			fmt.Printf("      modify time: %s\n", commons.MakeDateTimeString(replica.ModifyTime))
+		}
+
+		printAccessInfos(entryInfo.ACL)
+		printMetaInfos(entryInfo.Metadata)
+	case *commons.CollectionInfo:
+		fmt.Printf("[%s]\n", entryInfo.Path)
+		fmt.Printf("  type: %s\n", entryInfo.Type)
+		fmt.Printf("  id: %d\n", entryInfo.ID)
+		fmt.Printf("  name: %s\n", entryInfo.Name)
+		fmt.Printf("  owner: %s\n", entryInfo.Owner)
+		fmt.Printf("  create time: %s\n", commons.MakeDateTimeString(entryInfo.CreateTime))
+		fmt.Printf("  modify time: %s\n", commons.MakeDateTimeString(entryInfo.ModifyTime))
+
+		printAccessInfos(entryInfo.ACL)
+		printMetaInfos(entryInfo.Metadata)
+	}
+}
+
+func printAccessInfos(accesses []*commons.AccessInfo) {
+	if accesses == nil {
+		return
+	}
+
+	fmt.Printf("  acl:\n")
+	for _, access := range accesses {
+		fmt.Printf("    - %s#%s:%s\n", access.UserName, access.UserZone, access.AccessLevel)
+	}
+}
+
+func printMetaInfos(metas []*commons.MetaInfo) {
+	if metas == nil {
+		return
+	}
+
+	fmt.Printf("  metadata:\n")
+	for _, meta := range metas {
+		fmt.Printf("    - id: %d\n", meta.ID)
+		fmt.Printf("      attribute: %s\n", meta.Attribute)
+		fmt.Printf("      value: %s\n", meta.Value)
+		fmt.Printf("      units: %s\n", meta.Units)
+	}
+}
@@ -34,6 +34,10 @@ func AddSyncCommand(rootCmd *cobra.Command) {
 	flag.SetDifferentialTransferFlags(syncCmd, false)
 	flag.SetNoRootFlags(syncCmd)
 	flag.SetSyncFlags(syncCmd)
+	flag.SetHashCacheFlags(syncCmd)
+	flag.SetLimitRateFlags(syncCmd)
+	flag.SetContinueOnErrorFlags(syncCmd)
+	flag.SetFromListFlags(syncCmd)
 
 	rootCmd.AddCommand(syncCmd)
 }
@@ -54,16 +58,6 @@ func processSyncCommand(command *cobra.Command, args []string) error {
 		return xerrors.Errorf("failed to input missing fields: %w", err)
 	}
 
-	retryFlagValues := flag.GetRetryFlagValues()
-
-	if retryFlagValues.RetryNumber > 0 && !retryFlagValues.RetryChild {
-		err = commons.RunWithRetry(retryFlagValues.RetryNumber, retryFlagValues.RetryIntervalSeconds)
-		if err != nil {
-			return xerrors.Errorf("failed to run with retry %d: %w", retryFlagValues.RetryNumber, err)
-		}
-		return nil
-	}
-
 	targetPath := args[len(args)-1]
 	sourcePaths := args[:len(args)-1]
 
@@ -140,17 +134,9 @@ func syncFromLocalToIRODS(command *cobra.Command) error {
 	newArgs = append(newArgs, "--diff")
 	newArgs = append(newArgs, osArgs[commandIdx+1:]...)
 
-	// filter out retry flag
-	newArgs2 := []string{}
-	for _, arg := range newArgs {
-		if arg != "--retry_child" {
-			newArgs2 = append(newArgs2, arg)
-		}
-	}
-
 	// run bput
-	logger.Debugf("run bput with args: %v", newArgs2)
-	bputCmd.ParseFlags(newArgs2)
+	logger.Debugf("run bput with args: %v", newArgs)
+	bputCmd.ParseFlags(newArgs)
 	argWoFlags := bputCmd.Flags().Args()
 	return bputCmd.RunE(bputCmd, argWoFlags)
 }
@@ -180,19 +166,12 @@ func syncFromIRODSToIRODS(command *cobra.Command, sourcePaths []string, targetPa
 
 	newArgs = append(newArgs, osArgs[:commandIdx]...)
 	newArgs = append(newArgs, "--diff")
-	newArgs = append(newArgs, osArgs[commandIdx+1:]...)
-
-	// filter out retry flag
-	newArgs2 := []string{}
-	for _, arg := range newArgs {
-		if arg != "--retry_child" {
-			newArgs2 = append(newArgs2, arg)
-		}
-	}
+	// data is copied in iRODS, cp does not limit the rate
+	newArgs = append(newArgs, removeFlagArgs(osArgs[commandIdx+1:], "limit_rate")...)
 
-	// run bput
-	logger.Debugf("run cp with args: %v", newArgs2)
-	cpCmd.ParseFlags(newArgs2)
+	// run cp
+	logger.Debugf("run cp with args: %v", newArgs)
+	cpCmd.ParseFlags(newArgs)
 	argWoFlags := cpCmd.Flags().Args()
 	return cpCmd.RunE(cpCmd, argWoFlags)
 }
@@ -224,17 +203,30 @@ func syncFromIRODSToLocal(command *cobra.Command, sourcePaths []string, targetPa
 	newArgs = append(newArgs, "--diff")
 	newArgs = append(newArgs, osArgs[commandIdx+1:]...)
 
-	// filter out retry flag
-	newArgs2 := []string{}
-	for _, arg := range newArgs {
-		if arg != "--retry_child" {
-			newArgs2 = append(newArgs2, arg)
-		}
-	}
-
 	// run bput
-	logger.Debugf("run get with args: %v", newArgs2)
-	getCmd.ParseFlags(newArgs2)
+	logger.Debugf("run get with args: %v", newArgs)
+	getCmd.ParseFlags(newArgs)
 	argWoFlags := getCmd.Flags().Args()
 	return getCmd.RunE(getCmd, argWoFlags)
 }
+
+// removeFlagArgs removes the flag given as "--name value" or "--name=value" from args
+func removeFlagArgs(args []string, name string) []string {
+	newArgs := []string{}
+	for argIdx := 0; argIdx < len(args); argIdx++ {
+		arg := args[argIdx]
+		if arg == "--"+name {
+			// skip the value too
+			argIdx++
+			continue
+		}
+
+		if strings.HasPrefix(arg, "--"+name+"=") {
+			continue
+		}
+
+		newArgs = append(newArgs, arg)
+	}
+
+	return newArgs
+}
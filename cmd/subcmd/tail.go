@@ -0,0 +1,107 @@
+package subcmd
+
+import (
+	"os"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var tailCmd = &cobra.Command{
+	Use:   "tail [data-object1] [data-object2] ...",
+	Short: "Display the last part of iRODS data-objects",
+	Long:  `This displays the last lines of iRODS data-objects, or the last bytes with --bytes flag. Only the last part is read from the server.`,
+	RunE:  processTailCommand,
+	Args:  cobra.MinimumNArgs(1),
+}
+
+func AddTailCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(tailCmd)
+
+	flag.SetHeadTailFlags(tailCmd)
+
+	rootCmd.AddCommand(tailCmd)
+}
+
+func processTailCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	headTailFlagValues := flag.GetHeadTailFlagValues()
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	for idx, sourcePath := range args {
+		if len(args) > 1 {
+			printHeadTailHeader(sourcePath, idx == 0)
+		}
+
+		err = tailOne(filesystem, sourcePath, headTailFlagValues.Bytes, headTailFlagValues.Lines)
+		if err != nil {
+			return xerrors.Errorf("failed to perform tail %s: %w", sourcePath, err)
+		}
+	}
+	return nil
+}
+
+func tailOne(filesystem *irodsclient_fs.FileSystem, targetPath string, bytes int64, lines int64) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "tailOne",
+	})
+
+	fh, targetEntry, err := openDataObjectForRead(filesystem, targetPath)
+	if err != nil {
+		return err
+	}
+
+	defer fh.Close()
+
+	offset := int64(0)
+	if bytes >= 0 {
+		logger.Debugf("showing the last %d bytes of a data object %s", bytes, targetEntry.Path)
+
+		offset = targetEntry.Size - bytes
+		if offset < 0 {
+			offset = 0
+		}
+	} else {
+		logger.Debugf("showing the last %d lines of a data object %s", lines, targetEntry.Path)
+
+		offset, err = commons.GetTailLinesOffset(fh, targetEntry.Size, lines)
+		if err != nil {
+			return xerrors.Errorf("failed to find the last %d lines of %s: %w", lines, targetEntry.Path, err)
+		}
+	}
+
+	_, err = commons.CopyRange(os.Stdout, fh, offset, -1)
+	if err != nil {
+		return xerrors.Errorf("failed to read %s: %w", targetEntry.Path, err)
+	}
+
+	return nil
+}
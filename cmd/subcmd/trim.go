@@ -0,0 +1,160 @@
+package subcmd
+
+import (
+	"fmt"
+
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/cyverse/gocommands/cmd/flag"
+	"github.com/cyverse/gocommands/commons"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+var trimCmd = &cobra.Command{
+	Use:     "trim [data-object1] [data-object2] [collection1] ...",
+	Aliases: []string{"itrim"},
+	Short:   "Trim replicas of iRODS data-objects",
+	Long:    `This trims replicas of iRODS data-objects on the resource given with -S flag, or down to the number of replicas given with -N flag.`,
+	RunE:    processTrimCommand,
+	Args:    cobra.MinimumNArgs(1),
+}
+
+func AddTrimCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(trimCmd)
+
+	flag.SetRecursiveFlags(trimCmd)
+	flag.SetProgressFlags(trimCmd)
+	flag.SetDryRunFlags(trimCmd)
+	flag.SetReplicaSourceFlags(trimCmd)
+	flag.SetTrimFlags(trimCmd)
+
+	rootCmd.AddCommand(trimCmd)
+}
+
+func processTrimCommand(command *cobra.Command, args []string) error {
+	cont, err := flag.ProcessCommonFlags(command)
+	if err != nil {
+		return xerrors.Errorf("failed to process common flags: %w", err)
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// handle local flags
+	_, err = commons.InputMissingFields()
+	if err != nil {
+		return xerrors.Errorf("failed to input missing fields: %w", err)
+	}
+
+	recursiveFlagValues := flag.GetRecursiveFlagValues()
+	progressFlagValues := flag.GetProgressFlagValues()
+	dryRunFlagValues := flag.GetDryRunFlagValues()
+	replicaSourceFlagValues := flag.GetReplicaSourceFlagValues()
+	trimFlagValues := flag.GetTrimFlagValues()
+
+	if len(replicaSourceFlagValues.SourceResource) == 0 && trimFlagValues.MinCopies <= 0 {
+		return xerrors.Errorf("either source resource (-S) or number of replicas to keep (-N) must be given")
+	}
+
+	// Create a file system
+	account := commons.GetAccount()
+	filesystem, err := commons.GetIRODSFSClient(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get iRODS FS Client: %w", err)
+	}
+
+	defer filesystem.Release()
+
+	parallelJobManager := commons.NewParallelJobManager(filesystem, commons.TransferTreadNumDefault, progressFlagValues.ShowProgress)
+	parallelJobManager.Start()
+
+	for _, sourcePath := range args {
+		err = trimOne(parallelJobManager, sourcePath, replicaSourceFlagValues.SourceResource, trimFlagValues.MinCopies, recursiveFlagValues.Recursive, dryRunFlagValues.DryRun)
+		if err != nil {
+			return xerrors.Errorf("failed to perform trim %s: %w", sourcePath, err)
+		}
+	}
+
+	parallelJobManager.DoneScheduling()
+	err = parallelJobManager.Wait()
+	if err != nil {
+		return xerrors.Errorf("failed to perform parallel job: %w", err)
+	}
+
+	return nil
+}
+
+func trimOne(parallelJobManager *commons.ParallelJobManager, sourcePath string, resource string, minCopies int, recurse bool, dryRun bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "main",
+		"function": "trimOne",
+	})
+
+	cwd := commons.GetCWD()
+	home := commons.GetHomeDir()
+	zone := commons.GetZone()
+	sourcePath = commons.MakeIRODSPath(cwd, home, zone, sourcePath)
+
+	filesystem := parallelJobManager.GetFilesystem()
+
+	entries, err := getDataObjects(filesystem, sourcePath, recurse)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !needTrim(entry, resource, minCopies) {
+			logger.Debugf("skip trimming a data object %s, nothing to trim", entry.Path)
+			continue
+		}
+
+		if dryRun {
+			if len(resource) > 0 {
+				fmt.Printf("trim replicas of %s in %s\n", entry.Path, resource)
+			} else {
+				fmt.Printf("trim replicas of %s to keep %d replicas\n", entry.Path, minCopies)
+			}
+			continue
+		}
+
+		entryPath := entry.Path
+		trimTask := func(job *commons.ParallelJob) error {
+			manager := job.GetManager()
+			fs := manager.GetFilesystem()
+
+			job.Progress(0, 1, false)
+
+			logger.Debugf("trimming replicas of a data object %s", entryPath)
+			err := commons.TrimReplicas(fs, entryPath, resource, minCopies)
+			if err != nil {
+				job.Progress(-1, 1, true)
+				return xerrors.Errorf("failed to trim %s: %w", entryPath, err)
+			}
+
+			logger.Debugf("trimmed replicas of a data object %s", entryPath)
+			job.Progress(1, 1, false)
+			return nil
+		}
+
+		parallelJobManager.Schedule(entryPath, trimTask, 1, progress.UnitsDefault)
+		logger.Debugf("scheduled trimming replicas of a data object %s", entryPath)
+	}
+
+	return nil
+}
+
+func needTrim(entry *irodsclient_types.IRODSDataObject, resource string, minCopies int) bool {
+	if minCopies > 0 && len(entry.Replicas) <= minCopies {
+		return false
+	}
+
+	if len(resource) == 0 {
+		return true
+	}
+
+	return hasReplicaInResource(entry, resource)
+}
@@ -0,0 +1,85 @@
+package commons
+
+import (
+	"fmt"
+	"strings"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_message "github.com/cyverse/go-irodsclient/irods/message"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"golang.org/x/xerrors"
+)
+
+// ParseAccessLevel parses access level string given by users
+func ParseAccessLevel(accessLevel string) (irodsclient_types.IRODSAccessLevelType, error) {
+	switch strings.ToLower(accessLevel) {
+	case "read", "r", "read object":
+		return irodsclient_types.IRODSAccessLevelRead, nil
+	case "write", "w", "modify", "modify object":
+		return irodsclient_types.IRODSAccessLevelWrite, nil
+	case "own", "owner":
+		return irodsclient_types.IRODSAccessLevelOwner, nil
+	case "null", "none", "remove":
+		return irodsclient_types.IRODSAccessLevelNone, nil
+	default:
+		return irodsclient_types.IRODSAccessLevelNone, xerrors.Errorf("unknown access level %s", accessLevel)
+	}
+}
+
+// GetAccessString returns a string representation of the access, "user#zone:level"
+func GetAccessString(access *irodsclient_types.IRODSAccess) string {
+	return fmt.Sprintf("%s#%s:%s", access.UserName, access.UserZone, access.AccessLevel)
+}
+
+// ChangeAccess changes access control of a data object or collection for the user or group
+func ChangeAccess(fs *irodsclient_fs.FileSystem, path string, access irodsclient_types.IRODSAccessLevelType, user string, zone string, recursive bool) error {
+	request := irodsclient_message.NewIRODSMessageModifyAccessRequest(access.ChmodString(), user, zone, path, recursive, false)
+	err := requestModifyAccess(fs, request, recursive)
+	if err != nil {
+		return xerrors.Errorf("failed to change access of %s for %s: %w", path, user, err)
+	}
+
+	return nil
+}
+
+// ChangeAccessInherit sets or clears the inherit flag of a collection
+func ChangeAccessInherit(fs *irodsclient_fs.FileSystem, path string, inherit bool, recursive bool) error {
+	inheritStr := "inherit"
+	if !inherit {
+		inheritStr = "noinherit"
+	}
+
+	request := irodsclient_message.NewIRODSMessageModifyAccessRequest(inheritStr, "", "", path, recursive, false)
+	err := requestModifyAccess(fs, request, recursive)
+	if err != nil {
+		return xerrors.Errorf("failed to change access inheritance of %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func requestModifyAccess(fs *irodsclient_fs.FileSystem, request *irodsclient_message.IRODSMessageModifyAccessRequest, recursive bool) error {
+	// go-irodsclient does not set the recursive flag in the request
+	if recursive {
+		request.RecursiveFlag = 1
+	}
+
+	connection, err := fs.GetMetadataConnection()
+	if err != nil {
+		return xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnMetadataConnection(connection)
+
+	connection.Lock()
+	defer connection.Unlock()
+
+	response := irodsclient_message.IRODSMessageModifyAccessResponse{}
+	err = connection.RequestAndCheck(request, &response, nil)
+	if err != nil {
+		return err
+	}
+
+	// access info is cached in the file system
+	fs.ClearCache()
+	return nil
+}
@@ -0,0 +1,681 @@
+package commons
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/xerrors"
+)
+
+// bundleScheduleRequest is a source waiting for comparison with its target
+type bundleScheduleRequest struct {
+	source string
+	dir    bool
+	size   int64
+	// checksumAlgorithm and checksum are only for iRODS sources, checksum is a hex string
+	checksumAlgorithm string
+	checksum          string
+}
+
+type BundleEntry struct {
+	LocalPath string
+	IRODSPath string
+	Size      int64
+	Dir       bool
+	// Hashes are hashes of the local file computed while bundling or uploading, a map of hash algorithm to hash in hex string
+	Hashes map[string]string
+}
+
+// Bundle is a group of files or data-objects transferred together as a bundle file
+type Bundle struct {
+	scheduler *bundleScheduler
+
+	index             int64
+	entries           []*BundleEntry
+	size              int64
+	fileNum           int
+	localBundlePath   string
+	irodsBundlePath   string
+	irodsStagingPath  string
+	lastError         error
+	lastErrorTaskName string
+}
+
+func newBundle(scheduler *bundleScheduler) (*Bundle, error) {
+	bundle := &Bundle{
+		scheduler:         scheduler,
+		index:             scheduler.getNextBundleIndex(),
+		entries:           []*BundleEntry{},
+		size:              0,
+		fileNum:           0,
+		localBundlePath:   "",
+		irodsBundlePath:   "",
+		irodsStagingPath:  "",
+		lastError:         nil,
+		lastErrorTaskName: "",
+	}
+
+	err := bundle.updateBundlePath()
+	if err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+func (bundle *Bundle) GetEntries() []*BundleEntry {
+	return bundle.entries
+}
+
+func (bundle *Bundle) GetBundleFilename() (string, error) {
+	entryStrs := []string{}
+
+	entryStrs = append(entryStrs, "empty_bundle")
+
+	for _, entry := range bundle.entries {
+		entryStrs = append(entryStrs, entry.LocalPath)
+	}
+
+	hash, err := HashStrings(entryStrs, string(irodsclient_types.ChecksumAlgorithmMD5))
+	if err != nil {
+		return "", err
+	}
+
+	return GetBundleFilenameForFormat(hash, bundle.scheduler.bundleFormat), nil
+}
+
+func (bundle *Bundle) AddFile(source string, size int64) error {
+	e, err := bundle.scheduler.newEntry(source, false, size)
+	if err != nil {
+		return err
+	}
+
+	bundle.entries = append(bundle.entries, e)
+	bundle.size += size
+	bundle.fileNum++
+
+	err = bundle.updateBundlePath()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (bundle *Bundle) AddDir(source string) error {
+	e, err := bundle.scheduler.newEntry(source, true, 0)
+	if err != nil {
+		return err
+	}
+
+	bundle.entries = append(bundle.entries, e)
+
+	err = bundle.updateBundlePath()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (bundle *Bundle) updateBundlePath() error {
+	filename, err := bundle.GetBundleFilename()
+	if err != nil {
+		return xerrors.Errorf("failed to get bundle filename: %w", err)
+	}
+
+	bundle.localBundlePath = filepath.Join(bundle.scheduler.localTempDirPath, filename)
+	bundle.irodsBundlePath = path.Join(bundle.scheduler.irodsTempDirPath, filename)
+	bundle.irodsStagingPath = path.Join(bundle.scheduler.irodsTempDirPath, strings.TrimSuffix(filename, GetBundleFileExtension(bundle.scheduler.bundleFormat)))
+	return nil
+}
+
+func (bundle *Bundle) isFull() bool {
+	return bundle.size >= bundle.scheduler.maxBundleFileSize || len(bundle.entries) >= bundle.scheduler.maxBundleFileNum
+}
+
+func (bundle *Bundle) requireTar() bool {
+	return len(bundle.entries) >= MinBundleFileNumDefault
+}
+
+// requireStaging returns true if the bundle has enough data-objects to copy them to the staging collection and tar them in iRODS, dirs are made locally
+func (bundle *Bundle) requireStaging() bool {
+	return bundle.fileNum >= MinBundleFileNumDefault
+}
+
+// bundleScheduler groups scheduled sources into bundles for BundleTransferManager and BundleDownloadManager.
+// It runs comparisons for --diff, tracks progress and records errors of bundle tasks, managers run their own pipelines of bundle tasks.
+type bundleScheduler struct {
+	filesystem              *irodsclient_fs.FileSystem
+	downloading             bool
+	currentBundle           *Bundle
+	nextBundleIndex         int64
+	pendingBundles          chan *Bundle
+	bundles                 []*Bundle
+	bundleRootPath          string
+	maxBundleFileNum        int
+	maxBundleFileSize       int64
+	bundleFormat            string
+	compareThreadNum        int
+	compareRequests         chan *bundleScheduleRequest
+	localTempDirPath        string
+	irodsTempDirPath        string
+	differentFilesOnly      bool
+	noHashForComparison     bool
+	hashCache               *HashCache
+	retryPolicy             *RetryPolicy
+	failureReport           *FailureReport
+	rateLimiter             *RateLimiter
+	showProgress            bool
+	progressWriter          progress.Writer
+	progressTrackers        map[string]*progress.Tracker
+	progressTrackerCallback ProgressTrackerCallback
+	lastError               error
+	mutex                   sync.RWMutex
+
+	// getTargetPathFunc returns the target path of the source
+	getTargetPathFunc func(source string) (string, error)
+	// isDifferentFunc checks if the source of the request is different from its target
+	isDifferentFunc func(request *bundleScheduleRequest) (bool, error)
+
+	scheduleWait sync.WaitGroup
+	compareWait  sync.WaitGroup
+	transferWait sync.WaitGroup
+}
+
+// newBundleScheduler creates a new bundleScheduler, downloading is true if sources are data-objects in iRODS
+func newBundleScheduler(fs *irodsclient_fs.FileSystem, downloading bool, maxBundleFileNum int, maxBundleFileSize int64, localTempDirPath string, irodsTempDirPath string, diff bool, noHash bool, showProgress bool) bundleScheduler {
+	return bundleScheduler{
+		filesystem:              fs,
+		downloading:             downloading,
+		currentBundle:           nil,
+		nextBundleIndex:         0,
+		pendingBundles:          make(chan *Bundle, 100),
+		bundles:                 []*Bundle{},
+		bundleRootPath:          "/",
+		maxBundleFileNum:        maxBundleFileNum,
+		maxBundleFileSize:       maxBundleFileSize,
+		bundleFormat:            BundleFormatTar,
+		compareThreadNum:        CompareThreadNumDefault,
+		compareRequests:         make(chan *bundleScheduleRequest, 100),
+		localTempDirPath:        localTempDirPath,
+		irodsTempDirPath:        irodsTempDirPath,
+		differentFilesOnly:      diff,
+		noHashForComparison:     noHash,
+		hashCache:               nil,
+		retryPolicy:             nil,
+		failureReport:           nil,
+		rateLimiter:             nil,
+		showProgress:            showProgress,
+		progressWriter:          nil,
+		progressTrackers:        map[string]*progress.Tracker{},
+		progressTrackerCallback: nil,
+		lastError:               nil,
+		mutex:                   sync.RWMutex{},
+		getTargetPathFunc:       nil,
+		isDifferentFunc:         nil,
+		scheduleWait:            sync.WaitGroup{},
+		compareWait:             sync.WaitGroup{},
+		transferWait:            sync.WaitGroup{},
+	}
+}
+
+func (scheduler *bundleScheduler) GetFilesystem() *irodsclient_fs.FileSystem {
+	return scheduler.filesystem
+}
+
+func (scheduler *bundleScheduler) getNextBundleIndex() int64 {
+	idx := scheduler.nextBundleIndex
+	scheduler.nextBundleIndex++
+	return idx
+}
+
+// newEntry creates a bundle entry of the source and its target
+func (scheduler *bundleScheduler) newEntry(source string, dir bool, size int64) (*BundleEntry, error) {
+	targetPath, err := scheduler.getTargetPathFunc(source)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get target path for %s: %w", source, err)
+	}
+
+	entry := &BundleEntry{
+		LocalPath: source,
+		IRODSPath: targetPath,
+		Size:      size,
+		Dir:       dir,
+	}
+
+	if scheduler.downloading {
+		entry.LocalPath = targetPath
+		entry.IRODSPath = source
+	}
+
+	return entry, nil
+}
+
+// getSourcePath returns the source path of the entry
+func (scheduler *bundleScheduler) getSourcePath(entry *BundleEntry) string {
+	if scheduler.downloading {
+		return entry.IRODSPath
+	}
+
+	return entry.LocalPath
+}
+
+func (scheduler *bundleScheduler) progress(name string, processed int64, total int64, progressUnit progress.Units, errored bool) {
+	if scheduler.progressTrackerCallback != nil {
+		scheduler.progressTrackerCallback(name, processed, total, progressUnit, errored)
+	}
+}
+
+func (scheduler *bundleScheduler) getProgressName(bundle *Bundle, taskName string) string {
+	return fmt.Sprintf("bundle %d - %s", bundle.index, taskName)
+}
+
+// schedule queues the request for comparison with --diff, or adds its source to a bundle
+func (scheduler *bundleScheduler) schedule(request *bundleScheduleRequest) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "bundleScheduler",
+		"function": "schedule",
+	})
+
+	scheduler.mutex.RLock()
+
+	// do not accept new schedule if there's an error
+	if scheduler.lastError != nil {
+		defer scheduler.mutex.RUnlock()
+		return scheduler.lastError
+	}
+
+	scheduler.mutex.RUnlock()
+
+	if scheduler.differentFilesOnly {
+		// compare workers add it to a bundle if it is different
+		// this blocks if workers are all busy
+		logger.Debugf("queueing %s for comparison", request.source)
+		scheduler.compareRequests <- request
+		return nil
+	}
+
+	return scheduler.addToBundle(request.source, request.dir, request.size)
+}
+
+// addToBundle adds the source to the current bundle, the current bundle is sent to the pipeline if it is full.
+// For downloads, data-objects too large to bundle are sent in their own bundles to be downloaded directly.
+func (scheduler *bundleScheduler) addToBundle(source string, dir bool, size int64) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "bundleScheduler",
+		"function": "addToBundle",
+	})
+
+	scheduler.mutex.Lock()
+
+	if scheduler.downloading && !dir && size >= scheduler.maxBundleFileSize {
+		bundle, err := newBundle(scheduler)
+		if err != nil {
+			scheduler.mutex.Unlock()
+			return xerrors.Errorf("failed to create a new bundle for %s: %w", source, err)
+		}
+
+		err = bundle.AddFile(source, size)
+		if err != nil {
+			scheduler.mutex.Unlock()
+			return xerrors.Errorf("failed to add %s to bundle: %w", source, err)
+		}
+
+		scheduler.bundles = append(scheduler.bundles, bundle)
+		scheduler.transferWait.Add(1)
+		scheduler.mutex.Unlock()
+
+		// send outside of lock since adding to chan may block
+		scheduler.pendingBundles <- bundle
+
+		logger.Debugf("> scheduled %s in bundle %d", source, bundle.index)
+		return nil
+	}
+
+	// if current bundle is full, prepare a new bundle
+	var fullBundle *Bundle
+	if scheduler.currentBundle != nil && scheduler.currentBundle.isFull() {
+		fullBundle = scheduler.currentBundle
+		scheduler.bundles = append(scheduler.bundles, fullBundle)
+		scheduler.currentBundle = nil
+		scheduler.transferWait.Add(1)
+	}
+
+	if scheduler.currentBundle == nil {
+		// add new
+		bundle, err := newBundle(scheduler)
+		if err != nil {
+			scheduler.mutex.Unlock()
+			return xerrors.Errorf("failed to create a new bundle for %s: %w", source, err)
+		}
+
+		scheduler.currentBundle = bundle
+		logger.Debugf("assigned a new bundle %d", scheduler.currentBundle.index)
+	}
+
+	var err error
+	if dir {
+		err = scheduler.currentBundle.AddDir(source)
+	} else {
+		err = scheduler.currentBundle.AddFile(source, size)
+	}
+
+	scheduler.mutex.Unlock()
+
+	// send outside of lock since adding to chan may block
+	if fullBundle != nil {
+		scheduler.pendingBundles <- fullBundle
+	}
+
+	if err != nil {
+		return xerrors.Errorf("failed to add %s to bundle: %w", source, err)
+	}
+
+	logger.Debugf("> scheduled %s in a bundle", source)
+	return nil
+}
+
+// startCompare starts workers that compare queued sources with their targets and add different ones to bundles
+func (scheduler *bundleScheduler) startCompare() {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "bundleScheduler",
+		"function": "startCompare",
+	})
+
+	funcCompare := func(id int) {
+		logger.Debugf("start compare thread %d", id)
+		defer logger.Debugf("exit compare thread %d", id)
+
+		defer scheduler.compareWait.Done()
+
+		for request := range scheduler.compareRequests {
+			cont := true
+
+			scheduler.mutex.RLock()
+			if scheduler.lastError != nil {
+				cont = false
+			}
+			scheduler.mutex.RUnlock()
+
+			if !cont {
+				// drain requests
+				continue
+			}
+
+			different := false
+			err := scheduler.retryPolicy.Run(fmt.Sprintf("comparison of %s", request.source), func() error {
+				var compareErr error
+				different, compareErr = scheduler.isDifferentFunc(request)
+				return compareErr
+			})
+			if err == nil && different {
+				err = scheduler.addToBundle(request.source, request.dir, request.size)
+			}
+
+			if err != nil {
+				if scheduler.failureReport != nil {
+					scheduler.failureReport.Add(request.source, FailurePhaseCompare, err)
+				} else {
+					// mark error
+					scheduler.mutex.Lock()
+					scheduler.lastError = err
+					scheduler.mutex.Unlock()
+				}
+
+				logger.Error(err)
+				// don't stop here
+			}
+		}
+	}
+
+	for i := 0; i < scheduler.compareThreadNum; i++ {
+		scheduler.compareWait.Add(1)
+		go funcCompare(i)
+	}
+}
+
+func (scheduler *bundleScheduler) DoneScheduling() {
+	// wait for comparisons in progress
+	close(scheduler.compareRequests)
+	scheduler.compareWait.Wait()
+
+	scheduler.mutex.Lock()
+	if scheduler.currentBundle != nil {
+		scheduler.pendingBundles <- scheduler.currentBundle
+		scheduler.bundles = append(scheduler.bundles, scheduler.currentBundle)
+		scheduler.currentBundle = nil
+		scheduler.transferWait.Add(1)
+	}
+	scheduler.mutex.Unlock()
+
+	close(scheduler.pendingBundles)
+	scheduler.scheduleWait.Done()
+}
+
+func (scheduler *bundleScheduler) GetBundles() []*Bundle {
+	return scheduler.bundles
+}
+
+// waitTransfers waits until all bundles are scheduled and processed
+func (scheduler *bundleScheduler) waitTransfers() {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "bundleScheduler",
+		"function": "waitTransfers",
+	})
+
+	logger.Debug("waiting schedule-wait")
+	scheduler.scheduleWait.Wait()
+	logger.Debug("waiting transfer-wait")
+	scheduler.transferWait.Wait()
+}
+
+func (scheduler *bundleScheduler) SetBundleRootPath(bundleRootPath string) {
+	scheduler.bundleRootPath = bundleRootPath
+}
+
+// SetCompareThreadNum sets the number of threads comparing sources with targets, must be called before Start
+func (scheduler *bundleScheduler) SetCompareThreadNum(compareThreadNum int) {
+	if compareThreadNum < 1 {
+		compareThreadNum = 1
+	}
+
+	scheduler.compareThreadNum = compareThreadNum
+}
+
+// SetHashCache makes the manager use the hash cache to compare local files with data objects, nil disables the cache
+func (scheduler *bundleScheduler) SetHashCache(hashCache *HashCache) {
+	scheduler.hashCache = hashCache
+}
+
+// SetRetryPolicy makes the manager retry comparisons and bundle tasks failed with transient errors, nil disables retrying
+func (scheduler *bundleScheduler) SetRetryPolicy(retryPolicy *RetryPolicy) {
+	scheduler.retryPolicy = retryPolicy
+}
+
+// SetFailureReport makes the manager record files failed to the report and continue with other bundles, nil stops on the first failure
+func (scheduler *bundleScheduler) SetFailureReport(failureReport *FailureReport) {
+	scheduler.failureReport = failureReport
+}
+
+// GetFailureReport returns the failure report, nil if the manager stops on the first failure
+func (scheduler *bundleScheduler) GetFailureReport() *FailureReport {
+	return scheduler.failureReport
+}
+
+// SetRateLimiter sets the limiter shared by all bundle transfers to limit transfer bandwidth, nil for no limit
+func (scheduler *bundleScheduler) SetRateLimiter(rateLimiter *RateLimiter) {
+	scheduler.rateLimiter = rateLimiter
+}
+
+// GetRateLimiter returns the rate limiter, nil for no limit
+func (scheduler *bundleScheduler) GetRateLimiter() *RateLimiter {
+	return scheduler.rateLimiter
+}
+
+// canProcessBundle checks if the bundle has entries and no error occurred before
+func (scheduler *bundleScheduler) canProcessBundle(bundle *Bundle) bool {
+	scheduler.mutex.RLock()
+	defer scheduler.mutex.RUnlock()
+
+	return scheduler.lastError == nil && bundle.lastError == nil && len(bundle.entries) > 0
+}
+
+// bundleEntryError is an error of a bundle task failed at an entry, entries after it are not processed
+type bundleEntryError struct {
+	entryIdx int
+	err      error
+}
+
+func newBundleEntryError(entryIdx int, err error) error {
+	return &bundleEntryError{
+		entryIdx: entryIdx,
+		err:      err,
+	}
+}
+
+// Error returns error message
+func (err *bundleEntryError) Error() string {
+	return err.err.Error()
+}
+
+// Unwrap returns the error of the entry
+func (err *bundleEntryError) Unwrap() error {
+	return err.err
+}
+
+// markBundleError records the error of the bundle task, the transfer stops unless the failure report is set.
+// Files failed or not processed are recorded to the failure report, dirs are not.
+func (scheduler *bundleScheduler) markBundleError(bundle *Bundle, taskName string, err error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "bundleScheduler",
+		"function": "markBundleError",
+	})
+
+	scheduler.mutex.Lock()
+	if scheduler.failureReport == nil {
+		scheduler.lastError = err
+	}
+
+	bundle.lastError = err
+	bundle.lastErrorTaskName = taskName
+	scheduler.mutex.Unlock()
+
+	if scheduler.failureReport != nil {
+		// files before the failed entry are done if the task transfers files one by one
+		failedEntryIdx := 0
+
+		var entryErr *bundleEntryError
+		if errors.As(err, &entryErr) {
+			failedEntryIdx = entryErr.entryIdx
+		}
+
+		for _, entry := range bundle.entries[failedEntryIdx:] {
+			if entry.Dir {
+				continue
+			}
+
+			scheduler.failureReport.Add(scheduler.getSourcePath(entry), taskName, err)
+		}
+	}
+
+	logger.Error(err)
+}
+
+// runBundleTask runs the task for the bundle with retries unless an error occurred before, the error is recorded with markBundleError
+func (scheduler *bundleScheduler) runBundleTask(bundle *Bundle, taskName string, task func(bundle *Bundle) error) {
+	if !scheduler.canProcessBundle(bundle) {
+		return
+	}
+
+	err := scheduler.retryPolicy.Run(scheduler.getProgressName(bundle, taskName), func() error {
+		return task(bundle)
+	})
+	if err != nil {
+		scheduler.markBundleError(bundle, taskName, err)
+		// don't stop here
+	}
+}
+
+func (scheduler *bundleScheduler) startProgress() {
+	if scheduler.showProgress {
+		scheduler.progressWriter = GetProgressWriter(false)
+		messageWidth := getProgressMessageWidth(false)
+
+		go scheduler.progressWriter.Render()
+
+		// add progress tracker callback
+		scheduler.progressTrackerCallback = func(name string, processed int64, total int64, progressUnit progress.Units, errored bool) {
+			scheduler.mutex.Lock()
+			defer scheduler.mutex.Unlock()
+
+			var tracker *progress.Tracker
+			if t, ok := scheduler.progressTrackers[name]; !ok {
+				// created a new tracker if not exists
+				msg := GetShortPathMessage(name, messageWidth)
+
+				tracker = &progress.Tracker{
+					Message: msg,
+					Total:   total,
+					Units:   progressUnit,
+				}
+
+				scheduler.progressWriter.AppendTracker(tracker)
+				scheduler.progressTrackers[name] = tracker
+			} else {
+				tracker = t
+
+				if tracker.IsErrored() && !errored {
+					// retried
+					tracker.Reset()
+					tracker.Total = total
+				}
+			}
+
+			if processed >= 0 {
+				tracker.SetValue(processed)
+			}
+
+			if errored {
+				tracker.MarkAsErrored()
+			} else if processed >= total {
+				tracker.MarkAsDone()
+			}
+		}
+	}
+}
+
+func (scheduler *bundleScheduler) endProgress() {
+	if scheduler.showProgress {
+		if scheduler.progressWriter != nil {
+			scheduler.mutex.Lock()
+
+			for _, tracker := range scheduler.progressTrackers {
+				if !tracker.IsDone() {
+					if scheduler.lastError != nil {
+						tracker.MarkAsErrored()
+					} else {
+						tracker.MarkAsDone()
+					}
+				}
+			}
+
+			scheduler.mutex.Unlock()
+
+			scheduler.progressWriter.Stop()
+		}
+	}
+}
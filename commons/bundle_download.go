@@ -0,0 +1,628 @@
+package commons
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/xerrors"
+)
+
+const (
+	BundleStageThreadNumDefault int = 3
+)
+
+const (
+	BundleTaskNameStage    string = "Staging"
+	BundleTaskNameDownload string = "Downloading"
+)
+
+// BundleDownloadManager downloads data-objects in bundles.
+// Small data-objects are copied to a staging collection and bundled into a TAR file in iRODS, then the TAR file is downloaded and extracted locally.
+type BundleDownloadManager struct {
+	bundleScheduler
+
+	localDestPath     string
+	singleThreaded    bool
+	downloadThreadNum int
+}
+
+// NewBundleDownloadManager creates a new BundleDownloadManager
+func NewBundleDownloadManager(fs *irodsclient_fs.FileSystem, localDestPath string, maxBundleFileNum int, maxBundleFileSize int64, singleThreaded bool, downloadThreadNum int, localTempDirPath string, irodsTempDirPath string, diff bool, noHash bool, showProgress bool) *BundleDownloadManager {
+	manager := &BundleDownloadManager{
+		bundleScheduler:   newBundleScheduler(fs, true, maxBundleFileNum, maxBundleFileSize, localTempDirPath, irodsTempDirPath, diff, noHash, showProgress),
+		localDestPath:     localDestPath,
+		singleThreaded:    singleThreaded,
+		downloadThreadNum: downloadThreadNum,
+	}
+
+	manager.getTargetPathFunc = manager.getTargetPath
+	manager.isDifferentFunc = manager.isDifferent
+
+	if manager.downloadThreadNum > UploadTreadNumMax {
+		manager.downloadThreadNum = UploadTreadNumMax
+	}
+
+	manager.scheduleWait.Add(1)
+
+	return manager
+}
+
+// getRelPath returns a path of the data-object relative to the bundle root path, separated by "/"
+func (manager *BundleDownloadManager) getRelPath(irodsPath string) (string, error) {
+	relPath := getDiffRelPath(manager.bundleRootPath, irodsPath)
+	if len(relPath) == 0 {
+		return "", xerrors.Errorf("failed to compute relative path %s to %s", irodsPath, manager.bundleRootPath)
+	}
+
+	return relPath, nil
+}
+
+func (manager *BundleDownloadManager) getTargetPath(irodsPath string) (string, error) {
+	relPath, err := manager.getRelPath(irodsPath)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(manager.localDestPath, filepath.FromSlash(relPath)), nil
+}
+
+// Schedule schedules a data-object or a collection to download. checksumAlgorithm and checksum are used for --diff.
+func (manager *BundleDownloadManager) Schedule(source string, dir bool, size int64, checksumAlgorithm string, checksum string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "BundleDownloadManager",
+		"function": "Schedule",
+	})
+
+	// the staging collection may be under the source collection
+	if source == manager.irodsTempDirPath || strings.HasPrefix(source, manager.irodsTempDirPath+"/") {
+		logger.Debugf("skip staging path %s", source)
+		return nil
+	}
+
+	return manager.schedule(&bundleScheduleRequest{
+		source:            source,
+		dir:               dir,
+		size:              size,
+		checksumAlgorithm: checksumAlgorithm,
+		checksum:          checksum,
+	})
+}
+
+// isDifferent checks if the source is different from its local target
+func (manager *BundleDownloadManager) isDifferent(request *bundleScheduleRequest) (bool, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "BundleDownloadManager",
+		"function": "isDifferent",
+	})
+
+	targetPath, err := manager.getTargetPath(request.source)
+	if err != nil {
+		return false, xerrors.Errorf("failed to get target path for %s: %w", request.source, err)
+	}
+
+	logger.Debugf("checking if target %s for source %s exists", targetPath, request.source)
+
+	targetStat, err := os.Stat(targetPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			logger.Debugf("adding %s to the bundle as it doesn't exist", request.source)
+			return true, nil
+		}
+
+		return false, xerrors.Errorf("failed to stat %s: %w", targetPath, err)
+	}
+
+	if request.dir {
+		if targetStat.IsDir() {
+			logger.Debugf("skip adding a dir %s to the bundle. The dir already exists!", request.source)
+			return false, nil
+		}
+
+		return false, xerrors.Errorf("failed to download a collection %s, a file %s exists", request.source, targetPath)
+	}
+
+	if targetStat.IsDir() {
+		return false, xerrors.Errorf("failed to download a data object %s, a dir %s exists", request.source, targetPath)
+	}
+
+	if targetStat.Size() != request.size {
+		logger.Debugf("adding a data object %s to the bundle as it has different size %d != %d", request.source, targetStat.Size(), request.size)
+		return true, nil
+	}
+
+	if manager.noHashForComparison {
+		fmt.Printf("skip downloading a data object %s. The file already exists!\n", targetPath)
+		logger.Debugf("skip downloading a data object %s. The file already exists!", targetPath)
+		return false, nil
+	}
+
+	if len(request.checksum) == 0 {
+		logger.Debugf("adding a data object %s to the bundle as it doesn't have hash yet", request.source)
+		return true, nil
+	}
+
+	// compare hash
+	hash, err := manager.hashCache.HashLocalFile(targetPath, request.checksumAlgorithm)
+	if err != nil {
+		return false, xerrors.Errorf("failed to get hash of %s: %w", targetPath, err)
+	}
+
+	if hash == request.checksum {
+		fmt.Printf("skip downloading a data object %s. The file with the same hash already exists!\n", targetPath)
+		logger.Debugf("skip downloading a data object %s. The file with the same hash already exists!", targetPath)
+		return false, nil
+	}
+
+	logger.Debugf("adding a data object %s to the bundle as it has different hash, %s vs %s (alg %s)", request.source, hash, request.checksum, request.checksumAlgorithm)
+	return true, nil
+}
+
+func (manager *BundleDownloadManager) Wait() error {
+	manager.waitTransfers()
+
+	manager.CleanUpBundles()
+
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+	return manager.lastError
+}
+
+// CleanUpBundles removes the staging collection if nothing is left in it
+func (manager *BundleDownloadManager) CleanUpBundles() {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "BundleDownloadManager",
+		"function": "CleanUpBundles",
+	})
+
+	logger.Debugf("clearing staging dir %s", manager.irodsTempDirPath)
+
+	entries, err := manager.filesystem.List(manager.irodsTempDirPath)
+	if err != nil {
+		logger.WithError(err).Warnf("failed to listing staging dir %s", manager.irodsTempDirPath)
+		return
+	}
+
+	if len(entries) > 0 {
+		logger.Warnf("staging dir %s is not empty, use 'bclean' command to clear stale bundles", manager.irodsTempDirPath)
+		return
+	}
+
+	err = manager.filesystem.RemoveDir(manager.irodsTempDirPath, true, true)
+	if err != nil {
+		logger.WithError(err).Warnf("failed to remove staging dir %s", manager.irodsTempDirPath)
+	}
+}
+
+func (manager *BundleDownloadManager) Start() {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "BundleDownloadManager",
+		"function": "Start",
+	})
+
+	processBundleStageChan := make(chan *Bundle, 5)
+	processBundleDownloadChan := make(chan *Bundle, 5)
+	processBundleExtractChan := make(chan *Bundle, 5)
+
+	manager.startProgress()
+
+	if manager.differentFilesOnly {
+		manager.startCompare()
+	}
+
+	// compare (--diff) --> bundle --> stage (copy & tar in iRODS) --> download --> extract
+
+	go func() {
+		logger.Debug("start input thread")
+		defer logger.Debug("exit input thread")
+
+		defer close(processBundleStageChan)
+
+		if !manager.filesystem.ExistsDir(manager.irodsTempDirPath) {
+			err := manager.filesystem.MakeDir(manager.irodsTempDirPath, true)
+			if err != nil {
+				// mark error
+				manager.mutex.Lock()
+				manager.lastError = err
+				manager.mutex.Unlock()
+
+				logger.Error(err)
+				// don't stop here
+			}
+		}
+
+		err := os.MkdirAll(manager.localDestPath, 0755)
+		if err != nil {
+			// mark error
+			manager.mutex.Lock()
+			manager.lastError = err
+			manager.mutex.Unlock()
+
+			logger.Error(err)
+			// don't stop here
+		}
+
+		for bundle := range manager.pendingBundles {
+			processBundleStageChan <- bundle
+		}
+	}()
+
+	// process bundle - stage
+	funcAsyncStage := func(id int, wg *sync.WaitGroup) {
+		logger.Debugf("start stage thread %d", id)
+		defer logger.Debugf("exit stage thread %d", id)
+
+		defer wg.Done()
+
+		for bundle := range processBundleStageChan {
+			manager.runBundleTask(bundle, BundleTaskNameStage, manager.processBundleStage)
+			processBundleDownloadChan <- bundle
+		}
+	}
+
+	waitAsyncStage := sync.WaitGroup{}
+	for i := 0; i < BundleStageThreadNumDefault; i++ {
+		waitAsyncStage.Add(1)
+		go funcAsyncStage(i, &waitAsyncStage)
+	}
+
+	go func() {
+		waitAsyncStage.Wait()
+		close(processBundleDownloadChan)
+	}()
+
+	// process bundle - download
+	funcAsyncDownload := func(id int, wg *sync.WaitGroup) {
+		logger.Debugf("start transfer thread %d", id)
+		defer logger.Debugf("exit transfer thread %d", id)
+
+		defer wg.Done()
+
+		for bundle := range processBundleDownloadChan {
+			manager.runBundleTask(bundle, BundleTaskNameDownload, manager.processBundleDownload)
+			processBundleExtractChan <- bundle
+		}
+	}
+
+	waitAsyncDownload := sync.WaitGroup{}
+	for i := 0; i < manager.downloadThreadNum; i++ {
+		waitAsyncDownload.Add(1)
+		go funcAsyncDownload(i, &waitAsyncDownload)
+	}
+
+	go func() {
+		waitAsyncDownload.Wait()
+		close(processBundleExtractChan)
+	}()
+
+	// process bundle - extract
+	funcAsyncExtract := func(id int, wg *sync.WaitGroup) {
+		logger.Debugf("start extract thread %d", id)
+		defer logger.Debugf("exit extract thread %d", id)
+
+		defer wg.Done()
+
+		for bundle := range processBundleExtractChan {
+			manager.runBundleTask(bundle, BundleTaskNameExtract, manager.processBundleExtract)
+
+			// remove bundle files left by errors
+			manager.cleanUpBundle(bundle)
+			manager.transferWait.Done()
+		}
+	}
+
+	waitAsyncExtract := sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		waitAsyncExtract.Add(1)
+		go funcAsyncExtract(i, &waitAsyncExtract)
+	}
+
+	go func() {
+		waitAsyncExtract.Wait()
+
+		manager.endProgress()
+	}()
+}
+
+// processBundleStage copies data-objects in the bundle to the staging collection and bundles them into a TAR file in iRODS
+func (manager *BundleDownloadManager) processBundleStage(bundle *Bundle) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "BundleDownloadManager",
+		"function": "processBundleStage",
+	})
+
+	progressName := manager.getProgressName(bundle, BundleTaskNameStage)
+
+	totalFileNum := int64(bundle.fileNum)
+	processedFiles := int64(0)
+
+	if manager.showProgress {
+		manager.progress(progressName, 0, totalFileNum, progress.UnitsDefault, false)
+	}
+
+	if !bundle.requireStaging() {
+		// no tar, so pass this step
+		if manager.showProgress {
+			manager.progress(progressName, totalFileNum, totalFileNum, progress.UnitsDefault, false)
+		}
+
+		logger.Debugf("skip - staging bundle %d to %s", bundle.index, bundle.irodsBundlePath)
+		return nil
+	}
+
+	logger.Debugf("copying data objects in bundle %d to %s", bundle.index, bundle.irodsStagingPath)
+
+	if manager.filesystem.ExistsDir(bundle.irodsStagingPath) {
+		// stale
+		err := manager.filesystem.RemoveDir(bundle.irodsStagingPath, true, true)
+		if err != nil {
+			if manager.showProgress {
+				manager.progress(progressName, -1, totalFileNum, progress.UnitsDefault, true)
+			}
+
+			return xerrors.Errorf("failed to remove stale staging dir %s: %w", bundle.irodsStagingPath, err)
+		}
+	}
+
+	for _, entry := range bundle.entries {
+		if entry.Dir {
+			// dirs are made locally
+			continue
+		}
+
+		relPath, err := manager.getRelPath(entry.IRODSPath)
+		if err != nil {
+			if manager.showProgress {
+				manager.progress(progressName, -1, totalFileNum, progress.UnitsDefault, true)
+			}
+
+			return err
+		}
+
+		stagingFilePath := path.Join(bundle.irodsStagingPath, relPath)
+
+		if !manager.filesystem.ExistsDir(path.Dir(stagingFilePath)) {
+			err = manager.filesystem.MakeDir(path.Dir(stagingFilePath), true)
+			if err != nil {
+				if manager.showProgress {
+					manager.progress(progressName, -1, totalFileNum, progress.UnitsDefault, true)
+				}
+
+				return xerrors.Errorf("failed to make dir %s: %w", path.Dir(stagingFilePath), err)
+			}
+		}
+
+		err = manager.filesystem.CopyFileToFile(entry.IRODSPath, stagingFilePath, true)
+		if err != nil {
+			if manager.showProgress {
+				manager.progress(progressName, -1, totalFileNum, progress.UnitsDefault, true)
+			}
+
+			return xerrors.Errorf("failed to copy %s to %s: %w", entry.IRODSPath, stagingFilePath, err)
+		}
+
+		processedFiles++
+		if manager.showProgress {
+			manager.progress(progressName, processedFiles, totalFileNum, progress.UnitsDefault, false)
+		}
+	}
+
+	logger.Debugf("creating a tarball for bundle %d to %s", bundle.index, bundle.irodsBundlePath)
+
+	err := CreateStructFile(GetAccount(), bundle.irodsBundlePath, bundle.irodsStagingPath, "", irodsclient_types.TAR_FILE_DT, true)
+	if err != nil {
+		if manager.showProgress {
+			manager.progress(progressName, -1, totalFileNum, progress.UnitsDefault, true)
+		}
+
+		return xerrors.Errorf("failed to create a tarball for bundle %d to %s: %w", bundle.index, bundle.irodsBundlePath, err)
+	}
+
+	// copies are in the tarball now
+	err = manager.filesystem.RemoveDir(bundle.irodsStagingPath, true, true)
+	if err != nil {
+		logger.WithError(err).Warnf("failed to remove staging dir %s", bundle.irodsStagingPath)
+	}
+
+	logger.Debugf("created a tarball for bundle %d to %s", bundle.index, bundle.irodsBundlePath)
+	return nil
+}
+
+func (manager *BundleDownloadManager) processBundleDownload(bundle *Bundle) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "BundleDownloadManager",
+		"function": "processBundleDownload",
+	})
+
+	progressName := manager.getProgressName(bundle, BundleTaskNameDownload)
+
+	totalFileSize := bundle.size
+
+	if bundle.requireStaging() {
+		logger.Debugf("downloading bundle %d at %s to %s", bundle.index, bundle.irodsBundlePath, bundle.localBundlePath)
+
+		var callback func(processed int64, total int64)
+		if manager.showProgress {
+			callback = func(processed int64, total int64) {
+				manager.progress(progressName, processed, total, progress.UnitsBytes, false)
+			}
+		}
+
+		callback = manager.rateLimiter.WrapCallback(callback, 0)
+
+		var err error
+		if manager.singleThreaded {
+			err = manager.filesystem.DownloadFile(bundle.irodsBundlePath, "", bundle.localBundlePath, callback)
+		} else {
+			err = manager.filesystem.DownloadFileParallel(bundle.irodsBundlePath, "", bundle.localBundlePath, 0, callback)
+		}
+
+		if err != nil {
+			if manager.showProgress {
+				manager.progress(progressName, -1, totalFileSize, progress.UnitsBytes, true)
+			}
+
+			return xerrors.Errorf("failed to download bundle %d at %s to %s: %w", bundle.index, bundle.irodsBundlePath, bundle.localBundlePath, err)
+		}
+
+		// remove irods bundle file
+		manager.filesystem.RemoveFile(bundle.irodsBundlePath, true)
+
+		logger.Debugf("downloaded bundle %d at %s to %s", bundle.index, bundle.irodsBundlePath, bundle.localBundlePath)
+		return nil
+	}
+
+	fileDownloadProgress := make([]int64, len(bundle.entries))
+	fileDownloadProgressMutex := sync.Mutex{}
+
+	if manager.showProgress {
+		manager.progress(progressName, 0, totalFileSize, progress.UnitsBytes, false)
+	}
+
+	for fileIdx, file := range bundle.entries {
+		if file.Dir {
+			// dirs are made in extraction
+			continue
+		}
+
+		var callbackFileDownload func(processed int64, total int64)
+		if manager.showProgress {
+			callbackFileDownload = func(processed int64, total int64) {
+				fileDownloadProgressMutex.Lock()
+				defer fileDownloadProgressMutex.Unlock()
+
+				fileDownloadProgress[fileIdx] = processed
+
+				progressSum := int64(0)
+				for _, progress := range fileDownloadProgress {
+					progressSum += progress
+				}
+
+				manager.progress(progressName, progressSum, totalFileSize, progress.UnitsBytes, false)
+			}
+		}
+
+		callbackFileDownload = manager.rateLimiter.WrapCallback(callbackFileDownload, 0)
+
+		err := os.MkdirAll(filepath.Dir(file.LocalPath), 0755)
+		if err != nil {
+			if manager.showProgress {
+				manager.progress(progressName, -1, totalFileSize, progress.UnitsBytes, true)
+			}
+
+			return newBundleEntryError(fileIdx, xerrors.Errorf("failed to make dir %s: %w", filepath.Dir(file.LocalPath), err))
+		}
+
+		// delete file to not write to existing file
+		os.Remove(file.LocalPath)
+
+		if manager.singleThreaded {
+			err = manager.filesystem.DownloadFile(file.IRODSPath, "", file.LocalPath, callbackFileDownload)
+		} else {
+			err = manager.filesystem.DownloadFileParallel(file.IRODSPath, "", file.LocalPath, 0, callbackFileDownload)
+		}
+
+		if err != nil {
+			if manager.showProgress {
+				manager.progress(progressName, -1, totalFileSize, progress.UnitsBytes, true)
+			}
+
+			return newBundleEntryError(fileIdx, xerrors.Errorf("failed to download file %s in bundle %d to %s: %w", file.IRODSPath, bundle.index, file.LocalPath, err))
+		}
+
+		logger.Debugf("downloaded file %s in bundle %d to %s", file.IRODSPath, bundle.index, file.LocalPath)
+	}
+
+	logger.Debugf("downloaded files in bundle %d", bundle.index)
+	return nil
+}
+
+func (manager *BundleDownloadManager) processBundleExtract(bundle *Bundle) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "BundleDownloadManager",
+		"function": "processBundleExtract",
+	})
+
+	progressName := manager.getProgressName(bundle, BundleTaskNameExtract)
+
+	totalFileNum := int64(len(bundle.entries))
+
+	if manager.showProgress {
+		manager.progress(progressName, 0, totalFileNum, progress.UnitsDefault, false)
+	}
+
+	for _, entry := range bundle.entries {
+		if entry.Dir {
+			err := os.MkdirAll(entry.LocalPath, 0755)
+			if err != nil {
+				if manager.showProgress {
+					manager.progress(progressName, -1, totalFileNum, progress.UnitsDefault, true)
+				}
+
+				return xerrors.Errorf("failed to make dir %s: %w", entry.LocalPath, err)
+			}
+		}
+	}
+
+	if !bundle.requireStaging() {
+		// no tar, so pass this step
+		if manager.showProgress {
+			manager.progress(progressName, totalFileNum, totalFileNum, progress.UnitsDefault, false)
+		}
+
+		logger.Debugf("skip - extracting bundle %d at %s", bundle.index, bundle.localBundlePath)
+		return nil
+	}
+
+	logger.Debugf("extracting bundle %d at %s to %s", bundle.index, bundle.localBundlePath, manager.localDestPath)
+
+	err := Untar(bundle.localBundlePath, manager.localDestPath, nil)
+	if err != nil {
+		if manager.showProgress {
+			manager.progress(progressName, -1, totalFileNum, progress.UnitsDefault, true)
+		}
+
+		return xerrors.Errorf("failed to extract bundle %d at %s to %s: %w", bundle.index, bundle.localBundlePath, manager.localDestPath, err)
+	}
+
+	// remove local bundle file
+	os.Remove(bundle.localBundlePath)
+
+	if manager.showProgress {
+		manager.progress(progressName, totalFileNum, totalFileNum, progress.UnitsDefault, false)
+	}
+
+	logger.Debugf("extracted bundle %d at %s to %s", bundle.index, bundle.localBundlePath, manager.localDestPath)
+	return nil
+}
+
+// cleanUpBundle removes the staging collection and bundle files of the bundle if they are left
+func (manager *BundleDownloadManager) cleanUpBundle(bundle *Bundle) {
+	if !bundle.requireStaging() {
+		return
+	}
+
+	if manager.filesystem.ExistsDir(bundle.irodsStagingPath) {
+		manager.filesystem.RemoveDir(bundle.irodsStagingPath, true, true)
+	}
+
+	if manager.filesystem.ExistsFile(bundle.irodsBundlePath) {
+		manager.filesystem.RemoveFile(bundle.irodsBundlePath, true)
+	}
+
+	os.Remove(bundle.localBundlePath)
+}
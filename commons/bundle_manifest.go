@@ -0,0 +1,221 @@
+package commons
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"golang.org/x/xerrors"
+)
+
+// bundleManifestEntry is a file or a dir recorded in the manifest when it is transferred to its target
+type bundleManifestEntry struct {
+	LocalPath string `json:"localPath"`
+	IRODSPath string `json:"irodsPath"`
+	Size      int64  `json:"size"`
+	ModTime   int64  `json:"mtime"`
+	Dir       bool   `json:"dir"`
+}
+
+// BundleManifest records files transferred in the local temp dir, to skip them when a failed bundle transfer runs again.
+// Files are recorded per file, not per bundle, as files may be bundled differently in the next run.
+// The manifest file is a journal, each line is an entry appended when a bundle is done.
+type BundleManifest struct {
+	path    string
+	entries map[string]*bundleManifestEntry
+	file    *os.File
+	mutex   sync.Mutex
+}
+
+// GetBundleManifestFilename returns the filename of the manifest for the iRODS target path
+func GetBundleManifestFilename(irodsDestPath string) (string, error) {
+	hash, err := HashStrings([]string{irodsDestPath}, string(irodsclient_types.ChecksumAlgorithmMD5))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("bundle_manifest_%s.jsonl", hash), nil
+}
+
+// IsBundleManifestFilename checks if the filename is a manifest filename
+func IsBundleManifestFilename(p string) bool {
+	return strings.HasPrefix(p, "bundle_manifest_") && strings.HasSuffix(p, ".jsonl")
+}
+
+// NewBundleManifest creates a new BundleManifest for the iRODS target path, loading entries of the previous run if they exist
+func NewBundleManifest(localTempDirPath string, irodsDestPath string) (*BundleManifest, error) {
+	filename, err := GetBundleManifestFilename(irodsDestPath)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get manifest filename for %s: %w", irodsDestPath, err)
+	}
+
+	manifest := &BundleManifest{
+		path:    filepath.Join(localTempDirPath, filename),
+		entries: map[string]*bundleManifestEntry{},
+		file:    nil,
+		mutex:   sync.Mutex{},
+	}
+
+	manifestFile, err := os.Open(manifest.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+
+		return nil, xerrors.Errorf("failed to open manifest file %s: %w", manifest.path, err)
+	}
+	defer manifestFile.Close()
+
+	scanner := bufio.NewScanner(manifestFile)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry := bundleManifestEntry{}
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			// the last line may be cut when the process was killed
+			break
+		}
+
+		manifest.entries[entry.LocalPath] = &entry
+	}
+
+	return manifest, nil
+}
+
+// GetPath returns the path of the manifest file
+func (manifest *BundleManifest) GetPath() string {
+	return manifest.path
+}
+
+// IsDone checks if the local file or dir is transferred to the iRODS path in the previous run and not changed since
+func (manifest *BundleManifest) IsDone(localPath string, irodsPath string, dir bool, size int64) bool {
+	if manifest == nil {
+		return false
+	}
+
+	manifest.mutex.Lock()
+	entry, ok := manifest.entries[localPath]
+	manifest.mutex.Unlock()
+
+	if !ok || entry.IRODSPath != irodsPath || entry.Dir != dir {
+		return false
+	}
+
+	if dir {
+		return true
+	}
+
+	stat, err := os.Stat(localPath)
+	if err != nil {
+		return false
+	}
+
+	return entry.Size == size && stat.Size() == size && stat.ModTime().UnixNano() == entry.ModTime
+}
+
+// MarkDone records entries of the bundle as transferred, entries are appended to the manifest file in a single write
+func (manifest *BundleManifest) MarkDone(entries []*BundleEntry) error {
+	if manifest == nil || len(entries) == 0 {
+		return nil
+	}
+
+	lines := []byte{}
+	for _, entry := range entries {
+		recordEntry := bundleManifestEntry{
+			LocalPath: entry.LocalPath,
+			IRODSPath: entry.IRODSPath,
+			Size:      entry.Size,
+			Dir:       entry.Dir,
+		}
+
+		if !entry.Dir {
+			stat, err := os.Stat(entry.LocalPath)
+			if err != nil {
+				return xerrors.Errorf("failed to stat %s: %w", entry.LocalPath, err)
+			}
+
+			recordEntry.ModTime = stat.ModTime().UnixNano()
+		}
+
+		lineBytes, err := json.Marshal(recordEntry)
+		if err != nil {
+			return xerrors.Errorf("failed to marshal manifest entry: %w", err)
+		}
+
+		lines = append(lines, lineBytes...)
+		lines = append(lines, '\n')
+	}
+
+	manifest.mutex.Lock()
+	defer manifest.mutex.Unlock()
+
+	if manifest.file == nil {
+		err := os.MkdirAll(filepath.Dir(manifest.path), 0700)
+		if err != nil {
+			return xerrors.Errorf("failed to make dir %s: %w", filepath.Dir(manifest.path), err)
+		}
+
+		manifestFile, err := os.OpenFile(manifest.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return xerrors.Errorf("failed to open manifest file %s: %w", manifest.path, err)
+		}
+
+		manifest.file = manifestFile
+	}
+
+	_, err := manifest.file.Write(lines)
+	if err != nil {
+		return xerrors.Errorf("failed to write manifest file %s: %w", manifest.path, err)
+	}
+
+	return nil
+}
+
+// Close closes the manifest file, entries recorded are kept for the next run
+func (manifest *BundleManifest) Close() error {
+	if manifest == nil {
+		return nil
+	}
+
+	manifest.mutex.Lock()
+	defer manifest.mutex.Unlock()
+
+	if manifest.file != nil {
+		err := manifest.file.Close()
+		manifest.file = nil
+		if err != nil {
+			return xerrors.Errorf("failed to close manifest file %s: %w", manifest.path, err)
+		}
+	}
+
+	return nil
+}
+
+// Remove removes the manifest file, called when all files are done
+func (manifest *BundleManifest) Remove() error {
+	if manifest == nil {
+		return nil
+	}
+
+	err := manifest.Close()
+	if err != nil {
+		return err
+	}
+
+	manifest.mutex.Lock()
+	defer manifest.mutex.Unlock()
+
+	manifest.entries = map[string]*bundleManifestEntry{}
+
+	err = os.Remove(manifest.path)
+	if err != nil && !os.IsNotExist(err) {
+		return xerrors.Errorf("failed to remove manifest file %s: %w", manifest.path, err)
+	}
+
+	return nil
+}
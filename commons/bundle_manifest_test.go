@@ -0,0 +1,98 @@
+package commons
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundleManifest(t *testing.T) {
+	t.Run("test BundleManifest", testBundleManifest)
+}
+
+func testBundleManifest(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath1 := filepath.Join(tempDir, "file1.txt")
+	filePath2 := filepath.Join(tempDir, "file2.txt")
+
+	err := os.WriteFile(filePath1, []byte("hello world"), 0644)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filePath2, []byte("hello"), 0644)
+	assert.NoError(t, err)
+
+	manifest, err := NewBundleManifest(tempDir, "/zone/home/user")
+	assert.NoError(t, err)
+	assert.True(t, IsBundleManifestFilename(filepath.Base(manifest.GetPath())))
+
+	assert.False(t, manifest.IsDone(filePath1, "/zone/home/user/file1.txt", false, 11))
+
+	// files are recorded per bundle
+	err = manifest.MarkDone([]*BundleEntry{
+		{
+			LocalPath: filePath1,
+			IRODSPath: "/zone/home/user/file1.txt",
+			Size:      11,
+		},
+		{
+			LocalPath: tempDir,
+			IRODSPath: "/zone/home/user",
+			Dir:       true,
+		},
+	})
+	assert.NoError(t, err)
+
+	err = manifest.MarkDone([]*BundleEntry{
+		{
+			LocalPath: filePath2,
+			IRODSPath: "/zone/home/user/file2.txt",
+			Size:      5,
+		},
+	})
+	assert.NoError(t, err)
+
+	err = manifest.Close()
+	assert.NoError(t, err)
+
+	// reload, entries are kept regardless of bundles
+	manifest, err = NewBundleManifest(tempDir, "/zone/home/user")
+	assert.NoError(t, err)
+
+	assert.True(t, manifest.IsDone(filePath1, "/zone/home/user/file1.txt", false, 11))
+	assert.True(t, manifest.IsDone(filePath2, "/zone/home/user/file2.txt", false, 5))
+	assert.True(t, manifest.IsDone(tempDir, "/zone/home/user", true, 0))
+	assert.False(t, manifest.IsDone(filePath1, "/zone/home/user/other.txt", false, 11))
+
+	// different target path has a different manifest
+	otherManifest, err := NewBundleManifest(tempDir, "/zone/home/user/other")
+	assert.NoError(t, err)
+	assert.False(t, otherManifest.IsDone(filePath1, "/zone/home/user/file1.txt", false, 11))
+
+	// changed file
+	modTime := time.Now().Add(time.Hour)
+	err = os.Chtimes(filePath1, modTime, modTime)
+	assert.NoError(t, err)
+
+	assert.False(t, manifest.IsDone(filePath1, "/zone/home/user/file1.txt", false, 11))
+	assert.True(t, manifest.IsDone(filePath2, "/zone/home/user/file2.txt", false, 5))
+
+	// a cut line at the end is ignored
+	manifestFile, err := os.OpenFile(manifest.GetPath(), os.O_WRONLY|os.O_APPEND, 0600)
+	assert.NoError(t, err)
+	_, err = manifestFile.WriteString(`{"localPath":"`)
+	assert.NoError(t, err)
+	manifestFile.Close()
+
+	manifest, err = NewBundleManifest(tempDir, "/zone/home/user")
+	assert.NoError(t, err)
+	assert.True(t, manifest.IsDone(filePath2, "/zone/home/user/file2.txt", false, 5))
+
+	err = manifest.Remove()
+	assert.NoError(t, err)
+
+	_, err = os.Stat(manifest.GetPath())
+	assert.True(t, os.IsNotExist(err))
+}
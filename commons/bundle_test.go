@@ -0,0 +1,165 @@
+package commons
+
+import (
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestBundle(t *testing.T) {
+	t.Run("test BundleUpload", testBundleUpload)
+	t.Run("test BundleDownload", testBundleDownload)
+	t.Run("test BundleDownloadStagingPath", testBundleDownloadStagingPath)
+	t.Run("test MarkBundleError", testMarkBundleError)
+	t.Run("test IsBundleStagingDirname", testIsBundleStagingDirname)
+}
+
+func testBundleUpload(t *testing.T) {
+	localTempDir := t.TempDir()
+	manager := NewBundleTransferManager(nil, "/zone/home/user/dest", 2, 100, false, 1, localTempDir, "/zone/home/user/.gocmd_staging", false, false, false, false)
+	manager.SetBundleRootPath("/local/src")
+
+	assert.NoError(t, manager.Schedule("/local/src/a.txt", false, 10, time.Time{}))
+	// large files are bundled with others for uploads
+	assert.NoError(t, manager.Schedule("/local/src/b.txt", false, 200, time.Time{}))
+	assert.NoError(t, manager.Schedule("/local/src/sub", true, 0, time.Time{}))
+	manager.DoneScheduling()
+
+	bundles := manager.GetBundles()
+	assert.Len(t, bundles, 2)
+
+	entries := bundles[0].GetEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "/local/src/a.txt", entries[0].LocalPath)
+	assert.Equal(t, "/zone/home/user/dest/a.txt", entries[0].IRODSPath)
+	assert.Equal(t, "/local/src/b.txt", entries[1].LocalPath)
+	assert.Equal(t, int64(210), bundles[0].size)
+
+	entries = bundles[1].GetEntries()
+	assert.Len(t, entries, 1)
+	assert.True(t, entries[0].Dir)
+	assert.Equal(t, "/zone/home/user/dest/sub", entries[0].IRODSPath)
+
+	filename, err := bundles[0].GetBundleFilename()
+	assert.NoError(t, err)
+	assert.True(t, IsBundleFilename(filename))
+	assert.Equal(t, filepath.Join(localTempDir, filename), bundles[0].localBundlePath)
+	assert.Equal(t, path.Join("/zone/home/user/.gocmd_staging", filename), bundles[0].irodsBundlePath)
+
+	assert.True(t, manager.GetInputPathMap()["/zone/home/user/dest/a.txt"])
+}
+
+func testBundleDownload(t *testing.T) {
+	localTempDir := t.TempDir()
+	manager := NewBundleDownloadManager(nil, "/local/dest", 3, 100, false, 1, localTempDir, "/zone/home/user/.gocmd_staging", false, false, false)
+	manager.SetBundleRootPath("/zone/home/user")
+
+	assert.NoError(t, manager.Schedule("/zone/home/user/data/a.txt", false, 10, "", ""))
+	assert.NoError(t, manager.Schedule("/zone/home/user/data/b.txt", false, 10, "", ""))
+	assert.NoError(t, manager.Schedule("/zone/home/user/data/sub", true, 0, "", ""))
+	// large data objects are downloaded directly in their own bundles
+	assert.NoError(t, manager.Schedule("/zone/home/user/data/c.txt", false, 200, "", ""))
+	// the staging collection under the source collection is skipped
+	assert.NoError(t, manager.Schedule("/zone/home/user/.gocmd_staging", true, 0, "", ""))
+	assert.NoError(t, manager.Schedule("/zone/home/user/.gocmd_staging/bundle_0/d.txt", false, 10, "", ""))
+	manager.DoneScheduling()
+
+	bundles := manager.GetBundles()
+	assert.Len(t, bundles, 2)
+
+	largeEntries := bundles[0].GetEntries()
+	assert.Len(t, largeEntries, 1)
+	assert.Equal(t, "/zone/home/user/data/c.txt", largeEntries[0].IRODSPath)
+	assert.Equal(t, filepath.Join("/local/dest", "data", "c.txt"), largeEntries[0].LocalPath)
+	assert.False(t, bundles[0].requireStaging())
+
+	entries := bundles[1].GetEntries()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "/zone/home/user/data/a.txt", entries[0].IRODSPath)
+	assert.Equal(t, filepath.Join("/local/dest", "data", "a.txt"), entries[0].LocalPath)
+	assert.True(t, entries[2].Dir)
+
+	// dirs are made locally, so two data objects are too few to stage
+	assert.Equal(t, 2, bundles[1].fileNum)
+	assert.False(t, bundles[1].requireStaging())
+}
+
+func testBundleDownloadStagingPath(t *testing.T) {
+	localTempDir := t.TempDir()
+	manager := NewBundleDownloadManager(nil, "/local/dest", 10, 100, false, 1, localTempDir, "/zone/home/user/.gocmd_staging", false, false, false)
+	manager.SetBundleRootPath("/zone/home/user")
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		assert.NoError(t, manager.Schedule(path.Join("/zone/home/user/data", name), false, 10, "", ""))
+	}
+	manager.DoneScheduling()
+
+	bundles := manager.GetBundles()
+	assert.Len(t, bundles, 1)
+
+	bundle := bundles[0]
+	assert.True(t, bundle.requireStaging())
+
+	filename, err := bundle.GetBundleFilename()
+	assert.NoError(t, err)
+
+	// data objects are copied to the staging collection, then bundled into the TAR file next to it
+	assert.Equal(t, "/zone/home/user/.gocmd_staging", path.Dir(bundle.irodsStagingPath))
+	assert.True(t, IsBundleStagingDirname(path.Base(bundle.irodsStagingPath)))
+	assert.Equal(t, bundle.irodsStagingPath+".tar", bundle.irodsBundlePath)
+	assert.Equal(t, filepath.Join(localTempDir, filename), bundle.localBundlePath)
+}
+
+func testMarkBundleError(t *testing.T) {
+	manager := NewBundleDownloadManager(nil, "/local/dest", 10, 100, false, 1, t.TempDir(), "/zone/home/user/.gocmd_staging", false, false, false)
+	manager.SetBundleRootPath("/zone/home/user")
+	manager.SetFailureReport(NewFailureReport())
+
+	assert.NoError(t, manager.Schedule("/zone/home/user/a.txt", false, 10, "", ""))
+	assert.NoError(t, manager.Schedule("/zone/home/user/sub", true, 0, "", ""))
+	assert.NoError(t, manager.Schedule("/zone/home/user/b.txt", false, 10, "", ""))
+	assert.NoError(t, manager.Schedule("/zone/home/user/c.txt", false, 10, "", ""))
+	manager.DoneScheduling()
+
+	bundle := manager.GetBundles()[0]
+	assert.True(t, manager.canProcessBundle(bundle))
+
+	manager.runBundleTask(bundle, BundleTaskNameDownload, func(bundle *Bundle) error {
+		return xerrors.Errorf("download failed")
+	})
+
+	// failures are recorded with source paths of files, not dirs, and the manager continues
+	assert.False(t, manager.canProcessBundle(bundle))
+	assert.NoError(t, manager.lastError)
+
+	failures := manager.GetFailureReport().GetFailures()
+	assert.Len(t, failures, 3)
+	assert.Equal(t, "/zone/home/user/a.txt", failures[0].Path)
+	assert.Equal(t, "/zone/home/user/b.txt", failures[1].Path)
+	assert.Equal(t, "/zone/home/user/c.txt", failures[2].Path)
+	assert.Equal(t, BundleTaskNameDownload, failures[0].Phase)
+
+	// files before the failed entry are done
+	manager.SetFailureReport(NewFailureReport())
+	bundle.lastError = nil
+
+	manager.runBundleTask(bundle, BundleTaskNameDownload, func(bundle *Bundle) error {
+		return newBundleEntryError(2, xerrors.Errorf("download failed"))
+	})
+
+	failures = manager.GetFailureReport().GetFailures()
+	assert.Len(t, failures, 2)
+	assert.Equal(t, "/zone/home/user/b.txt", failures[0].Path)
+	assert.Equal(t, "/zone/home/user/c.txt", failures[1].Path)
+}
+
+func testIsBundleStagingDirname(t *testing.T) {
+	assert.True(t, IsBundleStagingDirname("bundle_0123456789abcdef0123456789abcdef"))
+	assert.False(t, IsBundleStagingDirname("bundle_0123456789abcdef0123456789abcdef.tar"))
+	assert.False(t, IsBundleStagingDirname("bundle_data"))
+	assert.False(t, IsBundleStagingDirname("data_0123456789abcdef0123456789abcdef"))
+}
@@ -20,6 +20,15 @@ const (
 	MaxBundleFileNumDefault  int   = 50
 	MaxBundleFileSizeDefault int64 = 2 * 1024 * 1024 * 1024 // 2GB
 	MinBundleFileNumDefault  int   = 3
+	CompareThreadNumDefault  int   = 10
+)
+
+// bundle formats
+const (
+	BundleFormatTar  string = "tar"
+	BundleFormatTgz  string = "tgz"
+	BundleFormatTbz2 string = "tbz2"
+	BundleFormatZip  string = "zip"
 )
 
 const (
@@ -29,191 +38,38 @@ const (
 	BundleTaskNameExtract                string = "Extracting"
 )
 
-type BundleEntry struct {
-	LocalPath string
-	IRODSPath string
-	Size      int64
-	Dir       bool
-}
-
-type Bundle struct {
-	manager *BundleTransferManager
-
-	index             int64
-	entries           []*BundleEntry
-	size              int64
-	localBundlePath   string
-	irodsBundlePath   string
-	lastError         error
-	lastErrorTaskName string
-}
-
-func newBundle(manager *BundleTransferManager) (*Bundle, error) {
-	bundle := &Bundle{
-		manager:           manager,
-		index:             manager.getNextBundleIndex(),
-		entries:           []*BundleEntry{},
-		size:              0,
-		localBundlePath:   "",
-		irodsBundlePath:   "",
-		lastError:         nil,
-		lastErrorTaskName: "",
-	}
-
-	err := bundle.updateBundlePath()
-	if err != nil {
-		return nil, err
-	}
-
-	return bundle, nil
-}
-
-func (bundle *Bundle) GetEntries() []*BundleEntry {
-	return bundle.entries
-}
-
-func (bundle *Bundle) GetBundleFilename() (string, error) {
-	entryStrs := []string{}
-
-	entryStrs = append(entryStrs, "empty_bundle")
-
-	for _, entry := range bundle.entries {
-		entryStrs = append(entryStrs, entry.LocalPath)
-	}
-
-	hash, err := HashStrings(entryStrs, string(irodsclient_types.ChecksumAlgorithmMD5))
-	if err != nil {
-		return "", err
-	}
-
-	return GetBundleFilename(hash), nil
-}
-
-func (bundle *Bundle) AddFile(localPath string, size int64) error {
-	irodsPath, err := bundle.manager.getTargetPath(localPath)
-	if err != nil {
-		return xerrors.Errorf("failed to get target path for %s: %w", localPath, err)
-	}
-
-	e := &BundleEntry{
-		LocalPath: localPath,
-		IRODSPath: irodsPath,
-		Size:      size,
-		Dir:       false,
-	}
-
-	bundle.entries = append(bundle.entries, e)
-	bundle.size += size
-
-	err = bundle.updateBundlePath()
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (bundle *Bundle) AddDir(localPath string) error {
-	irodsPath, err := bundle.manager.getTargetPath(localPath)
-	if err != nil {
-		return xerrors.Errorf("failed to get target path for %s: %w", localPath, err)
-	}
-
-	e := &BundleEntry{
-		LocalPath: localPath,
-		IRODSPath: irodsPath,
-		Size:      0,
-		Dir:       true,
-	}
-
-	bundle.entries = append(bundle.entries, e)
-
-	err = bundle.updateBundlePath()
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (bundle *Bundle) updateBundlePath() error {
-	filename, err := bundle.GetBundleFilename()
-	if err != nil {
-		return xerrors.Errorf("failed to get bundle filename: %w", err)
-	}
-
-	bundle.localBundlePath = filepath.Join(bundle.manager.localTempDirPath, filename)
-	bundle.irodsBundlePath = filepath.Join(bundle.manager.irodsTempDirPath, filename)
-	return nil
-}
-
-func (bundle *Bundle) isFull() bool {
-	return bundle.size >= bundle.manager.maxBundleFileSize || len(bundle.entries) >= bundle.manager.maxBundleFileNum
-}
-
-func (bundle *Bundle) requireTar() bool {
-	return len(bundle.entries) >= MinBundleFileNumDefault
-}
-
+// BundleTransferManager uploads local files in bundles.
+// Small files are bundled into a bundle file locally, then the bundle file is uploaded and extracted in iRODS.
 type BundleTransferManager struct {
-	filesystem              *irodsclient_fs.FileSystem
-	irodsDestPath           string
-	currentBundle           *Bundle
-	nextBundleIndex         int64
-	pendingBundles          chan *Bundle
-	bundles                 []*Bundle
-	inputPathMap            map[string]bool
-	bundleRootPath          string
-	maxBundleFileNum        int
-	maxBundleFileSize       int64
-	singleThreaded          bool
-	uploadThreadNum         int
-	localTempDirPath        string
-	irodsTempDirPath        string
-	differentFilesOnly      bool
-	noHashForComparison     bool
-	noBulkRegistration      bool
-	showProgress            bool
-	progressWriter          progress.Writer
-	progressTrackers        map[string]*progress.Tracker
-	progressTrackerCallback ProgressTrackerCallback
-	lastError               error
-	mutex                   sync.RWMutex
-
-	scheduleWait sync.WaitGroup
-	transferWait sync.WaitGroup
+	bundleScheduler
+
+	irodsDestPath      string
+	inputPathMap       map[string]bool
+	singleThreaded     bool
+	uploadThreadNum    int
+	noBulkRegistration bool
+	verifyChecksum     bool
+	keepMismatched     bool
+	manifest           *BundleManifest
 }
 
 // NewBundleTransferManager creates a new BundleTransferManager
 func NewBundleTransferManager(fs *irodsclient_fs.FileSystem, irodsDestPath string, maxBundleFileNum int, maxBundleFileSize int64, singleThreaded bool, uploadThreadNum int, localTempDirPath string, irodsTempDirPath string, diff bool, noHash bool, noBulkReg bool, showProgress bool) *BundleTransferManager {
 	manager := &BundleTransferManager{
-		filesystem:              fs,
-		irodsDestPath:           irodsDestPath,
-		currentBundle:           nil,
-		nextBundleIndex:         0,
-		pendingBundles:          make(chan *Bundle, 100),
-		bundles:                 []*Bundle{},
-		inputPathMap:            map[string]bool{},
-		bundleRootPath:          "/",
-		maxBundleFileNum:        maxBundleFileNum,
-		maxBundleFileSize:       maxBundleFileSize,
-		singleThreaded:          singleThreaded,
-		uploadThreadNum:         uploadThreadNum,
-		localTempDirPath:        localTempDirPath,
-		irodsTempDirPath:        irodsTempDirPath,
-		differentFilesOnly:      diff,
-		noHashForComparison:     noHash,
-		noBulkRegistration:      noBulkReg,
-		showProgress:            showProgress,
-		progressWriter:          nil,
-		progressTrackers:        map[string]*progress.Tracker{},
-		progressTrackerCallback: nil,
-		lastError:               nil,
-		mutex:                   sync.RWMutex{},
-		scheduleWait:            sync.WaitGroup{},
-		transferWait:            sync.WaitGroup{},
+		bundleScheduler:    newBundleScheduler(fs, false, maxBundleFileNum, maxBundleFileSize, localTempDirPath, irodsTempDirPath, diff, noHash, showProgress),
+		irodsDestPath:      irodsDestPath,
+		inputPathMap:       map[string]bool{},
+		singleThreaded:     singleThreaded,
+		uploadThreadNum:    uploadThreadNum,
+		noBulkRegistration: noBulkReg,
+		verifyChecksum:     false,
+		keepMismatched:     false,
+		manifest:           nil,
 	}
 
+	manager.getTargetPathFunc = manager.getTargetPath
+	manager.isDifferentFunc = manager.isDifferent
+
 	if manager.uploadThreadNum > UploadTreadNumMax {
 		manager.uploadThreadNum = UploadTreadNumMax
 	}
@@ -223,22 +79,6 @@ func NewBundleTransferManager(fs *irodsclient_fs.FileSystem, irodsDestPath strin
 	return manager
 }
 
-func (manager *BundleTransferManager) GetFilesystem() *irodsclient_fs.FileSystem {
-	return manager.filesystem
-}
-
-func (manager *BundleTransferManager) getNextBundleIndex() int64 {
-	idx := manager.nextBundleIndex
-	manager.nextBundleIndex++
-	return idx
-}
-
-func (manager *BundleTransferManager) progress(name string, processed int64, total int64, progressUnit progress.Units, errored bool) {
-	if manager.progressTrackerCallback != nil {
-		manager.progressTrackerCallback(name, processed, total, progressUnit, errored)
-	}
-}
-
 func (manager *BundleTransferManager) getTargetPath(localPath string) (string, error) {
 	relPath, err := filepath.Rel(manager.bundleRootPath, localPath)
 	if err != nil {
@@ -263,125 +103,97 @@ func (manager *BundleTransferManager) Schedule(source string, dir bool, size int
 		return manager.lastError
 	}
 
-	if manager.currentBundle != nil {
-		// if current bundle is full, prepare a new bundle
-		if manager.currentBundle.isFull() {
-			// temporarily release lock since adding to chan may block
-			manager.mutex.Unlock()
+	targePath, err := manager.getTargetPath(source)
+	if err != nil {
+		manager.mutex.Unlock()
+		return xerrors.Errorf("failed to get target path for %s: %w", source, err)
+	}
 
-			manager.pendingBundles <- manager.currentBundle
-			manager.bundles = append(manager.bundles, manager.currentBundle)
+	MarkPathMap(manager.inputPathMap, targePath)
+	manager.mutex.Unlock()
 
-			manager.mutex.Lock()
-			manager.currentBundle = nil
-			manager.transferWait.Add(1)
-		}
+	if manager.manifest.IsDone(source, targePath, dir, size) {
+		fmt.Printf("skip adding %s to the bundle. It was uploaded in the previous run!\n", source)
+		logger.Debugf("skip adding %s to the bundle. It was uploaded in the previous run!", source)
+		return nil
 	}
 
-	if manager.currentBundle == nil {
-		// add new
-		bundle, err := newBundle(manager)
-		if err != nil {
-			return xerrors.Errorf("failed to create a new bundle for %s: %w", source, err)
-		}
+	return manager.schedule(&bundleScheduleRequest{
+		source: source,
+		dir:    dir,
+		size:   size,
+	})
+}
 
-		manager.currentBundle = bundle
-		logger.Debugf("assigned a new bundle %d", manager.currentBundle.index)
-	}
+// isDifferent checks if the source is different from its target, stats and hashes are done without lock
+func (manager *BundleTransferManager) isDifferent(request *bundleScheduleRequest) (bool, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "BundleTransferManager",
+		"function": "isDifferent",
+	})
 
-	defer manager.mutex.Unlock()
-	targePath, err := manager.getTargetPath(source)
+	targePath, err := manager.getTargetPath(request.source)
 	if err != nil {
-		return xerrors.Errorf("failed to get target path for %s: %w", source, err)
+		return false, xerrors.Errorf("failed to get target path for %s: %w", request.source, err)
 	}
 
-	MarkPathMap(manager.inputPathMap, targePath)
-
-	if manager.differentFilesOnly {
-		logger.Debugf("checking if target %s for source %s exists", targePath, source)
-
-		if dir {
-			// handle dir
-			exist := manager.filesystem.ExistsDir(targePath)
-			if exist {
-				fmt.Printf("skip adding a dir %s to the bundle. The dir already exists!\n", source)
-				logger.Debugf("skip adding a dir %s to the bundle. The dir already exists!", source)
-				return nil
-			}
+	logger.Debugf("checking if target %s for source %s exists", targePath, request.source)
 
-			logger.Debugf("adding a dir %s to the bundle as it doesn't exist", source)
-		} else {
-			exist := manager.filesystem.ExistsFile(targePath)
-			if exist {
-				targetEntry, err := manager.filesystem.Stat(targePath)
-				if err != nil {
-					return xerrors.Errorf("failed to stat %s: %w", targePath, err)
-				}
+	if request.dir {
+		// handle dir
+		exist := manager.filesystem.ExistsDir(targePath)
+		if exist {
+			fmt.Printf("skip adding a dir %s to the bundle. The dir already exists!\n", request.source)
+			logger.Debugf("skip adding a dir %s to the bundle. The dir already exists!", request.source)
+			return false, nil
+		}
 
-				if manager.noHashForComparison {
-					if targetEntry.Size == size {
-						fmt.Printf("skip adding a file %s to the bundle. The file already exists!\n", source)
-						logger.Debugf("skip adding a file %s to the bundle. The file already exists!", source)
-						return nil
-					}
+		logger.Debugf("adding a dir %s to the bundle as it doesn't exist", request.source)
+		return true, nil
+	}
 
-					logger.Debugf("adding a file %s to the bundle as it has different size %d != %d", source, targetEntry.Size, size)
-				} else {
-					if targetEntry.Size == size {
-						if len(targetEntry.CheckSum) > 0 {
-							// compare hash
-							hash, err := HashLocalFile(source, targetEntry.CheckSumAlgorithm)
-							if err != nil {
-								return xerrors.Errorf("failed to get hash %s: %w", source, err)
-							}
+	exist := manager.filesystem.ExistsFile(targePath)
+	if !exist {
+		logger.Debugf("adding a file %s to the bundle as it doesn't exist", request.source)
+		return true, nil
+	}
 
-							if hash == targetEntry.CheckSum {
-								fmt.Printf("skip adding a file %s to the bundle. The file with the same hash already exists!\n", source)
-								logger.Debugf("skip adding a file %s to the bundle. The file with the same hash already exists!", source)
-								return nil
-							}
+	targetEntry, err := manager.filesystem.Stat(targePath)
+	if err != nil {
+		return false, xerrors.Errorf("failed to stat %s: %w", targePath, err)
+	}
 
-							logger.Debugf("adding a file %s to the bundle as it has different hash, %s vs %s (alg %s)", source, hash, targetEntry.CheckSum, targetEntry.CheckSumAlgorithm)
-						} else {
-							logger.Debugf("adding a file %s to the bundle as the file in iRODS doesn't have hash yet", source)
-						}
-					} else {
-						logger.Debugf("adding a file %s to the bundle as it has different size %d != %d", source, targetEntry.Size, size)
-					}
-				}
-			} else {
-				logger.Debugf("adding a file %s to the bundle as it doesn't exist", source)
-			}
-		}
+	if targetEntry.Size != request.size {
+		logger.Debugf("adding a file %s to the bundle as it has different size %d != %d", request.source, targetEntry.Size, request.size)
+		return true, nil
 	}
 
-	if dir {
-		manager.currentBundle.AddDir(source)
-		logger.Debugf("> scheduled a local file bundle-upload %s", source)
-		return nil
+	if manager.noHashForComparison {
+		fmt.Printf("skip adding a file %s to the bundle. The file already exists!\n", request.source)
+		logger.Debugf("skip adding a file %s to the bundle. The file already exists!", request.source)
+		return false, nil
 	}
 
-	manager.currentBundle.AddFile(source, size)
-	logger.Debugf("> scheduled a local file bundle-upload %s", source)
-	return nil
-}
+	if len(targetEntry.CheckSum) == 0 {
+		logger.Debugf("adding a file %s to the bundle as the file in iRODS doesn't have hash yet", request.source)
+		return true, nil
+	}
 
-func (manager *BundleTransferManager) DoneScheduling() {
-	manager.mutex.Lock()
-	if manager.currentBundle != nil {
-		manager.pendingBundles <- manager.currentBundle
-		manager.bundles = append(manager.bundles, manager.currentBundle)
-		manager.currentBundle = nil
-		manager.transferWait.Add(1)
+	// compare hash
+	hash, err := manager.hashCache.HashLocalFile(request.source, targetEntry.CheckSumAlgorithm)
+	if err != nil {
+		return false, xerrors.Errorf("failed to get hash %s: %w", request.source, err)
 	}
-	manager.mutex.Unlock()
 
-	close(manager.pendingBundles)
-	manager.scheduleWait.Done()
-}
+	if hash == targetEntry.CheckSum {
+		fmt.Printf("skip adding a file %s to the bundle. The file with the same hash already exists!\n", request.source)
+		logger.Debugf("skip adding a file %s to the bundle. The file with the same hash already exists!", request.source)
+		return false, nil
+	}
 
-func (manager *BundleTransferManager) GetBundles() []*Bundle {
-	return manager.bundles
+	logger.Debugf("adding a file %s to the bundle as it has different hash, %s vs %s (alg %s)", request.source, hash, targetEntry.CheckSum, targetEntry.CheckSumAlgorithm)
+	return true, nil
 }
 
 func (manager *BundleTransferManager) GetInputPathMap() map[string]bool {
@@ -395,20 +207,50 @@ func (manager *BundleTransferManager) Wait() error {
 		"function": "Wait",
 	})
 
-	logger.Debug("waiting schedule-wait")
-	manager.scheduleWait.Wait()
-	logger.Debug("waiting transfer-wait")
-	manager.transferWait.Wait()
+	manager.waitTransfers()
 
 	manager.CleanUpBundles()
 
 	manager.mutex.RLock()
 	defer manager.mutex.RUnlock()
+
+	if manager.lastError == nil && !manager.failureReport.HasFailures() {
+		// all bundles are done, nothing to resume
+		err := manager.manifest.Remove()
+		if err != nil {
+			logger.WithError(err).Warn("failed to remove manifest")
+		}
+	} else {
+		err := manager.manifest.Close()
+		if err != nil {
+			logger.WithError(err).Warn("failed to close manifest")
+		}
+	}
+
 	return manager.lastError
 }
 
-func (manager *BundleTransferManager) SetBundleRootPath(bundleRootPath string) {
-	manager.bundleRootPath = bundleRootPath
+// SetBundleFormat sets the format of bundle files, must be called before Schedule
+func (manager *BundleTransferManager) SetBundleFormat(format string) error {
+	_, err := GetBundleDataType(format)
+	if err != nil {
+		return err
+	}
+
+	manager.bundleFormat = format
+	return nil
+}
+
+// SetVerifyChecksum makes the manager verify checksums of uploaded files after extraction.
+// Files failed in verification are removed unless keepMismatched is set.
+func (manager *BundleTransferManager) SetVerifyChecksum(verifyChecksum bool, keepMismatched bool) {
+	manager.verifyChecksum = verifyChecksum
+	manager.keepMismatched = keepMismatched
+}
+
+// SetManifest makes the manager record files transferred to the manifest and skip files recorded in the previous run, nil disables resuming
+func (manager *BundleTransferManager) SetManifest(manifest *BundleManifest) {
+	manager.manifest = manifest
 }
 
 func (manager *BundleTransferManager) CleanUpBundles() {
@@ -475,70 +317,6 @@ func (manager *BundleTransferManager) CleanUpBundles() {
 	}
 }
 
-func (manager *BundleTransferManager) startProgress() {
-	if manager.showProgress {
-		manager.progressWriter = GetProgressWriter(false)
-		messageWidth := getProgressMessageWidth(false)
-
-		go manager.progressWriter.Render()
-
-		// add progress tracker callback
-		manager.progressTrackerCallback = func(name string, processed int64, total int64, progressUnit progress.Units, errored bool) {
-			manager.mutex.Lock()
-			defer manager.mutex.Unlock()
-
-			var tracker *progress.Tracker
-			if t, ok := manager.progressTrackers[name]; !ok {
-				// created a new tracker if not exists
-				msg := GetShortPathMessage(name, messageWidth)
-
-				tracker = &progress.Tracker{
-					Message: msg,
-					Total:   total,
-					Units:   progressUnit,
-				}
-
-				manager.progressWriter.AppendTracker(tracker)
-				manager.progressTrackers[name] = tracker
-			} else {
-				tracker = t
-			}
-
-			if processed >= 0 {
-				tracker.SetValue(processed)
-			}
-
-			if errored {
-				tracker.MarkAsErrored()
-			} else if processed >= total {
-				tracker.MarkAsDone()
-			}
-		}
-	}
-}
-
-func (manager *BundleTransferManager) endProgress() {
-	if manager.showProgress {
-		if manager.progressWriter != nil {
-			manager.mutex.Lock()
-
-			for _, tracker := range manager.progressTrackers {
-				if manager.lastError != nil {
-					tracker.MarkAsDone()
-				} else {
-					if !tracker.IsDone() {
-						tracker.MarkAsErrored()
-					}
-				}
-			}
-
-			manager.mutex.Unlock()
-
-			manager.progressWriter.Stop()
-		}
-	}
-}
-
 func (manager *BundleTransferManager) Start() {
 	logger := log.WithFields(log.Fields{
 		"package":  "commons",
@@ -554,8 +332,12 @@ func (manager *BundleTransferManager) Start() {
 
 	manager.startProgress()
 
-	// bundle --> tar --> upload                   --> extract
-	//        --> remove old files & make dirs ------>
+	if manager.differentFilesOnly {
+		manager.startCompare()
+	}
+
+	// compare (--diff) --> bundle --> tar --> upload                   --> extract
+	//                            --> remove old files & make dirs ------>
 
 	go func() {
 		logger.Debug("start input thread")
@@ -606,26 +388,10 @@ func (manager *BundleTransferManager) Start() {
 		defer close(processBundleUploadChan)
 
 		for bundle := range processBundleTarChan {
-			cont := true
-
-			manager.mutex.RLock()
-			if manager.lastError != nil {
-				cont = false
-			}
-			manager.mutex.RUnlock()
-
-			if cont && len(bundle.entries) > 0 {
+			if manager.canProcessBundle(bundle) {
 				err := manager.processBundleTar(bundle)
 				if err != nil {
-					// mark error
-					manager.mutex.Lock()
-					manager.lastError = err
-					manager.mutex.Unlock()
-
-					bundle.lastError = err
-					bundle.lastErrorTaskName = BundleTaskNameTar
-
-					logger.Error(err)
+					manager.markBundleError(bundle, BundleTaskNameTar, err)
 					// don't stop here
 				}
 			}
@@ -644,30 +410,7 @@ func (manager *BundleTransferManager) Start() {
 		for {
 			bundle, ok := <-processBundleUploadChan
 			if ok {
-				cont := true
-
-				manager.mutex.RLock()
-				if manager.lastError != nil {
-					cont = false
-				}
-				manager.mutex.RUnlock()
-
-				if cont && len(bundle.entries) > 0 {
-					err := manager.processBundleUpload(bundle)
-					if err != nil {
-						// mark error
-						manager.mutex.Lock()
-						manager.lastError = err
-						manager.mutex.Unlock()
-
-						bundle.lastError = err
-						bundle.lastErrorTaskName = BundleTaskNameUpload
-
-						logger.Error(err)
-						// don't stop here
-					}
-				}
-
+				manager.runBundleTask(bundle, BundleTaskNameUpload, manager.processBundleUpload)
 				processBundleExtractChan1 <- bundle
 			} else {
 				return
@@ -694,30 +437,7 @@ func (manager *BundleTransferManager) Start() {
 		defer close(processBundleExtractChan2)
 
 		for bundle := range processBundleRemoveFilesAndMakeDirsChan {
-			cont := true
-
-			manager.mutex.RLock()
-			if manager.lastError != nil {
-				cont = false
-			}
-			manager.mutex.RUnlock()
-
-			if cont && len(bundle.entries) > 0 {
-				err := manager.processBundleRemoveFilesAndMakeDirs(bundle)
-				if err != nil {
-					// mark error
-					manager.mutex.Lock()
-					manager.lastError = err
-					manager.mutex.Unlock()
-
-					bundle.lastError = err
-					bundle.lastErrorTaskName = BundleTaskNameRemoveFilesAndMakeDirs
-
-					logger.Error(err)
-					// don't stop here
-				}
-			}
-
+			manager.runBundleTask(bundle, BundleTaskNameRemoveFilesAndMakeDirs, manager.processBundleRemoveFilesAndMakeDirs)
 			processBundleExtractChan2 <- bundle
 		}
 	}()
@@ -743,29 +463,12 @@ func (manager *BundleTransferManager) Start() {
 						delete(removeTaskCompleted, bundle1.index)
 						removeTaskCompletedMutex.Unlock()
 
-						cont := true
-
-						manager.mutex.RLock()
-						if manager.lastError != nil {
-							cont = false
-						}
-						manager.mutex.RUnlock()
-
-						if cont && len(bundle1.entries) > 0 {
+						if manager.canProcessBundle(bundle1) {
 							err := manager.processBundleExtract(bundle1)
 							if err != nil {
-								// mark error
-								manager.mutex.Lock()
-								manager.lastError = err
-								manager.mutex.Unlock()
-
-								bundle1.lastError = err
-								bundle1.lastErrorTaskName = BundleTaskNameExtract
-
-								logger.Error(err)
+								manager.markBundleError(bundle1, BundleTaskNameExtract, err)
 								// don't stop here
 							}
-
 						} else {
 							if bundle1.requireTar() {
 								// remove irods bundle file
@@ -792,26 +495,10 @@ func (manager *BundleTransferManager) Start() {
 						delete(removeTaskCompleted, bundle2.index)
 						removeTaskCompletedMutex.Unlock()
 
-						cont := true
-
-						manager.mutex.RLock()
-						if manager.lastError != nil {
-							cont = false
-						}
-						manager.mutex.RUnlock()
-
-						if cont && len(bundle2.entries) > 0 {
+						if manager.canProcessBundle(bundle2) {
 							err := manager.processBundleExtract(bundle2)
 							if err != nil {
-								// mark error
-								manager.mutex.Lock()
-								manager.lastError = err
-								manager.mutex.Unlock()
-
-								bundle2.lastError = err
-								bundle2.lastErrorTaskName = BundleTaskNameExtract
-
-								logger.Error(err)
+								manager.markBundleError(bundle2, BundleTaskNameExtract, err)
 								// don't stop here
 							}
 						} else {
@@ -963,7 +650,12 @@ func (manager *BundleTransferManager) processBundleTar(bundle *Bundle) error {
 		entries[idx] = entry.LocalPath
 	}
 
-	err := Tar(manager.bundleRootPath, entries, bundle.localBundlePath, callback)
+	var hashAlgs []string
+	if manager.verifyChecksum {
+		hashAlgs = []string{GetUploadChecksumAlgorithm()}
+	}
+
+	hashes, err := ArchiveWithHash(manager.bundleRootPath, entries, bundle.localBundlePath, manager.bundleFormat, hashAlgs, callback)
 	if err != nil {
 		if manager.showProgress {
 			manager.progress(progressName, 0, totalFileNum, progress.UnitsDefault, true)
@@ -972,6 +664,12 @@ func (manager *BundleTransferManager) processBundleTar(bundle *Bundle) error {
 		return xerrors.Errorf("failed to create a tarball for bundle %d to %s: %w", bundle.index, bundle.localBundlePath, err)
 	}
 
+	for _, entry := range bundle.entries {
+		if entryHashes, ok := hashes[entry.LocalPath]; ok {
+			entry.Hashes = entryHashes
+		}
+	}
+
 	logger.Debugf("created a tarball for bundle %d to %s", bundle.index, bundle.localBundlePath)
 	return nil
 }
@@ -997,6 +695,8 @@ func (manager *BundleTransferManager) processBundleUpload(bundle *Bundle) error
 			}
 		}
 
+		callback = manager.rateLimiter.WrapCallback(callback, 0)
+
 		haveExistingBundle := false
 
 		bundleEntry, err := manager.filesystem.StatFile(bundle.irodsBundlePath)
@@ -1078,6 +778,8 @@ func (manager *BundleTransferManager) processBundleUpload(bundle *Bundle) error
 			}
 		}
 
+		callbackFileUpload = manager.rateLimiter.WrapCallback(callbackFileUpload, 0)
+
 		if !manager.filesystem.ExistsDir(path.Dir(file.IRODSPath)) {
 			// if parent dir does not exist, create
 			err := manager.filesystem.MakeDir(path.Dir(file.IRODSPath), true)
@@ -1086,7 +788,7 @@ func (manager *BundleTransferManager) processBundleUpload(bundle *Bundle) error
 					manager.progress(progressName, -1, totalFileSize, progress.UnitsBytes, true)
 				}
 
-				return xerrors.Errorf("failed to create a dir %s to upload file %s in bundle %d to %s: %w", path.Dir(file.IRODSPath), file.LocalPath, bundle.index, file.IRODSPath, err)
+				return newBundleEntryError(fileIdx, xerrors.Errorf("failed to create a dir %s to upload file %s in bundle %d to %s: %w", path.Dir(file.IRODSPath), file.LocalPath, bundle.index, file.IRODSPath, err))
 			}
 		}
 
@@ -1098,12 +800,26 @@ func (manager *BundleTransferManager) processBundleUpload(bundle *Bundle) error
 					manager.progress(progressName, -1, totalFileSize, progress.UnitsBytes, true)
 				}
 
-				return xerrors.Errorf("failed to upload dir %s in bundle %d to %s: %w", file.LocalPath, bundle.index, file.IRODSPath, err)
+				return newBundleEntryError(fileIdx, xerrors.Errorf("failed to upload dir %s in bundle %d to %s: %w", file.LocalPath, bundle.index, file.IRODSPath, err))
 			}
 
 			logger.Debugf("uploaded dir %s in bundle %d to %s", file.LocalPath, bundle.index, file.IRODSPath)
 		} else {
-			if manager.singleThreaded {
+			if manager.verifyChecksum {
+				// hash data read for the upload to not read the file again for verification
+				hashAlg := GetUploadChecksumAlgorithm()
+
+				var hash string
+				if manager.singleThreaded {
+					hash, err = UploadFileWithHash(manager.filesystem, file.LocalPath, file.IRODSPath, "", hashAlg, callbackFileUpload)
+				} else {
+					hash, err = UploadFileParallelResumable(manager.filesystem, file.LocalPath, file.IRODSPath, "", 0, manager.localTempDirPath, hashAlg, callbackFileUpload)
+				}
+
+				if err == nil {
+					file.Hashes = map[string]string{hashAlg: hash}
+				}
+			} else if manager.singleThreaded {
 				err = manager.filesystem.UploadFile(file.LocalPath, file.IRODSPath, "", false, callbackFileUpload)
 			} else {
 				err = manager.filesystem.UploadFileParallel(file.LocalPath, file.IRODSPath, "", 0, false, callbackFileUpload)
@@ -1114,7 +830,7 @@ func (manager *BundleTransferManager) processBundleUpload(bundle *Bundle) error
 					manager.progress(progressName, -1, totalFileSize, progress.UnitsBytes, true)
 				}
 
-				return xerrors.Errorf("failed to upload file %s in bundle %d to %s: %w", file.LocalPath, bundle.index, file.IRODSPath, err)
+				return newBundleEntryError(fileIdx, xerrors.Errorf("failed to upload file %s in bundle %d to %s: %w", file.LocalPath, bundle.index, file.IRODSPath, err))
 			}
 
 			logger.Debugf("uploaded file %s in bundle %d to %s", file.LocalPath, bundle.index, file.IRODSPath)
@@ -1144,6 +860,22 @@ func (manager *BundleTransferManager) processBundleExtract(bundle *Bundle) error
 
 	if !bundle.requireTar() {
 		// no tar, so pass this step
+		err := manager.retryPolicy.Run(progressName, func() error {
+			return manager.verifyBundleChecksums(bundle)
+		})
+		if err != nil {
+			if manager.showProgress {
+				manager.progress(progressName, -1, totalFileNum, progress.UnitsDefault, true)
+			}
+
+			return xerrors.Errorf("failed to verify checksums of files in bundle %d: %w", bundle.index, err)
+		}
+
+		err = manager.manifest.MarkDone(bundle.entries)
+		if err != nil {
+			return xerrors.Errorf("failed to record files in bundle %d to manifest: %w", bundle.index, err)
+		}
+
 		if manager.showProgress {
 			manager.progress(progressName, totalFileNum, totalFileNum, progress.UnitsDefault, false)
 		}
@@ -1152,7 +884,18 @@ func (manager *BundleTransferManager) processBundleExtract(bundle *Bundle) error
 		return nil
 	}
 
-	err := manager.filesystem.ExtractStructFile(bundle.irodsBundlePath, manager.irodsDestPath, "", irodsclient_types.TAR_FILE_DT, true, !manager.noBulkRegistration)
+	dataType, err := GetBundleDataType(manager.bundleFormat)
+	if err != nil {
+		if manager.showProgress {
+			manager.progress(progressName, -1, totalFileNum, progress.UnitsDefault, true)
+		}
+
+		return err
+	}
+
+	err = manager.retryPolicy.Run(progressName, func() error {
+		return manager.filesystem.ExtractStructFile(bundle.irodsBundlePath, manager.irodsDestPath, "", dataType, true, !manager.noBulkRegistration)
+	})
 	if err != nil {
 		if manager.showProgress {
 			manager.progress(progressName, -1, totalFileNum, progress.UnitsDefault, true)
@@ -1166,6 +909,22 @@ func (manager *BundleTransferManager) processBundleExtract(bundle *Bundle) error
 	logger.Debugf("removing bundle %d at %s", bundle.index, bundle.irodsBundlePath)
 	manager.filesystem.RemoveFile(bundle.irodsBundlePath, true)
 
+	err = manager.retryPolicy.Run(progressName, func() error {
+		return manager.verifyBundleChecksums(bundle)
+	})
+	if err != nil {
+		if manager.showProgress {
+			manager.progress(progressName, -1, totalFileNum, progress.UnitsDefault, true)
+		}
+
+		return xerrors.Errorf("failed to verify checksums of files in bundle %d: %w", bundle.index, err)
+	}
+
+	err = manager.manifest.MarkDone(bundle.entries)
+	if err != nil {
+		return xerrors.Errorf("failed to record files in bundle %d to manifest: %w", bundle.index, err)
+	}
+
 	if manager.showProgress {
 		manager.progress(progressName, totalFileNum, totalFileNum, progress.UnitsDefault, false)
 	}
@@ -1174,8 +933,66 @@ func (manager *BundleTransferManager) processBundleExtract(bundle *Bundle) error
 	return nil
 }
 
-func (manager *BundleTransferManager) getProgressName(bundle *Bundle, taskName string) string {
-	return fmt.Sprintf("bundle %d - %s", bundle.index, taskName)
+// verifyBundleChecksums compares checksums of uploaded files in the bundle with local files
+func (manager *BundleTransferManager) verifyBundleChecksums(bundle *Bundle) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "BundleTransferManager",
+		"function": "verifyBundleChecksums",
+	})
+
+	if !manager.verifyChecksum {
+		return nil
+	}
+
+	for _, file := range bundle.entries {
+		if file.Dir {
+			continue
+		}
+
+		logger.Debugf("verifying checksum of %s in bundle %d", file.IRODSPath, bundle.index)
+
+		err := VerifyLocalFileChecksumWithHashes(manager.filesystem, file.LocalPath, file.IRODSPath, file.Hashes)
+		if err != nil {
+			if IsChecksumMismatchError(err) && !manager.keepMismatched {
+				manager.filesystem.RemoveFile(file.IRODSPath, true)
+			}
+
+			return xerrors.Errorf("failed to verify checksum of %s: %w", file.IRODSPath, err)
+		}
+	}
+
+	return nil
+}
+
+// GetBundleDataType returns the data type to extract bundle files of the format in iRODS
+func GetBundleDataType(format string) (irodsclient_types.DataType, error) {
+	switch format {
+	case BundleFormatTar:
+		return irodsclient_types.TAR_FILE_DT, nil
+	case BundleFormatTgz:
+		return irodsclient_types.GZIP_TAR_DT, nil
+	case BundleFormatZip:
+		return irodsclient_types.ZIP_FILE_DT, nil
+	case BundleFormatTbz2:
+		return irodsclient_types.BZIP2_TAR_DT, nil
+	default:
+		return "", xerrors.Errorf("unknown bundle format %s", format)
+	}
+}
+
+// GetBundleFileExtension returns the extension of bundle files of the format
+func GetBundleFileExtension(format string) string {
+	switch format {
+	case BundleFormatTgz:
+		return ".tar.gz"
+	case BundleFormatTbz2:
+		return ".tar.bz2"
+	case BundleFormatZip:
+		return ".zip"
+	default:
+		return ".tar"
+	}
 }
 
 func CleanUpOldLocalBundles(localTempDirPath string, force bool) {
@@ -1195,8 +1012,8 @@ func CleanUpOldLocalBundles(localTempDirPath string, force bool) {
 
 	bundleEntries := []string{}
 	for _, entry := range entries {
-		// filter only bundle files
-		if GetFileExtension(entry.Name()) == ".tar" {
+		// filter only bundle files, manifests and upload status files
+		if IsBundleFilename(entry.Name()) || IsBundleManifestFilename(entry.Name()) || IsUploadStatusFilename(entry.Name()) {
 			fullPath := filepath.Join(localTempDirPath, entry.Name())
 			bundleEntries = append(bundleEntries, fullPath)
 		}
@@ -1261,10 +1078,21 @@ func CleanUpOldIRODSBundles(fs *irodsclient_fs.FileSystem, irodsTempDirPath stri
 		// filter only bundle files
 		if entry.Type == irodsclient_fs.FileEntry {
 			if IsBundleFilename(entry.Name) {
-				logger.Debugf("deleting old irods bundle %s", entry)
+				logger.Debugf("deleting old irods bundle %s", entry.Path)
 				removeErr := fs.RemoveFile(entry.Path, force)
 				if removeErr != nil {
-					logger.WithError(removeErr).Warnf("failed to remove old irods bundle %s", entry)
+					logger.WithError(removeErr).Warnf("failed to remove old irods bundle %s", entry.Path)
+				} else {
+					deletedCount++
+				}
+			}
+		} else if entry.Type == irodsclient_fs.DirectoryEntry {
+			// staging collections left by bundle downloads
+			if IsBundleStagingDirname(entry.Name) {
+				logger.Debugf("deleting old irods bundle staging collection %s", entry.Path)
+				removeErr := fs.RemoveDir(entry.Path, true, force)
+				if removeErr != nil {
+					logger.WithError(removeErr).Warnf("failed to remove old irods bundle staging collection %s", entry.Path)
 				} else {
 					deletedCount++
 				}
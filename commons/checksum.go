@@ -0,0 +1,206 @@
+package commons
+
+import (
+	"fmt"
+	"sync"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_common "github.com/cyverse/go-irodsclient/irods/common"
+	irodsclient_message "github.com/cyverse/go-irodsclient/irods/message"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"golang.org/x/xerrors"
+)
+
+// checksum keywords are not defined in go-irodsclient
+const (
+	forceChecksumKeyword  irodsclient_common.KeyWord = "forceChksum"
+	verifyChecksumKeyword irodsclient_common.KeyWord = "verifyChksum"
+	checksumAllKeyword    irodsclient_common.KeyWord = "ChksumAll"
+)
+
+var (
+	// uploadChecksumAlgorithm is the algorithm of checksums the server computed last, iRODS uses SHA-256 by default
+	uploadChecksumAlgorithm      string = string(irodsclient_types.ChecksumAlgorithmSHA256)
+	uploadChecksumAlgorithmMutex sync.Mutex
+)
+
+// GetUploadChecksumAlgorithm returns the algorithm of hashes computed while uploading files for checksum verification.
+// The server's hash scheme is not known to clients, so it is learned from checksums the server computed.
+func GetUploadChecksumAlgorithm() string {
+	uploadChecksumAlgorithmMutex.Lock()
+	defer uploadChecksumAlgorithmMutex.Unlock()
+
+	return uploadChecksumAlgorithm
+}
+
+func setUploadChecksumAlgorithm(algorithm irodsclient_types.ChecksumAlgorithm) {
+	if algorithm == irodsclient_types.ChecksumAlgorithmUnknown {
+		return
+	}
+
+	uploadChecksumAlgorithmMutex.Lock()
+	defer uploadChecksumAlgorithmMutex.Unlock()
+
+	uploadChecksumAlgorithm = string(algorithm)
+}
+
+// ComputeChecksum asks the server to compute and register checksums of all replicas of the data object.
+// If force is set, existing checksums are recomputed.
+func ComputeChecksum(fs *irodsclient_fs.FileSystem, path string, force bool) (*irodsclient_types.IRODSChecksum, error) {
+	request := irodsclient_message.NewIRODSMessageChecksumRequest(path, "")
+	request.AddKeyVal(checksumAllKeyword, "")
+	if force {
+		request.AddKeyVal(forceChecksumKeyword, "")
+	}
+
+	checksumString, err := requestChecksum(fs, request)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to compute checksum of %s: %w", path, err)
+	}
+
+	checksum, err := irodsclient_types.CreateIRODSChecksum(checksumString)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create iRODS checksum: %w", err)
+	}
+
+	setUploadChecksumAlgorithm(checksum.Algorithm)
+	return checksum, nil
+}
+
+// VerifyChecksum asks the server to verify replicas of the data object against their registered checksums.
+// All replicas are verified if resource is empty, only the replica in the resource otherwise.
+// Returns USER_CHKSUM_MISMATCH iRODS error if any replica does not match.
+func VerifyChecksum(fs *irodsclient_fs.FileSystem, path string, resource string) error {
+	request := irodsclient_message.NewIRODSMessageChecksumRequest(path, "")
+	if len(resource) > 0 {
+		request.AddKeyVal(irodsclient_common.RESC_NAME_KW, resource)
+	} else {
+		request.AddKeyVal(checksumAllKeyword, "")
+	}
+	request.AddKeyVal(verifyChecksumKeyword, "")
+
+	_, err := requestChecksum(fs, request)
+	if err != nil {
+		return xerrors.Errorf("failed to verify checksum of %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// IsChecksumMismatchError checks if the error is caused by checksum mismatch
+func IsChecksumMismatchError(err error) bool {
+	return irodsclient_types.GetIRODSErrorCode(err) == irodsclient_common.USER_CHKSUM_MISMATCH
+}
+
+// checksumResponse is a checksum response that tolerates an empty body.
+// The server returns no checksum string on verification.
+type checksumResponse struct {
+	Checksum string
+	Result   int
+}
+
+func (msg *checksumResponse) FromMessage(msgIn *irodsclient_message.IRODSMessage) error {
+	if msgIn.Body == nil {
+		return xerrors.Errorf("empty message body")
+	}
+
+	msg.Result = int(msgIn.Body.IntInfo)
+	if len(msgIn.Body.Message) == 0 {
+		return nil
+	}
+
+	response := irodsclient_message.IRODSMessageChecksumResponse{}
+	err := response.FromBytes(msgIn.Body.Message)
+	if err != nil {
+		return xerrors.Errorf("failed to get irods message from message body: %w", err)
+	}
+
+	msg.Checksum = response.Checksum
+	return nil
+}
+
+func requestChecksum(fs *irodsclient_fs.FileSystem, request *irodsclient_message.IRODSMessageChecksumRequest) (string, error) {
+	connection, err := fs.GetIOConnection()
+	if err != nil {
+		return "", xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnIOConnection(connection)
+
+	connection.Lock()
+	defer connection.Unlock()
+
+	response := checksumResponse{}
+	err = connection.Request(request, &response, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if response.Result < 0 {
+		return "", irodsclient_types.NewIRODSError(irodsclient_common.ErrorCode(response.Result))
+	}
+
+	return response.Checksum, nil
+}
+
+// VerifyLocalFileChecksum compares a hash of the local file with the checksum of the data object.
+// A checksum of the data object is computed and registered if it does not exist.
+// Returns USER_CHKSUM_MISMATCH iRODS error if they do not match.
+func VerifyLocalFileChecksum(fs *irodsclient_fs.FileSystem, localPath string, irodsPath string) error {
+	return VerifyLocalFileChecksumWithHashes(fs, localPath, irodsPath, nil)
+}
+
+// VerifyLocalFileChecksumWithHashes is the same as VerifyLocalFileChecksum, but uses the given hashes of the local file computed while transferring.
+// Hashes are a map of hash algorithm to hash in hex string, the hash of the algorithm of the checksum is used.
+// The local file is hashed again only if hashes do not have the algorithm.
+func VerifyLocalFileChecksumWithHashes(fs *irodsclient_fs.FileSystem, localPath string, irodsPath string, hashes map[string]string) error {
+	checksum, err := ComputeChecksum(fs, irodsPath, false)
+	if err != nil {
+		return xerrors.Errorf("failed to get checksum of %s: %w", irodsPath, err)
+	}
+
+	if len(checksum.Checksum) == 0 {
+		return xerrors.Errorf("failed to get checksum of %s, server returned no checksum", irodsPath)
+	}
+
+	hash := GetHashForAlgorithm(hashes, string(checksum.Algorithm))
+	if len(hash) == 0 {
+		hash, err = HashLocalFileHex(localPath, string(checksum.Algorithm))
+		if err != nil {
+			return xerrors.Errorf("failed to get hash of %s: %w", localPath, err)
+		}
+	}
+
+	if hash != checksum.GetChecksumString() {
+		return irodsclient_types.NewIRODSErrorWithString(irodsclient_common.USER_CHKSUM_MISMATCH, fmt.Sprintf("checksum of %s (%s) does not match checksum of %s (%s)", localPath, hash, irodsPath, checksum.GetChecksumString()))
+	}
+
+	return nil
+}
+
+// VerifyDataObjectChecksum compares checksums of two data objects, computing them if they do not exist.
+// Returns USER_CHKSUM_MISMATCH iRODS error if they do not match.
+func VerifyDataObjectChecksum(fs *irodsclient_fs.FileSystem, sourcePath string, targetPath string) error {
+	sourceChecksum, err := ComputeChecksum(fs, sourcePath, false)
+	if err != nil {
+		return xerrors.Errorf("failed to get checksum of %s: %w", sourcePath, err)
+	}
+
+	targetChecksum, err := ComputeChecksum(fs, targetPath, false)
+	if err != nil {
+		return xerrors.Errorf("failed to get checksum of %s: %w", targetPath, err)
+	}
+
+	if len(sourceChecksum.Checksum) == 0 || len(targetChecksum.Checksum) == 0 {
+		return xerrors.Errorf("failed to get checksums of %s and %s, server returned no checksum", sourcePath, targetPath)
+	}
+
+	if sourceChecksum.Algorithm != targetChecksum.Algorithm {
+		return xerrors.Errorf("failed to compare checksums of %s (%s) and %s (%s), checksum algorithms are different", sourcePath, sourceChecksum.Algorithm, targetPath, targetChecksum.Algorithm)
+	}
+
+	if sourceChecksum.GetChecksumString() != targetChecksum.GetChecksumString() {
+		return irodsclient_types.NewIRODSErrorWithString(irodsclient_common.USER_CHKSUM_MISMATCH, fmt.Sprintf("checksum of %s (%s) does not match checksum of %s (%s)", sourcePath, sourceChecksum.OriginalChecksum, targetPath, targetChecksum.OriginalChecksum))
+	}
+
+	return nil
+}
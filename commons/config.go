@@ -38,6 +38,9 @@ type Config struct {
 	EncryptionAlgorithm     string `yaml:"irods_encryption_algorithm,omitempty" envconfig:"IRODS_ENCRYPTION_ALGORITHM"`
 	EncryptionSaltSize      int    `yaml:"irods_encryption_salt_size,omitempty" envconfig:"IRODS_ENCRYPTION_SALT_SIZE"`
 	EncryptionNumHashRounds int    `yaml:"irods_encryption_num_hash_rounds,omitempty" envconfig:"IRODS_ENCRYPTION_NUM_HASH_ROUNDS"`
+
+	// transfer bandwidth limit used when --limit_rate is not given, e.g., 200MB
+	LimitRate string `yaml:"gocmd_limit_rate,omitempty" envconfig:"GOCMD_LIMIT_RATE"`
 }
 
 func GetDefaultConfig() *Config {
@@ -6,3 +6,7 @@ const (
 	TcpBufferSizeDefault       int    = 4 * 1024 * 1024
 	TcpBufferSizeStringDefault string = "4MB"
 )
+
+const (
+	ReadBufferSizeDefault int = 1024 * 1024
+)
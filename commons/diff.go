@@ -0,0 +1,352 @@
+package commons
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	"golang.org/x/xerrors"
+)
+
+// DiffType is a kind of difference between two entries
+type DiffType string
+
+const (
+	// DiffTypeOnlySource means the entry only exists in source
+	DiffTypeOnlySource DiffType = "only_source"
+	// DiffTypeOnlyTarget means the entry only exists in target
+	DiffTypeOnlyTarget DiffType = "only_target"
+	// DiffTypeType means one is a file and the other is a directory
+	DiffTypeType DiffType = "type"
+	// DiffTypeSize means sizes are different
+	DiffTypeSize DiffType = "size"
+	// DiffTypeChecksum means checksums are different
+	DiffTypeChecksum DiffType = "checksum"
+	// DiffTypeUnverified means sizes are the same but checksums could not be compared
+	DiffTypeUnverified DiffType = "unverified"
+)
+
+// DiffFile is a file or a directory to compare, either local or iRODS
+type DiffFile struct {
+	// Path is an absolute path
+	Path string
+	// RelPath is a path relative to the root of comparison, separated by "/"
+	RelPath string
+	Dir     bool
+	Size    int64
+	Local   bool
+	// ChecksumAlgorithm and Checksum are only for iRODS data-objects, Checksum is a hex string
+	ChecksumAlgorithm string
+	Checksum          string
+}
+
+// DiffEntry is a difference found
+type DiffEntry struct {
+	Type           DiffType `json:"type"`
+	RelPath        string   `json:"relPath"`
+	SourcePath     string   `json:"sourcePath,omitempty"`
+	TargetPath     string   `json:"targetPath,omitempty"`
+	SourceSize     int64    `json:"sourceSize"`
+	TargetSize     int64    `json:"targetSize"`
+	SourceChecksum string   `json:"sourceChecksum,omitempty"`
+	TargetChecksum string   `json:"targetChecksum,omitempty"`
+}
+
+// ListLocalDiffFiles returns the local file or all files and directories under the local directory
+func ListLocalDiffFiles(localPath string) ([]*DiffFile, error) {
+	files := []*DiffFile{}
+
+	err := filepath.Walk(localPath, func(walkPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return xerrors.Errorf("failed to walk %s: %w", walkPath, err)
+		}
+
+		relPath, err := filepath.Rel(localPath, walkPath)
+		if err != nil {
+			return xerrors.Errorf("failed to get relative path of %s: %w", walkPath, err)
+		}
+
+		file := &DiffFile{
+			Path:    walkPath,
+			RelPath: filepath.ToSlash(relPath),
+			Dir:     info.IsDir(),
+			Local:   true,
+		}
+
+		if !info.IsDir() {
+			file.Size = info.Size()
+		}
+
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to list local files in %s: %w", localPath, err)
+	}
+
+	return files, nil
+}
+
+// ListIRODSDiffFiles returns the data-object or all data-objects and collections under the collection.
+// The collection tree is listed with bulk queries.
+func ListIRODSDiffFiles(fs *irodsclient_fs.FileSystem, irodsPath string) ([]*DiffFile, error) {
+	entry, err := fs.Stat(irodsPath)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to stat %s: %w", irodsPath, err)
+	}
+
+	if entry.Type == irodsclient_fs.FileEntry {
+		return []*DiffFile{
+			{
+				Path:              entry.Path,
+				RelPath:           ".",
+				Size:              entry.Size,
+				ChecksumAlgorithm: entry.CheckSumAlgorithm,
+				Checksum:          entry.CheckSum,
+			},
+		}, nil
+	}
+
+	collections, err := SearchCollections(fs, irodsPath)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to search collections in %s: %w", irodsPath, err)
+	}
+
+	dataObjects, err := SearchDataObjects(fs, irodsPath, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to search data-objects in %s: %w", irodsPath, err)
+	}
+
+	files := []*DiffFile{}
+	for _, collection := range collections {
+		relPath := getDiffRelPath(irodsPath, collection.Path)
+		if len(relPath) == 0 {
+			continue
+		}
+
+		files = append(files, &DiffFile{
+			Path:    collection.Path,
+			RelPath: relPath,
+			Dir:     true,
+		})
+	}
+
+	for _, dataObject := range dataObjects {
+		relPath := getDiffRelPath(irodsPath, dataObject.Path)
+		if len(relPath) == 0 {
+			continue
+		}
+
+		file := &DiffFile{
+			Path:    dataObject.Path,
+			RelPath: relPath,
+			Size:    dataObject.Size,
+		}
+
+		// use a checksum of any replica
+		for _, replica := range dataObject.Replicas {
+			if replica.Checksum != nil && len(replica.Checksum.Checksum) > 0 {
+				file.ChecksumAlgorithm = replica.Checksum.GetChecksumAlgorithm()
+				file.Checksum = replica.Checksum.GetChecksumString()
+				break
+			}
+		}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// getDiffRelPath returns a relative path of the iRODS path from the root collection, empty if it is not under the root
+func getDiffRelPath(rootPath string, irodsPath string) string {
+	if irodsPath == rootPath {
+		return "."
+	}
+
+	prefix := rootPath + "/"
+	if rootPath == "/" {
+		prefix = "/"
+	}
+
+	if !strings.HasPrefix(irodsPath, prefix) {
+		return ""
+	}
+
+	return irodsPath[len(prefix):]
+}
+
+// DiffFiles compares source and target files with the same relative paths, it changes nothing.
+// Sizes are compared first, then checksums if noHash is not set. Local files are hashed with the algorithm of the iRODS checksum.
+// Returned differences are sorted by relative path.
+func DiffFiles(sourceFiles []*DiffFile, targetFiles []*DiffFile, noHash bool) ([]*DiffEntry, error) {
+	targetFileMap := map[string]*DiffFile{}
+	for _, targetFile := range targetFiles {
+		targetFileMap[targetFile.RelPath] = targetFile
+	}
+
+	diffs := []*DiffEntry{}
+	for _, sourceFile := range sourceFiles {
+		targetFile, ok := targetFileMap[sourceFile.RelPath]
+		if !ok {
+			diffs = append(diffs, &DiffEntry{
+				Type:       DiffTypeOnlySource,
+				RelPath:    sourceFile.RelPath,
+				SourcePath: sourceFile.Path,
+				SourceSize: sourceFile.Size,
+			})
+			continue
+		}
+
+		delete(targetFileMap, sourceFile.RelPath)
+
+		diff, err := diffFile(sourceFile, targetFile, noHash)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to compare %s and %s: %w", sourceFile.Path, targetFile.Path, err)
+		}
+
+		if diff != nil {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	for _, targetFile := range targetFileMap {
+		diffs = append(diffs, &DiffEntry{
+			Type:       DiffTypeOnlyTarget,
+			RelPath:    targetFile.RelPath,
+			TargetPath: targetFile.Path,
+			TargetSize: targetFile.Size,
+		})
+	}
+
+	sort.Slice(diffs, func(i int, j int) bool {
+		return diffs[i].RelPath < diffs[j].RelPath
+	})
+
+	return diffs, nil
+}
+
+// diffFile compares two files with the same relative path, returns nil if they are the same
+func diffFile(sourceFile *DiffFile, targetFile *DiffFile, noHash bool) (*DiffEntry, error) {
+	diff := &DiffEntry{
+		RelPath:    sourceFile.RelPath,
+		SourcePath: sourceFile.Path,
+		TargetPath: targetFile.Path,
+		SourceSize: sourceFile.Size,
+		TargetSize: targetFile.Size,
+	}
+
+	if sourceFile.Dir != targetFile.Dir {
+		diff.Type = DiffTypeType
+		return diff, nil
+	}
+
+	if sourceFile.Dir {
+		return nil, nil
+	}
+
+	if sourceFile.Size != targetFile.Size {
+		diff.Type = DiffTypeSize
+		return diff, nil
+	}
+
+	if noHash {
+		return nil, nil
+	}
+
+	// local files do not have checksums, follow the algorithm of the iRODS side
+	algorithm := sourceFile.ChecksumAlgorithm
+	if sourceFile.Local {
+		algorithm = targetFile.ChecksumAlgorithm
+	} else if !targetFile.Local && targetFile.ChecksumAlgorithm != algorithm {
+		diff.Type = DiffTypeUnverified
+		return diff, nil
+	}
+
+	if len(algorithm) == 0 {
+		// no checksum registered
+		diff.Type = DiffTypeUnverified
+		return diff, nil
+	}
+
+	sourceChecksum, err := getDiffFileChecksum(sourceFile, algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	targetChecksum, err := getDiffFileChecksum(targetFile, algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sourceChecksum) == 0 || len(targetChecksum) == 0 {
+		diff.Type = DiffTypeUnverified
+		return diff, nil
+	}
+
+	if sourceChecksum != targetChecksum {
+		diff.Type = DiffTypeChecksum
+		diff.SourceChecksum = sourceChecksum
+		diff.TargetChecksum = targetChecksum
+		return diff, nil
+	}
+
+	return nil, nil
+}
+
+func getDiffFileChecksum(file *DiffFile, algorithm string) (string, error) {
+	if !file.Local {
+		return file.Checksum, nil
+	}
+
+	hash, err := HashLocalFileHex(file.Path, algorithm)
+	if err != nil {
+		return "", xerrors.Errorf("failed to get hash for %s: %w", file.Path, err)
+	}
+
+	return hash, nil
+}
+
+// DifferencesFoundError is an error returned when differences are found, to exit with a non-zero status
+type DifferencesFoundError struct {
+	Count int
+}
+
+// NewDifferencesFoundError creates a new DifferencesFoundError
+func NewDifferencesFoundError(count int) error {
+	return &DifferencesFoundError{
+		Count: count,
+	}
+}
+
+// Error returns error message
+func (err *DifferencesFoundError) Error() string {
+	return fmt.Sprintf("found %d difference(s)", err.Count)
+}
+
+// Is tests type of error
+func (err *DifferencesFoundError) Is(other error) bool {
+	_, ok := other.(*DifferencesFoundError)
+	return ok
+}
+
+// IsDifferencesFoundError evaluates if the given error is DifferencesFoundError
+func IsDifferencesFoundError(err error) bool {
+	return errors.Is(err, &DifferencesFoundError{})
+}
+
+// CountDifferences counts entries that are different, unverified entries are not counted
+func CountDifferences(diffs []*DiffEntry) int {
+	count := 0
+	for _, diff := range diffs {
+		if diff.Type != DiffTypeUnverified {
+			count++
+		}
+	}
+
+	return count
+}
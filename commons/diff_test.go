@@ -0,0 +1,85 @@
+package commons
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestDiff(t *testing.T) {
+	t.Run("test DiffFiles", testDiffFiles)
+	t.Run("test CountDifferences", testCountDifferences)
+}
+
+func testDiffFiles(t *testing.T) {
+	localDir := t.TempDir()
+
+	content := []byte("hello world")
+	err := os.WriteFile(filepath.Join(localDir, "same.txt"), content, 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(localDir, "changed.txt"), content, 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(localDir, "local_only.txt"), content, 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(localDir, "no_checksum.txt"), content, 0644)
+	assert.NoError(t, err)
+
+	sum := md5.Sum(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	sourceFiles, err := ListLocalDiffFiles(localDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(sourceFiles))
+
+	size := int64(len(content))
+	targetFiles := []*DiffFile{
+		{Path: "/zone/c", RelPath: ".", Dir: true},
+		{Path: "/zone/c/same.txt", RelPath: "same.txt", Size: size, ChecksumAlgorithm: "MD5", Checksum: checksum},
+		{Path: "/zone/c/changed.txt", RelPath: "changed.txt", Size: size, ChecksumAlgorithm: "MD5", Checksum: "00"},
+		{Path: "/zone/c/no_checksum.txt", RelPath: "no_checksum.txt", Size: size},
+		{Path: "/zone/c/irods_only.txt", RelPath: "irods_only.txt", Size: 1},
+	}
+
+	diffs, err := DiffFiles(sourceFiles, targetFiles, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(diffs))
+	assert.Equal(t, "changed.txt", diffs[0].RelPath)
+	assert.Equal(t, DiffTypeChecksum, diffs[0].Type)
+	assert.Equal(t, checksum, diffs[0].SourceChecksum)
+	assert.Equal(t, "irods_only.txt", diffs[1].RelPath)
+	assert.Equal(t, DiffTypeOnlyTarget, diffs[1].Type)
+	assert.Equal(t, "local_only.txt", diffs[2].RelPath)
+	assert.Equal(t, DiffTypeOnlySource, diffs[2].Type)
+	assert.Equal(t, "no_checksum.txt", diffs[3].RelPath)
+	assert.Equal(t, DiffTypeUnverified, diffs[3].Type)
+
+	diffs, err = DiffFiles(sourceFiles, targetFiles, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(diffs))
+
+	targetFiles[1].Size = 1
+	diffs, err = DiffFiles(sourceFiles, targetFiles, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(diffs))
+	assert.Equal(t, DiffTypeSize, diffs[2].Type)
+}
+
+func testCountDifferences(t *testing.T) {
+	diffs := []*DiffEntry{
+		{Type: DiffTypeOnlySource, RelPath: "a.txt"},
+		{Type: DiffTypeChecksum, RelPath: "b.txt"},
+		{Type: DiffTypeUnverified, RelPath: "c.txt"},
+	}
+
+	assert.Equal(t, 2, CountDifferences(diffs))
+	assert.Equal(t, 0, CountDifferences(diffs[2:]))
+
+	err := xerrors.Errorf("failed to diff: %w", NewDifferencesFoundError(2))
+	assert.True(t, IsDifferencesFoundError(err))
+	assert.False(t, IsDifferencesFoundError(xerrors.Errorf("failed to diff")))
+}
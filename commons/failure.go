@@ -0,0 +1,249 @@
+package commons
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// failure phases, bundle tasks use their task names
+const (
+	FailurePhaseScan     string = "Scanning"
+	FailurePhaseCompare  string = "Comparing"
+	FailurePhaseTransfer string = "Transferring"
+)
+
+// TransferFailure is a failure of a file transfer
+type TransferFailure struct {
+	Path  string
+	Phase string
+	Error error
+}
+
+// FailureReport collects failures of file transfers to continue on errors
+type FailureReport struct {
+	failures []*TransferFailure
+	mutex    sync.Mutex
+}
+
+// NewFailureReport creates a new FailureReport
+func NewFailureReport() *FailureReport {
+	return &FailureReport{
+		failures: []*TransferFailure{},
+		mutex:    sync.Mutex{},
+	}
+}
+
+// Add records a failure
+func (report *FailureReport) Add(path string, phase string, err error) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	report.failures = append(report.failures, &TransferFailure{
+		Path:  path,
+		Phase: phase,
+		Error: err,
+	})
+}
+
+// Continue records the error and returns nil to continue. A nil report returns the error to stop.
+func (report *FailureReport) Continue(path string, phase string, err error) error {
+	if report == nil || err == nil {
+		return err
+	}
+
+	report.Add(path, phase, err)
+	return nil
+}
+
+// GetFailures returns failures recorded
+func (report *FailureReport) GetFailures() []*TransferFailure {
+	if report == nil {
+		return nil
+	}
+
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	failures := make([]*TransferFailure, len(report.failures))
+	copy(failures, report.failures)
+	return failures
+}
+
+// HasFailures checks if any failure is recorded
+func (report *FailureReport) HasFailures() bool {
+	if report == nil {
+		return false
+	}
+
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	return len(report.failures) > 0
+}
+
+// PrintSummary prints failures recorded
+func (report *FailureReport) PrintSummary(writer io.Writer) {
+	failures := report.GetFailures()
+	if len(failures) == 0 {
+		return
+	}
+
+	fmt.Fprintf(writer, "%d failure(s):\n", len(failures))
+	for _, failure := range failures {
+		fmt.Fprintf(writer, "  [%s] %s: %v\n", failure.Phase, failure.Path, failure.Error)
+	}
+}
+
+// WriteFailedPaths writes paths failed to the file, one path per line
+func (report *FailureReport) WriteFailedPaths(listPath string) error {
+	failures := report.GetFailures()
+
+	paths := []string{}
+	pathMap := map[string]bool{}
+	for _, failure := range failures {
+		if _, ok := pathMap[failure.Path]; ok {
+			continue
+		}
+
+		pathMap[failure.Path] = true
+		paths = append(paths, failure.Path)
+	}
+
+	content := ""
+	if len(paths) > 0 {
+		content = strings.Join(paths, "\n") + "\n"
+	}
+
+	err := os.WriteFile(listPath, []byte(content), 0644)
+	if err != nil {
+		return xerrors.Errorf("failed to write failed paths to %s: %w", listPath, err)
+	}
+
+	return nil
+}
+
+// ReadPathList reads paths from the file, one path per line. Empty lines are ignored.
+func ReadPathList(listPath string) ([]string, error) {
+	listFile, err := os.Open(listPath)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open %s: %w", listPath, err)
+	}
+	defer listFile.Close()
+
+	paths := []string{}
+	scanner := bufio.NewScanner(listFile)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		paths = append(paths, line)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read %s: %w", listPath, err)
+	}
+
+	return paths, nil
+}
+
+// PathFilter limits files to transfer to the listed paths, everything under listed dirs is included
+type PathFilter struct {
+	local   bool
+	paths   map[string]bool
+	parents map[string]bool
+}
+
+// NewPathFilter creates a new PathFilter for iRODS paths, paths must be absolute
+func NewPathFilter(paths []string) *PathFilter {
+	return newPathFilter(paths, false)
+}
+
+// NewLocalPathFilter creates a new PathFilter for local paths, paths must be absolute
+func NewLocalPathFilter(paths []string) *PathFilter {
+	return newPathFilter(paths, true)
+}
+
+func newPathFilter(paths []string, local bool) *PathFilter {
+	filter := &PathFilter{
+		local:   local,
+		paths:   map[string]bool{},
+		parents: map[string]bool{},
+	}
+
+	for _, p := range paths {
+		p = filter.clean(p)
+		filter.paths[p] = true
+
+		for parent := filter.dir(p); ; parent = filter.dir(parent) {
+			filter.parents[parent] = true
+			if filter.dir(parent) == parent {
+				break
+			}
+		}
+	}
+
+	return filter
+}
+
+// clean cleans the path, local paths use OS-specific separators
+func (filter *PathFilter) clean(p string) string {
+	if filter.local {
+		return filepath.Clean(p)
+	}
+
+	return path.Clean(p)
+}
+
+// dir returns the parent dir of the path, the root is the parent of itself
+func (filter *PathFilter) dir(p string) string {
+	if filter.local {
+		return filepath.Dir(p)
+	}
+
+	return path.Dir(p)
+}
+
+// Match checks if the file is listed or under a listed dir. A nil filter matches all.
+func (filter *PathFilter) Match(p string) bool {
+	if filter == nil {
+		return true
+	}
+
+	p = filter.clean(p)
+	for {
+		if _, ok := filter.paths[p]; ok {
+			return true
+		}
+
+		parent := filter.dir(p)
+		if parent == p {
+			return false
+		}
+
+		p = parent
+	}
+}
+
+// MatchDir checks if the dir may have files matching. A nil filter matches all.
+func (filter *PathFilter) MatchDir(p string) bool {
+	if filter == nil {
+		return true
+	}
+
+	if _, ok := filter.parents[filter.clean(p)]; ok {
+		return true
+	}
+
+	return filter.Match(p)
+}
@@ -0,0 +1,85 @@
+package commons
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestFailure(t *testing.T) {
+	t.Run("test FailureReport", testFailureReport)
+	t.Run("test PathFilter", testPathFilter)
+	t.Run("test LocalPathFilter", testLocalPathFilter)
+}
+
+func testFailureReport(t *testing.T) {
+	// nil report stops on errors
+	var nilReport *FailureReport
+	assert.Error(t, nilReport.Continue("/local/a.txt", FailurePhaseTransfer, xerrors.Errorf("failed")))
+	assert.False(t, nilReport.HasFailures())
+
+	report := NewFailureReport()
+	assert.NoError(t, report.Continue("/local/a.txt", FailurePhaseTransfer, nil))
+	assert.False(t, report.HasFailures())
+
+	assert.NoError(t, report.Continue("/local/a.txt", FailurePhaseCompare, xerrors.Errorf("failed to compare")))
+	assert.NoError(t, report.Continue("/local/a.txt", FailurePhaseTransfer, xerrors.Errorf("failed to upload")))
+	assert.NoError(t, report.Continue("/local/dir/b.txt", FailurePhaseScan, xerrors.Errorf("failed to stat")))
+	assert.True(t, report.HasFailures())
+	assert.Len(t, report.GetFailures(), 3)
+
+	buf := &bytes.Buffer{}
+	report.PrintSummary(buf)
+	assert.Contains(t, buf.String(), "3 failure(s)")
+	assert.Contains(t, buf.String(), "[Scanning] /local/dir/b.txt: failed to stat")
+
+	// failed paths are written once, and read back
+	listPath := filepath.Join(t.TempDir(), "failed.txt")
+	err := report.WriteFailedPaths(listPath)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(listPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "/local/a.txt\n/local/dir/b.txt\n", string(content))
+
+	paths, err := ReadPathList(listPath)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/local/a.txt", "/local/dir/b.txt"}, paths)
+}
+
+func testPathFilter(t *testing.T) {
+	var nilFilter *PathFilter
+	assert.True(t, nilFilter.Match("/zone/home/user/a.txt"))
+	assert.True(t, nilFilter.MatchDir("/zone/home/user"))
+
+	filter := NewPathFilter([]string{"/zone/home/user/dir1/a.txt", "/zone/home/user/dir2/"})
+
+	assert.True(t, filter.Match("/zone/home/user/dir1/a.txt"))
+	assert.False(t, filter.Match("/zone/home/user/dir1/b.txt"))
+	assert.True(t, filter.Match("/zone/home/user/dir2/sub/c.txt"))
+	assert.False(t, filter.Match("/zone/home/user/dir3/c.txt"))
+
+	assert.True(t, filter.MatchDir("/zone/home/user"))
+	assert.True(t, filter.MatchDir("/zone/home/user/dir1"))
+	assert.True(t, filter.MatchDir("/zone/home/user/dir2/sub"))
+	assert.False(t, filter.MatchDir("/zone/home/user/dir3"))
+}
+
+func testLocalPathFilter(t *testing.T) {
+	dir1 := filepath.Join(string(filepath.Separator), "data", "dir1")
+	dir2 := filepath.Join(string(filepath.Separator), "data", "dir2")
+
+	filter := NewLocalPathFilter([]string{filepath.Join(dir1, "a.txt"), dir2 + string(filepath.Separator)})
+
+	assert.True(t, filter.Match(filepath.Join(dir1, "a.txt")))
+	assert.False(t, filter.Match(filepath.Join(dir1, "b.txt")))
+	assert.True(t, filter.Match(filepath.Join(dir2, "sub", "c.txt")))
+
+	assert.True(t, filter.MatchDir(filepath.Dir(dir1)))
+	assert.True(t, filter.MatchDir(dir1))
+	assert.False(t, filter.MatchDir(filepath.Join(filepath.Dir(dir1), "dir3")))
+}
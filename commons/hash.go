@@ -130,3 +130,90 @@ func hashLocalFile(sourcePath string, hashAlg hash.Hash) ([]byte, error) {
 	sumBytes := hashAlg.Sum(nil)
 	return sumBytes, nil
 }
+
+// NewHashFunc returns a hash function for the hash algorithm
+func NewHashFunc(hashAlg string) (hash.Hash, error) {
+	switch strings.ToLower(hashAlg) {
+	case strings.ToLower(string(types.ChecksumAlgorithmMD5)):
+		return md5.New(), nil
+	case strings.ToLower(string(types.ChecksumAlgorithmADLER32)):
+		return adler32.New(), nil
+	case strings.ToLower(string(types.ChecksumAlgorithmSHA1)):
+		return sha1.New(), nil
+	case strings.ToLower(string(types.ChecksumAlgorithmSHA256)):
+		return sha256.New(), nil
+	case strings.ToLower(string(types.ChecksumAlgorithmSHA512)):
+		return sha512.New(), nil
+	default:
+		return nil, xerrors.Errorf("unknown hash algorithm %s", hashAlg)
+	}
+}
+
+// HashLocalFileHex returns a hash of the local file in hex string.
+// Checksums of iRODS data-objects in fs.Entry are hex strings for all algorithms, so this is used for comparison.
+func HashLocalFileHex(sourcePath string, hashAlg string) (string, error) {
+	hashFunc, err := NewHashFunc(hashAlg)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := hashLocalFile(sourcePath, hashFunc)
+	if err != nil {
+		return "", xerrors.Errorf("failed to hash local file %s with alg %s: %w", sourcePath, hashAlg, err)
+	}
+
+	return hex.EncodeToString(hash), nil
+}
+
+// MultiHash computes hashes of multiple algorithms in a single read
+type MultiHash struct {
+	hashFuncs map[string]hash.Hash
+}
+
+// NewMultiHash creates a new MultiHash for the hash algorithms
+func NewMultiHash(hashAlgs []string) (*MultiHash, error) {
+	hashFuncs := map[string]hash.Hash{}
+	for _, hashAlg := range hashAlgs {
+		hashFunc, err := NewHashFunc(hashAlg)
+		if err != nil {
+			return nil, err
+		}
+
+		hashFuncs[hashAlg] = hashFunc
+	}
+
+	return &MultiHash{
+		hashFuncs: hashFuncs,
+	}, nil
+}
+
+// Write writes data to all hash functions
+func (multiHash *MultiHash) Write(p []byte) (int, error) {
+	for _, hashFunc := range multiHash.hashFuncs {
+		// hash.Hash never returns an error
+		hashFunc.Write(p)
+	}
+
+	return len(p), nil
+}
+
+// Sums returns a map of hash algorithm to hash in hex string
+func (multiHash *MultiHash) Sums() map[string]string {
+	sums := map[string]string{}
+	for hashAlg, hashFunc := range multiHash.hashFuncs {
+		sums[hashAlg] = hex.EncodeToString(hashFunc.Sum(nil))
+	}
+
+	return sums
+}
+
+// GetHashForAlgorithm returns the hash of the algorithm in hashes, a map of hash algorithm to hash, empty if not found
+func GetHashForAlgorithm(hashes map[string]string, hashAlg string) string {
+	for alg, hash := range hashes {
+		if strings.EqualFold(alg, hashAlg) {
+			return hash
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,214 @@
+package commons
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/xerrors"
+)
+
+const (
+	HashCacheFilePathDefault string = "~/.irods/gocommands_hash_cache.json"
+)
+
+// hashCacheEntry has hashes of a local file, hashes are valid while size, modification time and inode are the same
+type hashCacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	Inode   uint64 `json:"inode"`
+	// Hashes is a map of hash algorithm to hash in hex string
+	Hashes map[string]string `json:"hashes"`
+}
+
+// HashCache is a persistent cache of local file hashes, to not rehash unchanged files
+type HashCache struct {
+	path    string
+	entries map[string]*hashCacheEntry
+	dirty   bool
+	mutex   sync.Mutex
+}
+
+// NewHashCache creates a new HashCache, loading existing entries from the cache file if it exists
+func NewHashCache(cacheFilePath string) (*HashCache, error) {
+	if len(cacheFilePath) == 0 {
+		cacheFilePath = HashCacheFilePathDefault
+	}
+
+	cacheFilePath, err := ExpandHomeDir(cacheFilePath)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to expand home dir for %s: %w", cacheFilePath, err)
+	}
+
+	cache := &HashCache{
+		path:    cacheFilePath,
+		entries: map[string]*hashCacheEntry{},
+		dirty:   false,
+		mutex:   sync.Mutex{},
+	}
+
+	cacheBytes, err := os.ReadFile(cacheFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+
+		return nil, xerrors.Errorf("failed to read hash cache file %s: %w", cacheFilePath, err)
+	}
+
+	err = json.Unmarshal(cacheBytes, &cache.entries)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse hash cache file %s: %w", cacheFilePath, err)
+	}
+
+	return cache, nil
+}
+
+// GetPath returns the path of the cache file
+func (cache *HashCache) GetPath() string {
+	return cache.path
+}
+
+// HashLocalFile returns a hash of the local file in hex string, the same as HashLocalFileHex.
+// Hashes are hex strings for all algorithms to compare them with checksums of data-objects listed by iRODS,
+// unlike HashLocalFile of the package that returns base64 strings for SHA algorithms.
+// A cached hash is returned if the file has not changed. A nil cache always computes the hash.
+func (cache *HashCache) HashLocalFile(localPath string, hashAlg string) (string, error) {
+	if cache == nil {
+		return HashLocalFileHex(localPath, hashAlg)
+	}
+
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "HashCache",
+		"function": "HashLocalFile",
+	})
+
+	absLocalPath, err := filepath.Abs(localPath)
+	if err != nil {
+		return "", xerrors.Errorf("failed to get absolute path of %s: %w", localPath, err)
+	}
+
+	stat, err := os.Stat(absLocalPath)
+	if err != nil {
+		return "", xerrors.Errorf("failed to stat %s: %w", absLocalPath, err)
+	}
+
+	hashAlgKey := strings.ToLower(hashAlg)
+	size := stat.Size()
+	modTime := stat.ModTime().UnixNano()
+	inode := getInode(stat)
+
+	cache.mutex.Lock()
+	entry, ok := cache.entries[absLocalPath]
+	if ok && entry.Size == size && entry.ModTime == modTime && entry.Inode == inode {
+		if hash, ok := entry.Hashes[hashAlgKey]; ok {
+			cache.mutex.Unlock()
+			logger.Debugf("use cached hash of %s", absLocalPath)
+			return hash, nil
+		}
+	}
+	cache.mutex.Unlock()
+
+	hash, err := HashLocalFileHex(absLocalPath, hashAlg)
+	if err != nil {
+		return "", err
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, ok = cache.entries[absLocalPath]
+	if !ok || entry.Size != size || entry.ModTime != modTime || entry.Inode != inode {
+		// new or changed file, drop stale hashes
+		entry = &hashCacheEntry{
+			Size:    size,
+			ModTime: modTime,
+			Inode:   inode,
+			Hashes:  map[string]string{},
+		}
+		cache.entries[absLocalPath] = entry
+	}
+
+	entry.Hashes[hashAlgKey] = hash
+	cache.dirty = true
+
+	return hash, nil
+}
+
+// Save writes the cache to the cache file if it has changed
+func (cache *HashCache) Save() error {
+	if cache == nil {
+		return nil
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if !cache.dirty {
+		return nil
+	}
+
+	cacheBytes, err := json.Marshal(cache.entries)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal hash cache: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(cache.path), 0700)
+	if err != nil {
+		return xerrors.Errorf("failed to make dir %s: %w", filepath.Dir(cache.path), err)
+	}
+
+	// write to a temp file and rename to not break the cache file on failure
+	// the temp file is unique, as other runs may save the cache at the same time
+	tempFile, err := os.CreateTemp(filepath.Dir(cache.path), filepath.Base(cache.path)+".*.tmp")
+	if err != nil {
+		return xerrors.Errorf("failed to create a temp file for hash cache file %s: %w", cache.path, err)
+	}
+
+	tempPath := tempFile.Name()
+
+	_, err = tempFile.Write(cacheBytes)
+	if err != nil {
+		tempFile.Close()
+		os.Remove(tempPath)
+		return xerrors.Errorf("failed to write hash cache file %s: %w", tempPath, err)
+	}
+
+	err = tempFile.Close()
+	if err != nil {
+		os.Remove(tempPath)
+		return xerrors.Errorf("failed to close hash cache file %s: %w", tempPath, err)
+	}
+
+	err = os.Rename(tempPath, cache.path)
+	if err != nil {
+		os.Remove(tempPath)
+		return xerrors.Errorf("failed to rename %s to %s: %w", tempPath, cache.path, err)
+	}
+
+	cache.dirty = false
+	return nil
+}
+
+// Prune drops entries of files that no longer exist, so the cache does not grow with removed files.
+// This stats all cached files, so it is only done on demand.
+func (cache *HashCache) Prune() {
+	if cache == nil {
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for localPath := range cache.entries {
+		_, err := os.Stat(localPath)
+		if err != nil && os.IsNotExist(err) {
+			delete(cache.entries, localPath)
+			cache.dirty = true
+		}
+	}
+}
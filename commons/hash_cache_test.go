@@ -0,0 +1,114 @@
+package commons
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashCache(t *testing.T) {
+	t.Run("test HashCache", testHashCache)
+	t.Run("test HashCachePrune", testHashCachePrune)
+}
+
+func testHashCache(t *testing.T) {
+	tempDir := t.TempDir()
+	cachePath := filepath.Join(tempDir, "cache", "hash_cache.json")
+	filePath := filepath.Join(tempDir, "file.txt")
+
+	err := os.WriteFile(filePath, []byte("hello world"), 0644)
+	assert.NoError(t, err)
+
+	cache, err := NewHashCache(cachePath)
+	assert.NoError(t, err)
+
+	expected, err := HashLocalFileHex(filePath, "MD5")
+	assert.NoError(t, err)
+
+	hash, err := cache.HashLocalFile(filePath, "MD5")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, hash)
+
+	err = cache.Save()
+	assert.NoError(t, err)
+
+	// reload and check the cached hash is used, the content is changed but the size and mtime are kept
+	stat, err := os.Stat(filePath)
+	assert.NoError(t, err)
+	err = os.WriteFile(filePath, []byte("HELLO WORLD"), 0644)
+	assert.NoError(t, err)
+	err = os.Chtimes(filePath, stat.ModTime(), stat.ModTime())
+	assert.NoError(t, err)
+
+	cache, err = NewHashCache(cachePath)
+	assert.NoError(t, err)
+
+	hash, err = cache.HashLocalFile(filePath, "MD5")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, hash)
+
+	// modified file is rehashed
+	newTime := stat.ModTime().Add(time.Minute)
+	err = os.Chtimes(filePath, newTime, newTime)
+	assert.NoError(t, err)
+
+	hash, err = cache.HashLocalFile(filePath, "MD5")
+	assert.NoError(t, err)
+	assert.NotEqual(t, expected, hash)
+
+	// nil cache always computes
+	var nilCache *HashCache
+	hash, err = nilCache.HashLocalFile(filePath, "MD5")
+	assert.NoError(t, err)
+	assert.NotEqual(t, expected, hash)
+}
+
+func testHashCachePrune(t *testing.T) {
+	tempDir := t.TempDir()
+	cachePath := filepath.Join(tempDir, "hash_cache.json")
+	filePath1 := filepath.Join(tempDir, "file1.txt")
+	filePath2 := filepath.Join(tempDir, "file2.txt")
+
+	err := os.WriteFile(filePath1, []byte("hello world"), 0644)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filePath2, []byte("hello"), 0644)
+	assert.NoError(t, err)
+
+	cache, err := NewHashCache(cachePath)
+	assert.NoError(t, err)
+
+	_, err = cache.HashLocalFile(filePath1, "MD5")
+	assert.NoError(t, err)
+
+	_, err = cache.HashLocalFile(filePath2, "MD5")
+	assert.NoError(t, err)
+
+	// removed files are kept unless pruned
+	err = os.Remove(filePath2)
+	assert.NoError(t, err)
+
+	err = cache.Save()
+	assert.NoError(t, err)
+
+	cache, err = NewHashCache(cachePath)
+	assert.NoError(t, err)
+	assert.Len(t, cache.entries, 2)
+
+	cache.Prune()
+	err = cache.Save()
+	assert.NoError(t, err)
+
+	cache, err = NewHashCache(cachePath)
+	assert.NoError(t, err)
+	assert.Len(t, cache.entries, 1)
+	assert.Contains(t, cache.entries, filePath1)
+
+	// no temp files are left
+	entries, err := os.ReadDir(tempDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
@@ -0,0 +1,40 @@
+package commons
+
+import (
+	"testing"
+
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash(t *testing.T) {
+	t.Run("test GetHashForAlgorithm", testGetHashForAlgorithm)
+	t.Run("test UploadChecksumAlgorithm", testUploadChecksumAlgorithm)
+}
+
+func testGetHashForAlgorithm(t *testing.T) {
+	hashes := map[string]string{
+		"SHA-256": "abcd",
+	}
+
+	// the algorithm of a server checksum may be in different case
+	assert.Equal(t, "abcd", GetHashForAlgorithm(hashes, "sha-256"))
+	assert.Equal(t, "", GetHashForAlgorithm(hashes, "SHA-512"))
+}
+
+func testUploadChecksumAlgorithm(t *testing.T) {
+	defer setUploadChecksumAlgorithm(irodsclient_types.ChecksumAlgorithmSHA256)
+
+	assert.Equal(t, string(irodsclient_types.ChecksumAlgorithmSHA256), GetUploadChecksumAlgorithm())
+
+	// the algorithm is learned from checksums the server computed
+	setUploadChecksumAlgorithm(irodsclient_types.ChecksumAlgorithmMD5)
+	assert.Equal(t, string(irodsclient_types.ChecksumAlgorithmMD5), GetUploadChecksumAlgorithm())
+
+	setUploadChecksumAlgorithm(irodsclient_types.ChecksumAlgorithmUnknown)
+	assert.Equal(t, string(irodsclient_types.ChecksumAlgorithmMD5), GetUploadChecksumAlgorithm())
+
+	// hashes are computed with the learned algorithm
+	_, err := NewHashFunc(GetUploadChecksumAlgorithm())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package commons
+
+import (
+	"os"
+	"syscall"
+)
+
+// getInode returns an inode number of the file, 0 if unavailable
+func getInode(stat os.FileInfo) uint64 {
+	if sysStat, ok := stat.Sys().(*syscall.Stat_t); ok {
+		return uint64(sysStat.Ino)
+	}
+	return 0
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package commons
+
+import (
+	"os"
+)
+
+// getInode returns an inode number of the file, Windows does not provide it through os.FileInfo
+func getInode(stat os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,187 @@
+package commons
+
+import (
+	"fmt"
+	"strconv"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_common "github.com/cyverse/go-irodsclient/irods/common"
+	irodsclient_message "github.com/cyverse/go-irodsclient/irods/message"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"golang.org/x/xerrors"
+)
+
+// resource metadata columns are not defined in go-irodsclient
+const (
+	icatColumnMetaResourceAttrName  irodsclient_common.ICATColumnNumber = 630
+	icatColumnMetaResourceAttrValue irodsclient_common.ICATColumnNumber = 631
+	icatColumnMetaResourceAttrUnits irodsclient_common.ICATColumnNumber = 632
+	icatColumnMetaResourceAttrID    irodsclient_common.ICATColumnNumber = 633
+)
+
+// ListResourceMetadata lists metadata of the given resource
+func ListResourceMetadata(fs *irodsclient_fs.FileSystem, resource string) ([]*irodsclient_types.IRODSMeta, error) {
+	connection, err := fs.GetMetadataConnection()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnMetadataConnection(connection)
+
+	connection.Lock()
+	defer connection.Unlock()
+
+	metas := []*irodsclient_types.IRODSMeta{}
+
+	continueIndex := 0
+	for {
+		query := irodsclient_message.NewIRODSMessageQueryRequest(irodsclient_common.MaxQueryRows, continueIndex, 0, 0)
+		query.AddSelect(icatColumnMetaResourceAttrID, 1)
+		query.AddSelect(icatColumnMetaResourceAttrName, 1)
+		query.AddSelect(icatColumnMetaResourceAttrValue, 1)
+		query.AddSelect(icatColumnMetaResourceAttrUnits, 1)
+		query.AddCondition(irodsclient_common.ICAT_COLUMN_R_RESC_NAME, fmt.Sprintf("= '%s'", resource))
+
+		queryResult := irodsclient_message.IRODSMessageQueryResponse{}
+		err = connection.Request(query, &queryResult, nil)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to receive a resource metadata query result message: %w", err)
+		}
+
+		err = queryResult.CheckError()
+		if err != nil {
+			if irodsclient_types.GetIRODSErrorCode(err) == irodsclient_common.CAT_NO_ROWS_FOUND {
+				// empty
+				break
+			}
+			return nil, xerrors.Errorf("received a resource metadata query error: %w", err)
+		}
+
+		pagenatedMetas := make([]*irodsclient_types.IRODSMeta, queryResult.RowCount)
+		for row := 0; row < queryResult.RowCount; row++ {
+			pagenatedMetas[row] = &irodsclient_types.IRODSMeta{
+				AVUID: -1,
+			}
+		}
+
+		for _, sqlResult := range queryResult.SQLResult {
+			if len(sqlResult.Values) != queryResult.RowCount {
+				return nil, xerrors.Errorf("failed to receive resource metadata rows - requires %d, but received %d attributes", queryResult.RowCount, len(sqlResult.Values))
+			}
+
+			for row, value := range sqlResult.Values {
+				switch sqlResult.AttributeIndex {
+				case int(icatColumnMetaResourceAttrID):
+					avuID, err := strconv.ParseInt(value, 10, 64)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse resource metadata id '%s': %w", value, err)
+					}
+					pagenatedMetas[row].AVUID = avuID
+				case int(icatColumnMetaResourceAttrName):
+					pagenatedMetas[row].Name = value
+				case int(icatColumnMetaResourceAttrValue):
+					pagenatedMetas[row].Value = value
+				case int(icatColumnMetaResourceAttrUnits):
+					pagenatedMetas[row].Units = value
+				}
+			}
+		}
+
+		metas = append(metas, pagenatedMetas...)
+
+		continueIndex = queryResult.ContinueIndex
+		if continueIndex == 0 {
+			break
+		}
+	}
+
+	return metas, nil
+}
+
+// MetadataUpdate holds new fields of an AVU for modification, only updated fields are changed
+type MetadataUpdate struct {
+	Name         string
+	NameUpdated  bool
+	Value        string
+	ValueUpdated bool
+	Units        string
+	UnitsUpdated bool
+}
+
+// newModifyMetadataRequest makes a request for the iRODS "mod" operation.
+// New fields are prefixed with "n:", "v:" and "u:" and only updated fields are sent.
+// iRODS takes the first new field in place of the old units if the old AVU has no units.
+func newModifyMetadataRequest(itemType irodsclient_types.IRODSMetaItemType, itemName string, oldMeta *irodsclient_types.IRODSMeta, update *MetadataUpdate) (*irodsclient_message.IRODSMessageModifyMetadataRequest, error) {
+	newArgs := []string{}
+	if update.NameUpdated {
+		if len(update.Name) == 0 {
+			return nil, xerrors.Errorf("new attribute name must not be empty")
+		}
+		newArgs = append(newArgs, "n:"+update.Name)
+	}
+
+	if update.ValueUpdated {
+		if len(update.Value) == 0 {
+			return nil, xerrors.Errorf("new attribute value must not be empty")
+		}
+		newArgs = append(newArgs, "v:"+update.Value)
+	}
+
+	if update.UnitsUpdated {
+		newArgs = append(newArgs, "u:"+update.Units)
+	}
+
+	if len(newArgs) == 0 {
+		return nil, xerrors.Errorf("nothing to modify, one of name, value or units must be updated")
+	}
+
+	args := []string{}
+	if len(oldMeta.Units) > 0 {
+		args = append(args, oldMeta.Units)
+	}
+	args = append(args, newArgs...)
+
+	// arg5 to arg8
+	for len(args) < 4 {
+		args = append(args, "")
+	}
+
+	request := &irodsclient_message.IRODSMessageModifyMetadataRequest{
+		Operation:    "mod",
+		ItemType:     string(itemType),
+		ItemName:     itemName,
+		AttrName:     oldMeta.Name,
+		AttrValue:    oldMeta.Value,
+		AttrUnits:    args[0],
+		NewAttrName:  args[1],
+		NewAttrValue: args[2],
+		NewAttrUnits: args[3],
+	}
+
+	return request, nil
+}
+
+// ModifyMetadata modifies fields of an existing AVU of the given item in a single request
+func ModifyMetadata(fs *irodsclient_fs.FileSystem, itemType irodsclient_types.IRODSMetaItemType, itemName string, oldMeta *irodsclient_types.IRODSMeta, update *MetadataUpdate) error {
+	request, err := newModifyMetadataRequest(itemType, itemName, oldMeta, update)
+	if err != nil {
+		return xerrors.Errorf("failed to make a request to modify metadata of %s: %w", itemName, err)
+	}
+
+	connection, err := fs.GetMetadataConnection()
+	if err != nil {
+		return xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnMetadataConnection(connection)
+
+	connection.Lock()
+	defer connection.Unlock()
+
+	response := irodsclient_message.IRODSMessageModifyMetadataResponse{}
+	err = connection.RequestAndCheck(request, &response, nil)
+	if err != nil {
+		return xerrors.Errorf("failed to modify metadata of %s: %w", itemName, err)
+	}
+
+	fs.ClearCache()
+	return nil
+}
@@ -0,0 +1,71 @@
+package commons
+
+import (
+	"testing"
+
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadata(t *testing.T) {
+	t.Run("test ModifyMetadataRequest", testModifyMetadataRequest)
+}
+
+func testModifyMetadataRequest(t *testing.T) {
+	oldMeta := &irodsclient_types.IRODSMeta{
+		Name:  "attr",
+		Value: "val",
+		Units: "unit",
+	}
+
+	request, err := newModifyMetadataRequest(irodsclient_types.IRODSDataObjectMetaItemType, "/zone/home/user/a", oldMeta, &MetadataUpdate{
+		Value:        "val2",
+		ValueUpdated: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "mod", request.Operation)
+	assert.Equal(t, "-d", request.ItemType)
+	assert.Equal(t, "/zone/home/user/a", request.ItemName)
+	assert.Equal(t, "attr", request.AttrName)
+	assert.Equal(t, "val", request.AttrValue)
+	assert.Equal(t, "unit", request.AttrUnits)
+	assert.Equal(t, "v:val2", request.NewAttrName)
+	assert.Equal(t, "", request.NewAttrValue)
+	assert.Equal(t, "", request.NewAttrUnits)
+
+	request, err = newModifyMetadataRequest(irodsclient_types.IRODSDataObjectMetaItemType, "/zone/home/user/a", oldMeta, &MetadataUpdate{
+		Name:         "attr2",
+		NameUpdated:  true,
+		Value:        "val2",
+		ValueUpdated: true,
+		Units:        "unit2",
+		UnitsUpdated: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "unit", request.AttrUnits)
+	assert.Equal(t, "n:attr2", request.NewAttrName)
+	assert.Equal(t, "v:val2", request.NewAttrValue)
+	assert.Equal(t, "u:unit2", request.NewAttrUnits)
+
+	// without old units, new fields start at the units
+	oldMeta.Units = ""
+	request, err = newModifyMetadataRequest(irodsclient_types.IRODSCollectionMetaItemType, "/zone/home/user", oldMeta, &MetadataUpdate{
+		Name:         "attr2",
+		NameUpdated:  true,
+		Units:        "unit2",
+		UnitsUpdated: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "-C", request.ItemType)
+	assert.Equal(t, "n:attr2", request.AttrUnits)
+	assert.Equal(t, "u:unit2", request.NewAttrName)
+	assert.Equal(t, "", request.NewAttrValue)
+
+	_, err = newModifyMetadataRequest(irodsclient_types.IRODSCollectionMetaItemType, "/zone/home/user", oldMeta, &MetadataUpdate{})
+	assert.Error(t, err)
+
+	_, err = newModifyMetadataRequest(irodsclient_types.IRODSCollectionMetaItemType, "/zone/home/user", oldMeta, &MetadataUpdate{
+		ValueUpdated: true,
+	})
+	assert.Error(t, err)
+}
@@ -46,6 +46,9 @@ type ParallelJobManager struct {
 	nextJobIndex            int64
 	pendingJobs             chan *ParallelJob
 	maxThreads              int
+	retryPolicy             *RetryPolicy
+	failureReport           *FailureReport
+	rateLimiter             *RateLimiter
 	showProgress            bool
 	progressWriter          progress.Writer
 	progressTrackers        map[string]*progress.Tracker
@@ -65,6 +68,9 @@ func NewParallelJobManager(fs *irodsclient_fs.FileSystem, maxThreads int, showPr
 		nextJobIndex:            0,
 		pendingJobs:             make(chan *ParallelJob, 100),
 		maxThreads:              maxThreads,
+		retryPolicy:             nil,
+		failureReport:           nil,
+		rateLimiter:             nil,
 		showProgress:            showProgress,
 		progressWriter:          nil,
 		progressTrackers:        map[string]*progress.Tracker{},
@@ -86,6 +92,32 @@ func (manager *ParallelJobManager) GetFilesystem() *irodsclient_fs.FileSystem {
 	return manager.filesystem
 }
 
+// SetRetryPolicy makes the manager retry jobs failed with transient errors, nil disables retrying
+func (manager *ParallelJobManager) SetRetryPolicy(retryPolicy *RetryPolicy) {
+	manager.retryPolicy = retryPolicy
+}
+
+// SetFailureReport makes the manager record failed jobs to the report and continue running other jobs, nil stops on the first failure.
+// Job names are recorded as failed paths.
+func (manager *ParallelJobManager) SetFailureReport(failureReport *FailureReport) {
+	manager.failureReport = failureReport
+}
+
+// GetFailureReport returns the failure report, nil if the manager stops on the first failure
+func (manager *ParallelJobManager) GetFailureReport() *FailureReport {
+	return manager.failureReport
+}
+
+// SetRateLimiter sets the limiter shared by all jobs to limit transfer bandwidth, nil for no limit
+func (manager *ParallelJobManager) SetRateLimiter(rateLimiter *RateLimiter) {
+	manager.rateLimiter = rateLimiter
+}
+
+// GetRateLimiter returns the rate limiter, nil for no limit
+func (manager *ParallelJobManager) GetRateLimiter() *RateLimiter {
+	return manager.rateLimiter
+}
+
 func (manager *ParallelJobManager) getNextJobIndex() int64 {
 	idx := manager.nextJobIndex
 	manager.nextJobIndex++
@@ -167,6 +199,12 @@ func (manager *ParallelJobManager) startProgress() {
 				manager.progressTrackers[name] = tracker
 			} else {
 				tracker = t
+
+				if tracker.IsErrored() && !errored {
+					// retried
+					tracker.Reset()
+					tracker.Total = total
+				}
 			}
 
 			if processed >= 0 {
@@ -248,15 +286,21 @@ func (manager *ParallelJobManager) Start() {
 				go func(pjob *ParallelJob) {
 					logger.Debugf("Run job %d, %s", pjob.index, pjob.name)
 
-					err := pjob.task(pjob)
+					err := manager.retryPolicy.Run(pjob.name, func() error {
+						return pjob.task(pjob)
+					})
 
 					logger.Debugf("Run job %d, %s", pjob.index, pjob.name)
 
 					if err != nil {
-						// mark error
-						manager.mutex.Lock()
-						manager.lastError = err
-						manager.mutex.Unlock()
+						if manager.failureReport != nil {
+							manager.failureReport.Add(pjob.name, FailurePhaseTransfer, err)
+						} else {
+							// mark error
+							manager.mutex.Lock()
+							manager.lastError = err
+							manager.mutex.Unlock()
+						}
 
 						logger.Error(err)
 						// don't stop here
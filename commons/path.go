@@ -13,6 +13,11 @@ import (
 	"golang.org/x/xerrors"
 )
 
+const (
+	// StdioPath is a local path meaning stdin or stdout
+	StdioPath string = "-"
+)
+
 func MakeIRODSPath(cwd string, homedir string, zone string, irodsPath string) string {
 	irodsPath = strings.TrimPrefix(irodsPath, "i:")
 
@@ -279,6 +284,37 @@ func GetCommonRootLocalDirPathForSync(paths []string) (string, error) {
 	return filepath.Dir(commonRoot), nil
 }
 
+func GetCommonRootIRODSDirPath(fs *irodsclient_fs.FileSystem, paths []string) (string, error) {
+	commonRootPath, err := GetCommonRootIRODSDirPathForSync(fs, paths)
+	if err != nil {
+		return "", err
+	}
+
+	if commonRootPath == "/" {
+		return "/", nil
+	}
+
+	return path.Dir(commonRootPath), nil
+}
+
+func GetCommonRootIRODSDirPathForSync(fs *irodsclient_fs.FileSystem, paths []string) (string, error) {
+	// find shortest path
+	commonRoot := commonPrefix('/', paths...)
+	if len(commonRoot) == 0 {
+		return "/", nil
+	}
+
+	commonRootEntry, err := fs.Stat(commonRoot)
+	if err != nil {
+		return "", xerrors.Errorf("failed to stat %s: %w", commonRoot, err)
+	}
+
+	if commonRootEntry.Type == irodsclient_fs.DirectoryEntry {
+		return commonRoot, nil
+	}
+	return path.Dir(commonRoot), nil
+}
+
 func ExpandHomeDir(p string) (string, error) {
 	// resolve "~/"
 	if p == "~" {
@@ -0,0 +1,627 @@
+package commons
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_common "github.com/cyverse/go-irodsclient/irods/common"
+	irodsclient_message "github.com/cyverse/go-irodsclient/irods/message"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	irodsclient_util "github.com/cyverse/go-irodsclient/irods/util"
+	"golang.org/x/xerrors"
+)
+
+// QueryCondition is a GenQuery condition on a column, e.g., "= 'value'"
+type QueryCondition struct {
+	Column    irodsclient_common.ICATColumnNumber
+	Condition string
+}
+
+// MetaCondition is a condition on AVU metadata, e.g., "study_id=1234"
+type MetaCondition struct {
+	Name     string
+	Operator string
+	Value    string
+}
+
+var (
+	// two-char operators must come first
+	metaConditionOperators []string = []string{">=", "<=", "!=", "<>", "~=", "=", "<", ">"}
+)
+
+// ParseMetaCondition parses a metadata condition string in "name<op>value" form.
+// Names and values with single quotes are rejected, as GenQuery cannot escape them.
+func ParseMetaCondition(cond string) (*MetaCondition, error) {
+	opIdx := strings.IndexAny(cond, "=<>!~")
+	if opIdx < 0 {
+		return nil, xerrors.Errorf("failed to find an operator in metadata condition '%s'", cond)
+	}
+
+	for _, op := range metaConditionOperators {
+		if strings.HasPrefix(cond[opIdx:], op) {
+			name := strings.TrimSpace(cond[:opIdx])
+			if len(name) == 0 {
+				return nil, xerrors.Errorf("failed to find an attribute name in metadata condition '%s'", cond)
+			}
+
+			value := strings.TrimSpace(cond[opIdx+len(op):])
+			if strings.Contains(name, "'") || strings.Contains(value, "'") {
+				return nil, xerrors.Errorf("single quotes are not allowed in metadata condition '%s'", cond)
+			}
+
+			return &MetaCondition{
+				Name:     name,
+				Operator: op,
+				Value:    value,
+			}, nil
+		}
+	}
+
+	return nil, xerrors.Errorf("unknown operator in metadata condition '%s'", cond)
+}
+
+// IsNumeric checks if the condition compares numbers, GenQuery compares values as numbers if they are not quoted
+func (cond *MetaCondition) IsNumeric() bool {
+	switch cond.Operator {
+	case "<", "<=", ">", ">=":
+		_, err := strconv.ParseFloat(cond.Value, 64)
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// GetValueCondition returns GenQuery condition string for the value
+func (cond *MetaCondition) GetValueCondition() string {
+	switch cond.Operator {
+	case "!=", "<>":
+		return fmt.Sprintf("<> '%s'", cond.Value)
+	case "~=":
+		return fmt.Sprintf("like '%s'", cond.Value)
+	default:
+		if cond.IsNumeric() {
+			// "10" < "9" in string comparison
+			return fmt.Sprintf("%s %s", cond.Operator, cond.Value)
+		}
+
+		return fmt.Sprintf("%s '%s'", cond.Operator, cond.Value)
+	}
+}
+
+// GetDataObjectQueryConditions returns GenQuery conditions for searching data objects having the metadata
+func (cond *MetaCondition) GetDataObjectQueryConditions() []QueryCondition {
+	return []QueryCondition{
+		{
+			Column:    irodsclient_common.ICAT_COLUMN_META_DATA_ATTR_NAME,
+			Condition: fmt.Sprintf("= '%s'", cond.Name),
+		},
+		{
+			Column:    irodsclient_common.ICAT_COLUMN_META_DATA_ATTR_VALUE,
+			Condition: cond.GetValueCondition(),
+		},
+	}
+}
+
+// GetCollectionTreeCondition returns GenQuery condition matching the collection and all its sub-collections.
+// The condition may match more collections than the tree, use IsInCollectionTree to check the paths.
+func GetCollectionTreeCondition(collectionPath string) QueryCondition {
+	pattern := EscapeLikePattern(collectionPath)
+	cond := fmt.Sprintf("like '%s' || like '%s/%%'", pattern, pattern)
+	if collectionPath == "/" {
+		cond = "like '/%'"
+	}
+
+	return QueryCondition{
+		Column:    irodsclient_common.ICAT_COLUMN_COLL_NAME,
+		Condition: cond,
+	}
+}
+
+// EscapeLikePattern escapes wildcards in the value for a GenQuery like pattern.
+// Single quotes cannot be escaped in GenQuery, so they are converted to a single character wildcard.
+func EscapeLikePattern(value string) string {
+	sb := strings.Builder{}
+
+	for _, r := range value {
+		switch r {
+		case '\\', '%', '_':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case '\'':
+			sb.WriteRune('_')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+// IsInCollectionTree checks if the path is the collection or is under the collection
+func IsInCollectionTree(collectionPath string, p string) bool {
+	return getCollectionDepth(collectionPath, p) >= 0
+}
+
+// GetDataObjectNameCondition returns GenQuery condition matching names of data objects with the glob pattern.
+// The condition may match more data objects than the pattern, use path.Match to check the names.
+func GetDataObjectNameCondition(pattern string) QueryCondition {
+	return QueryCondition{
+		Column:    irodsclient_common.ICAT_COLUMN_DATA_NAME,
+		Condition: fmt.Sprintf("like '%s'", GlobToLikePattern(pattern)),
+	}
+}
+
+// GlobToLikePattern converts a glob pattern to a GenQuery like pattern.
+// Character classes are not supported by GenQuery, so they are converted to a single character wildcard.
+func GlobToLikePattern(pattern string) string {
+	sb := strings.Builder{}
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '*':
+			sb.WriteRune('%')
+		case '?':
+			sb.WriteRune('_')
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+
+			if end >= len(runes) {
+				// not closed
+				sb.WriteRune(runes[i])
+				continue
+			}
+
+			sb.WriteRune('_')
+			i = end
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			sb.WriteRune(runes[i])
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+
+	return sb.String()
+}
+
+// GetDataObjectSizeCondition returns GenQuery condition comparing sizes of data objects with the size
+func GetDataObjectSizeCondition(operator string, size int64) QueryCondition {
+	return QueryCondition{
+		Column:    irodsclient_common.ICAT_COLUMN_DATA_SIZE,
+		Condition: fmt.Sprintf("%s '%d'", operator, size),
+	}
+}
+
+// GetDataObjectModifyTimeCondition returns GenQuery condition comparing modification times of data object replicas with the time
+func GetDataObjectModifyTimeCondition(operator string, t time.Time) QueryCondition {
+	return QueryCondition{
+		Column:    irodsclient_common.ICAT_COLUMN_D_MODIFY_TIME,
+		Condition: fmt.Sprintf("%s '%s'", operator, MakeIRODSTimeString(t)),
+	}
+}
+
+// MakeIRODSTimeString returns a time string stored in iRODS catalog, zero-padded seconds since epoch
+func MakeIRODSTimeString(t time.Time) string {
+	return fmt.Sprintf("%011d", t.Unix())
+}
+
+// SizeCondition is a condition on data object sizes in find style, e.g., "+1G" for larger than 1GB, "-100k" for smaller than 100KB
+type SizeCondition struct {
+	Operator string
+	Size     int64
+}
+
+// ParseSizeCondition parses a size condition string in "[+|-]size" form
+func ParseSizeCondition(cond string) (*SizeCondition, error) {
+	operator, value := parseFindConditionSign(cond)
+	if len(value) == 0 {
+		return nil, xerrors.Errorf("failed to find a size in size condition '%s'", cond)
+	}
+
+	size, err := ParseSize(value)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse size condition '%s': %w", cond, err)
+	}
+
+	return &SizeCondition{
+		Operator: operator,
+		Size:     size,
+	}, nil
+}
+
+// GetQueryCondition returns GenQuery condition for the size condition
+func (cond *SizeCondition) GetQueryCondition() QueryCondition {
+	return GetDataObjectSizeCondition(cond.Operator, cond.Size)
+}
+
+// Match checks if the size satisfies the size condition
+func (cond *SizeCondition) Match(size int64) bool {
+	switch cond.Operator {
+	case ">":
+		return size > cond.Size
+	case "<":
+		return size < cond.Size
+	default:
+		return size == cond.Size
+	}
+}
+
+// TimeCondition is a condition on modification times in find style, ages are in days unless a unit is given.
+// e.g., "-7" for modified within 7 days, "+12h" for modified more than 12 hours ago, "3" for modified 3 days ago
+type TimeCondition struct {
+	// After and Before are bounds of modification times, zero time means no bound
+	After  time.Time
+	Before time.Time
+}
+
+// ParseTimeCondition parses a time condition string in "[+|-]age[s|m|h|d]" form, ages are relative to now
+func ParseTimeCondition(cond string, now time.Time) (*TimeCondition, error) {
+	operator, value := parseFindConditionSign(cond)
+	if len(value) == 0 {
+		return nil, xerrors.Errorf("failed to find an age in time condition '%s'", cond)
+	}
+
+	unit := time.Duration(Day) * time.Second
+	switch strings.ToUpper(value[len(value)-1:]) {
+	case "S":
+		unit = time.Second
+		value = value[:len(value)-1]
+	case "M":
+		unit = time.Duration(Minute) * time.Second
+		value = value[:len(value)-1]
+	case "H":
+		unit = time.Duration(Hour) * time.Second
+		value = value[:len(value)-1]
+	case "D":
+		value = value[:len(value)-1]
+	}
+
+	age, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse time condition '%s': %w", cond, err)
+	}
+
+	timeCondition := &TimeCondition{}
+
+	switch operator {
+	case "<":
+		// younger than age
+		timeCondition.After = now.Add(-time.Duration(age) * unit)
+	case ">":
+		// older than age
+		timeCondition.Before = now.Add(-time.Duration(age) * unit)
+	default:
+		// age in units is exactly the given age
+		timeCondition.After = now.Add(-time.Duration(age+1) * unit)
+		timeCondition.Before = now.Add(-time.Duration(age) * unit)
+	}
+
+	return timeCondition, nil
+}
+
+// GetQueryConditions returns GenQuery conditions for the time condition
+func (cond *TimeCondition) GetQueryConditions() []QueryCondition {
+	conditions := []QueryCondition{}
+	if !cond.After.IsZero() {
+		conditions = append(conditions, GetDataObjectModifyTimeCondition(">", cond.After))
+	}
+
+	if !cond.Before.IsZero() {
+		conditions = append(conditions, GetDataObjectModifyTimeCondition("<", cond.Before))
+	}
+
+	return conditions
+}
+
+// Match checks if the time satisfies the time condition
+func (cond *TimeCondition) Match(t time.Time) bool {
+	if !cond.After.IsZero() && !t.After(cond.After) {
+		return false
+	}
+
+	if !cond.Before.IsZero() && !t.Before(cond.Before) {
+		return false
+	}
+
+	return true
+}
+
+// parseFindConditionSign returns operator for the leading sign, "+" for ">", "-" for "<" and none for "="
+func parseFindConditionSign(cond string) (string, string) {
+	cond = strings.TrimSpace(cond)
+
+	if strings.HasPrefix(cond, "+") {
+		return ">", cond[1:]
+	}
+
+	if strings.HasPrefix(cond, "-") {
+		return "<", cond[1:]
+	}
+
+	return "=", cond
+}
+
+// SearchCollections returns the collection and all its sub-collections
+func SearchCollections(fs *irodsclient_fs.FileSystem, collectionPath string) ([]*irodsclient_types.IRODSCollection, error) {
+	connection, err := fs.GetMetadataConnection()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnMetadataConnection(connection)
+
+	connection.Lock()
+	defer connection.Unlock()
+
+	collCondition := GetCollectionTreeCondition(collectionPath)
+
+	collections := []*irodsclient_types.IRODSCollection{}
+
+	continueIndex := 0
+	for {
+		query := irodsclient_message.NewIRODSMessageQueryRequest(irodsclient_common.MaxQueryRows, continueIndex, 0, 0)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_COLL_ID, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_COLL_NAME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_COLL_OWNER_NAME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_COLL_CREATE_TIME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_COLL_MODIFY_TIME, 1)
+		query.AddCondition(collCondition.Column, collCondition.Condition)
+
+		queryResult := irodsclient_message.IRODSMessageQueryResponse{}
+		err = connection.Request(query, &queryResult, nil)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to receive a collection query result message: %w", err)
+		}
+
+		err = queryResult.CheckError()
+		if err != nil {
+			if irodsclient_types.GetIRODSErrorCode(err) == irodsclient_common.CAT_NO_ROWS_FOUND {
+				// empty
+				break
+			}
+			return nil, xerrors.Errorf("received a collection query error: %w", err)
+		}
+
+		pagenatedCollections := make([]*irodsclient_types.IRODSCollection, queryResult.RowCount)
+		for row := 0; row < queryResult.RowCount; row++ {
+			pagenatedCollections[row] = &irodsclient_types.IRODSCollection{}
+		}
+
+		for _, sqlResult := range queryResult.SQLResult {
+			if len(sqlResult.Values) != queryResult.RowCount {
+				return nil, xerrors.Errorf("failed to receive collection rows - requires %d, but received %d attributes", queryResult.RowCount, len(sqlResult.Values))
+			}
+
+			for row, value := range sqlResult.Values {
+				switch sqlResult.AttributeIndex {
+				case int(irodsclient_common.ICAT_COLUMN_COLL_ID):
+					collID, err := strconv.ParseInt(value, 10, 64)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse collection id '%s': %w", value, err)
+					}
+					pagenatedCollections[row].ID = collID
+				case int(irodsclient_common.ICAT_COLUMN_COLL_NAME):
+					pagenatedCollections[row].Path = value
+					pagenatedCollections[row].Name = irodsclient_util.GetBasename(value)
+				case int(irodsclient_common.ICAT_COLUMN_COLL_OWNER_NAME):
+					pagenatedCollections[row].Owner = value
+				case int(irodsclient_common.ICAT_COLUMN_COLL_CREATE_TIME):
+					cT, err := irodsclient_util.GetIRODSDateTime(value)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse create time '%s': %w", value, err)
+					}
+					pagenatedCollections[row].CreateTime = cT
+				case int(irodsclient_common.ICAT_COLUMN_COLL_MODIFY_TIME):
+					mT, err := irodsclient_util.GetIRODSDateTime(value)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse modify time '%s': %w", value, err)
+					}
+					pagenatedCollections[row].ModifyTime = mT
+				}
+			}
+		}
+
+		for _, collection := range pagenatedCollections {
+			if IsInCollectionTree(collectionPath, collection.Path) {
+				collections = append(collections, collection)
+			}
+		}
+
+		continueIndex = queryResult.ContinueIndex
+		if continueIndex == 0 {
+			break
+		}
+	}
+
+	// sort by path
+	sort.SliceStable(collections, func(i int, j int) bool {
+		return collections[i].Path < collections[j].Path
+	})
+
+	return collections, nil
+}
+
+// SearchDataObjects searches data objects under the collection tree that satisfy all conditions
+func SearchDataObjects(fs *irodsclient_fs.FileSystem, collectionPath string, conditions []QueryCondition) ([]*irodsclient_types.IRODSDataObject, error) {
+	connection, err := fs.GetMetadataConnection()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnMetadataConnection(connection)
+
+	connection.Lock()
+	defer connection.Unlock()
+
+	collCondition := GetCollectionTreeCondition(collectionPath)
+
+	dataObjectsMap := map[int64]*irodsclient_types.IRODSDataObject{}
+
+	continueIndex := 0
+	for {
+		query := irodsclient_message.NewIRODSMessageQueryRequest(irodsclient_common.MaxQueryRows, continueIndex, 0, 0)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_D_DATA_ID, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_D_COLL_ID, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_COLL_NAME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_DATA_NAME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_DATA_SIZE, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_DATA_TYPE_NAME, 1)
+
+		// replica
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_DATA_REPL_NUM, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_D_OWNER_NAME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_D_DATA_CHECKSUM, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_D_REPL_STATUS, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_D_RESC_NAME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_D_DATA_PATH, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_D_RESC_HIER, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_D_CREATE_TIME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_D_MODIFY_TIME, 1)
+
+		query.AddCondition(collCondition.Column, collCondition.Condition)
+		for _, condition := range conditions {
+			query.AddCondition(condition.Column, condition.Condition)
+		}
+
+		queryResult := irodsclient_message.IRODSMessageQueryResponse{}
+		err = connection.Request(query, &queryResult, nil)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to receive a data object query result message: %w", err)
+		}
+
+		err = queryResult.CheckError()
+		if err != nil {
+			if irodsclient_types.GetIRODSErrorCode(err) == irodsclient_common.CAT_NO_ROWS_FOUND {
+				// empty
+				break
+			}
+			return nil, xerrors.Errorf("received a data object query error: %w", err)
+		}
+
+		for row := 0; row < queryResult.RowCount; row++ {
+			dataObject := &irodsclient_types.IRODSDataObject{}
+			replica := &irodsclient_types.IRODSReplica{}
+			collName := ""
+
+			for _, sqlResult := range queryResult.SQLResult {
+				if len(sqlResult.Values) != queryResult.RowCount {
+					return nil, xerrors.Errorf("failed to receive data object rows - requires %d, but received %d attributes", queryResult.RowCount, len(sqlResult.Values))
+				}
+
+				value := sqlResult.Values[row]
+
+				switch sqlResult.AttributeIndex {
+				case int(irodsclient_common.ICAT_COLUMN_D_DATA_ID):
+					objID, err := strconv.ParseInt(value, 10, 64)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse data object id '%s': %w", value, err)
+					}
+					dataObject.ID = objID
+				case int(irodsclient_common.ICAT_COLUMN_D_COLL_ID):
+					collID, err := strconv.ParseInt(value, 10, 64)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse collection id '%s': %w", value, err)
+					}
+					dataObject.CollectionID = collID
+				case int(irodsclient_common.ICAT_COLUMN_COLL_NAME):
+					collName = value
+				case int(irodsclient_common.ICAT_COLUMN_DATA_NAME):
+					dataObject.Name = value
+				case int(irodsclient_common.ICAT_COLUMN_DATA_SIZE):
+					objSize, err := strconv.ParseInt(value, 10, 64)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse data object size '%s': %w", value, err)
+					}
+					dataObject.Size = objSize
+				case int(irodsclient_common.ICAT_COLUMN_DATA_TYPE_NAME):
+					dataObject.DataType = value
+				case int(irodsclient_common.ICAT_COLUMN_DATA_REPL_NUM):
+					replNum, err := strconv.ParseInt(value, 10, 64)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse data object replica number '%s': %w", value, err)
+					}
+					replica.Number = replNum
+				case int(irodsclient_common.ICAT_COLUMN_D_OWNER_NAME):
+					replica.Owner = value
+				case int(irodsclient_common.ICAT_COLUMN_D_DATA_CHECKSUM):
+					checksum, err := irodsclient_types.CreateIRODSChecksum(value)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse data object checksum '%s': %w", value, err)
+					}
+					replica.Checksum = checksum
+				case int(irodsclient_common.ICAT_COLUMN_D_REPL_STATUS):
+					replica.Status = value
+				case int(irodsclient_common.ICAT_COLUMN_D_RESC_NAME):
+					replica.ResourceName = value
+				case int(irodsclient_common.ICAT_COLUMN_D_DATA_PATH):
+					replica.Path = value
+				case int(irodsclient_common.ICAT_COLUMN_D_RESC_HIER):
+					replica.ResourceHierarchy = value
+				case int(irodsclient_common.ICAT_COLUMN_D_CREATE_TIME):
+					cT, err := irodsclient_util.GetIRODSDateTime(value)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse create time '%s': %w", value, err)
+					}
+					replica.CreateTime = cT
+				case int(irodsclient_common.ICAT_COLUMN_D_MODIFY_TIME):
+					mT, err := irodsclient_util.GetIRODSDateTime(value)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse modify time '%s': %w", value, err)
+					}
+					replica.ModifyTime = mT
+				}
+			}
+
+			if !IsInCollectionTree(collectionPath, collName) {
+				continue
+			}
+
+			dataObject.Path = irodsclient_util.MakeIRODSPath(collName, dataObject.Name)
+
+			existingDataObject, ok := dataObjectsMap[dataObject.ID]
+			if !ok {
+				dataObject.Replicas = []*irodsclient_types.IRODSReplica{replica}
+				dataObjectsMap[dataObject.ID] = dataObject
+				continue
+			}
+
+			// a data object may appear multiple times if it matches multiple AVUs
+			hasReplica := false
+			for _, existingReplica := range existingDataObject.Replicas {
+				if existingReplica.Number == replica.Number {
+					hasReplica = true
+					break
+				}
+			}
+
+			if !hasReplica {
+				existingDataObject.Replicas = append(existingDataObject.Replicas, replica)
+			}
+		}
+
+		continueIndex = queryResult.ContinueIndex
+		if continueIndex == 0 {
+			break
+		}
+	}
+
+	dataObjects := []*irodsclient_types.IRODSDataObject{}
+	for _, dataObject := range dataObjectsMap {
+		dataObjects = append(dataObjects, dataObject)
+	}
+
+	// sort by path
+	sort.SliceStable(dataObjects, func(i int, j int) bool {
+		return dataObjects[i].Path < dataObjects[j].Path
+	})
+
+	return dataObjects, nil
+}
@@ -0,0 +1,140 @@
+package commons
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	t.Run("test MetaCondition", testMetaCondition)
+	t.Run("test GlobToLikePattern", testGlobToLikePattern)
+	t.Run("test CollectionTreeCondition", testCollectionTreeCondition)
+	t.Run("test SizeCondition", testSizeCondition)
+	t.Run("test TimeCondition", testTimeCondition)
+}
+
+func testMetaCondition(t *testing.T) {
+	c1, err := ParseMetaCondition("study_id=1234")
+	assert.NoError(t, err)
+	assert.Equal(t, "study_id", c1.Name)
+	assert.Equal(t, "=", c1.Operator)
+	assert.Equal(t, "1234", c1.Value)
+	assert.Equal(t, "= '1234'", c1.GetValueCondition())
+
+	c2, err := ParseMetaCondition("target >= 1")
+	assert.NoError(t, err)
+	assert.Equal(t, "target", c2.Name)
+	assert.Equal(t, ">=", c2.Operator)
+	assert.Equal(t, "1", c2.Value)
+	assert.True(t, c2.IsNumeric())
+	assert.Equal(t, ">= 1", c2.GetValueCondition())
+
+	c5, err := ParseMetaCondition("version<2.5")
+	assert.NoError(t, err)
+	assert.Equal(t, "< 2.5", c5.GetValueCondition())
+
+	c6, err := ParseMetaCondition("sample>abc")
+	assert.NoError(t, err)
+	assert.False(t, c6.IsNumeric())
+	assert.Equal(t, "> 'abc'", c6.GetValueCondition())
+
+	_, err = ParseMetaCondition("sample=a' || 'b")
+	assert.Error(t, err)
+
+	c3, err := ParseMetaCondition("sample!=abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "<> 'abc'", c3.GetValueCondition())
+
+	c4, err := ParseMetaCondition("sample~=NA%")
+	assert.NoError(t, err)
+	assert.Equal(t, "like 'NA%'", c4.GetValueCondition())
+
+	_, err = ParseMetaCondition("study_id")
+	assert.Error(t, err)
+
+	_, err = ParseMetaCondition("=1234")
+	assert.Error(t, err)
+
+	_, err = ParseMetaCondition("study_id!1234")
+	assert.Error(t, err)
+}
+
+func testGlobToLikePattern(t *testing.T) {
+	assert.Equal(t, "%.fastq", GlobToLikePattern("*.fastq"))
+	assert.Equal(t, "run_.txt", GlobToLikePattern("run?.txt"))
+	assert.Equal(t, "sample__.bam", GlobToLikePattern("sample[0-9][ab].bam"))
+	assert.Equal(t, "a*b", GlobToLikePattern("a\\*b"))
+	assert.Equal(t, "a[b", GlobToLikePattern("a[b"))
+}
+
+func testCollectionTreeCondition(t *testing.T) {
+	assert.Equal(t, "like '/zone/home/a\\_b' || like '/zone/home/a\\_b/%'", GetCollectionTreeCondition("/zone/home/a_b").Condition)
+	assert.Equal(t, "like '/%'", GetCollectionTreeCondition("/").Condition)
+	assert.Equal(t, "100\\%\\\\x", EscapeLikePattern("100%\\x"))
+	assert.Equal(t, "it_s", EscapeLikePattern("it's"))
+
+	assert.True(t, IsInCollectionTree("/zone/home/a_b", "/zone/home/a_b"))
+	assert.True(t, IsInCollectionTree("/zone/home/a_b", "/zone/home/a_b/c"))
+	assert.False(t, IsInCollectionTree("/zone/home/a_b", "/zone/home/aXb/c"))
+	assert.False(t, IsInCollectionTree("/zone/home/a_b", "/zone/home/a_bc"))
+	assert.True(t, IsInCollectionTree("/", "/zone"))
+}
+
+func testSizeCondition(t *testing.T) {
+	c1, err := ParseSizeCondition("+1G")
+	assert.NoError(t, err)
+	assert.Equal(t, ">", c1.Operator)
+	assert.Equal(t, GigaBytes, c1.Size)
+	assert.Equal(t, "> '1073741824'", c1.GetQueryCondition().Condition)
+	assert.True(t, c1.Match(GigaBytes+1))
+	assert.False(t, c1.Match(GigaBytes))
+
+	c2, err := ParseSizeCondition("-100k")
+	assert.NoError(t, err)
+	assert.Equal(t, "<", c2.Operator)
+	assert.True(t, c2.Match(0))
+	assert.False(t, c2.Match(100*KiloBytes))
+
+	c3, err := ParseSizeCondition("1024")
+	assert.NoError(t, err)
+	assert.Equal(t, "=", c3.Operator)
+	assert.True(t, c3.Match(1024))
+
+	_, err = ParseSizeCondition("+")
+	assert.Error(t, err)
+
+	_, err = ParseSizeCondition("+1X")
+	assert.Error(t, err)
+}
+
+func testTimeCondition(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	c1, err := ParseTimeCondition("-7", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-7*day), c1.After)
+	assert.True(t, c1.Before.IsZero())
+	assert.Equal(t, 1, len(c1.GetQueryConditions()))
+	assert.True(t, c1.Match(now.Add(-6*day)))
+	assert.False(t, c1.Match(now.Add(-8*day)))
+
+	c2, err := ParseTimeCondition("+12h", now)
+	assert.NoError(t, err)
+	assert.True(t, c2.After.IsZero())
+	assert.Equal(t, now.Add(-12*time.Hour), c2.Before)
+	assert.True(t, c2.Match(now.Add(-13*time.Hour)))
+	assert.False(t, c2.Match(now.Add(-11*time.Hour)))
+
+	c3, err := ParseTimeCondition("3", now)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(c3.GetQueryConditions()))
+	assert.True(t, c3.Match(now.Add(-3*day-time.Hour)))
+	assert.False(t, c3.Match(now.Add(-2*day)))
+	assert.False(t, c3.Match(now.Add(-5*day)))
+
+	_, err = ParseTimeCondition("-7x", now)
+	assert.Error(t, err)
+}
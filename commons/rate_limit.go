@@ -0,0 +1,103 @@
+package commons
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter limits transfer bandwidth with a token bucket shared by all threads
+type RateLimiter struct {
+	rate       int64 // bytes per second
+	tokens     float64
+	lastRefill time.Time
+	mutex      sync.Mutex
+}
+
+// NewRateLimiter creates a new RateLimiter, returns nil for no limit if rate is not positive
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	return &RateLimiter{
+		rate:       bytesPerSecond,
+		tokens:     float64(bytesPerSecond),
+		lastRefill: time.Now(),
+		mutex:      sync.Mutex{},
+	}
+}
+
+// reserve takes tokens for the size and returns the time to wait until they are available.
+// Tokens may go negative for sizes larger than the bucket, so later callers wait for them too.
+func (limiter *RateLimiter) reserve(size int64) time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+
+	// refill, the bucket holds tokens for a second at most
+	limiter.tokens += now.Sub(limiter.lastRefill).Seconds() * float64(limiter.rate)
+	if limiter.tokens > float64(limiter.rate) {
+		limiter.tokens = float64(limiter.rate)
+	}
+	limiter.lastRefill = now
+
+	limiter.tokens -= float64(size)
+	if limiter.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-limiter.tokens / float64(limiter.rate) * float64(time.Second))
+}
+
+// Wait blocks until the size can be transferred. A nil limiter does not block.
+func (limiter *RateLimiter) Wait(size int64) {
+	if limiter == nil || size <= 0 {
+		return
+	}
+
+	wait := limiter.reserve(size)
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// WrapCallback returns a transfer callback that waits for the limiter as the transfer reports progress,
+// the transfer blocks while the callback waits. Resumed is the size transferred before, not to be limited.
+// A nil limiter returns the callback as is.
+func (limiter *RateLimiter) WrapCallback(callback func(processed int64, total int64), resumed int64) func(processed int64, total int64) {
+	if limiter == nil {
+		return callback
+	}
+
+	lastProcessed := int64(0)
+	credit := resumed
+	mutex := sync.Mutex{}
+
+	return func(processed int64, total int64) {
+		mutex.Lock()
+		delta := int64(0)
+		// parallel transfers may report out of order
+		if processed > lastProcessed {
+			delta = processed - lastProcessed
+			lastProcessed = processed
+		}
+
+		if credit > 0 {
+			used := credit
+			if used > delta {
+				used = delta
+			}
+
+			credit -= used
+			delta -= used
+		}
+		mutex.Unlock()
+
+		limiter.Wait(delta)
+
+		if callback != nil {
+			callback(processed, total)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package commons
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Run("test RateLimiter", testRateLimiter)
+	t.Run("test WrapCallback", testWrapCallback)
+}
+
+func testRateLimiter(t *testing.T) {
+	assert.Nil(t, NewRateLimiter(0))
+
+	// nil limiter does not block
+	var nilLimiter *RateLimiter
+	nilLimiter.Wait(1024 * 1024)
+
+	limiter := NewRateLimiter(10000)
+
+	// the bucket is full at first
+	start := time.Now()
+	limiter.Wait(10000)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	// then waits for tokens
+	start = time.Now()
+	limiter.Wait(2000)
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
+
+func testWrapCallback(t *testing.T) {
+	var nilLimiter *RateLimiter
+	assert.Nil(t, nilLimiter.WrapCallback(nil, 0))
+
+	// sizes within the bucket to not wait, tokens used are checked
+	limiter := NewRateLimiter(1000)
+
+	reported := int64(0)
+	callback := limiter.WrapCallback(func(processed int64, total int64) {
+		reported = processed
+	}, 300)
+
+	tokens := limiter.tokens
+
+	// resumed size is not limited
+	callback(200, 1000)
+	assert.Equal(t, int64(200), reported)
+	assert.InDelta(t, tokens, limiter.tokens, 1)
+
+	callback(500, 1000)
+	assert.InDelta(t, tokens-200, limiter.tokens, 1)
+
+	// reports out of order are not counted twice
+	callback(400, 1000)
+	callback(1000, 1000)
+	assert.InDelta(t, tokens-700, limiter.tokens, 1)
+}
@@ -0,0 +1,174 @@
+package commons
+
+import (
+	"bytes"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+// CopyRange copies length bytes from offset of the reader to the writer, negative length copies to the end.
+// Data is read with a large buffer, as each read is a round trip to the server.
+func CopyRange(w io.Writer, r io.ReadSeeker, offset int64, length int64) (int64, error) {
+	// the reader may have been read already, so always seek
+	newOffset, err := r.Seek(offset, io.SeekStart)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to seek to %d: %w", offset, err)
+	}
+
+	if newOffset != offset {
+		return 0, xerrors.Errorf("failed to seek to %d, moved to %d", offset, newOffset)
+	}
+
+	var reader io.Reader = r
+	if length >= 0 {
+		reader = io.LimitReader(r, length)
+	}
+
+	// io.Copy may use small buffers of io.ReaderFrom implementations, so copy here
+	buf := make([]byte, ReadBufferSizeDefault)
+	totalWritten := int64(0)
+	for {
+		readLen, readErr := reader.Read(buf)
+		if readLen > 0 {
+			_, err := w.Write(buf[:readLen])
+			if err != nil {
+				return totalWritten, xerrors.Errorf("failed to write: %w", err)
+			}
+
+			totalWritten += int64(readLen)
+		}
+
+		if readErr != nil {
+			if readErr == io.EOF {
+				return totalWritten, nil
+			}
+
+			return totalWritten, xerrors.Errorf("failed to read: %w", readErr)
+		}
+	}
+}
+
+// CopyLines copies the first given number of lines from the reader to the writer
+func CopyLines(w io.Writer, r io.Reader, lines int64) (int64, error) {
+	if lines <= 0 {
+		return 0, nil
+	}
+
+	buf := make([]byte, ReadBufferSizeDefault)
+	totalWritten := int64(0)
+	for {
+		readLen, readErr := r.Read(buf)
+		if readLen > 0 {
+			data := buf[:readLen]
+
+			// find the end of the last line to copy
+			done := false
+			for idx, b := range data {
+				if b == '\n' {
+					lines--
+					if lines == 0 {
+						data = data[:idx+1]
+						done = true
+						break
+					}
+				}
+			}
+
+			_, err := w.Write(data)
+			if err != nil {
+				return totalWritten, xerrors.Errorf("failed to write: %w", err)
+			}
+
+			totalWritten += int64(len(data))
+
+			if done {
+				return totalWritten, nil
+			}
+		}
+
+		if readErr != nil {
+			if readErr == io.EOF {
+				return totalWritten, nil
+			}
+
+			return totalWritten, xerrors.Errorf("failed to read: %w", readErr)
+		}
+	}
+}
+
+// GetTailLinesOffset returns the offset where the last given number of lines start.
+// The data is scanned backward from the end, so only the tail is read.
+func GetTailLinesOffset(r io.ReaderAt, size int64, lines int64) (int64, error) {
+	if lines <= 0 {
+		return size, nil
+	}
+
+	end := size
+	if end > 0 {
+		// a newline at the end does not start a new line
+		lastByte := make([]byte, 1)
+		err := readFullAt(r, lastByte, end-1)
+		if err != nil {
+			return 0, err
+		}
+
+		if lastByte[0] == '\n' {
+			end--
+		}
+	}
+
+	buf := make([]byte, ReadBufferSizeDefault)
+	for end > 0 {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+
+		chunk := buf[:end-start]
+		err := readFullAt(r, chunk, start)
+		if err != nil {
+			return 0, err
+		}
+
+		for {
+			idx := bytes.LastIndexByte(chunk, '\n')
+			if idx < 0 {
+				break
+			}
+
+			lines--
+			if lines == 0 {
+				return start + int64(idx) + 1, nil
+			}
+
+			chunk = chunk[:idx]
+		}
+
+		end = start
+	}
+
+	return 0, nil
+}
+
+func readFullAt(r io.ReaderAt, buf []byte, offset int64) error {
+	readTotal := 0
+	for readTotal < len(buf) {
+		readLen, err := r.ReadAt(buf[readTotal:], offset+int64(readTotal))
+		readTotal += readLen
+
+		if err != nil {
+			if err == io.EOF && readTotal == len(buf) {
+				return nil
+			}
+
+			return xerrors.Errorf("failed to read at %d: %w", offset+int64(readTotal), err)
+		}
+
+		if readLen == 0 {
+			return xerrors.Errorf("failed to read at %d: %w", offset+int64(readTotal), io.ErrUnexpectedEOF)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package commons
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	t.Run("test CopyRange", testCopyRange)
+	t.Run("test CopyLines", testCopyLines)
+	t.Run("test GetTailLinesOffset", testGetTailLinesOffset)
+}
+
+func testCopyRange(t *testing.T) {
+	data := "0123456789"
+
+	out := &bytes.Buffer{}
+	n, err := CopyRange(out, strings.NewReader(data), 2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, "234", out.String())
+
+	out.Reset()
+	n, err = CopyRange(out, strings.NewReader(data), 7, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, "789", out.String())
+
+	out.Reset()
+	_, err = CopyRange(out, strings.NewReader(data), 8, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, "89", out.String())
+}
+
+func testCopyLines(t *testing.T) {
+	data := "a\nb\nc\nd"
+
+	out := &bytes.Buffer{}
+	_, err := CopyLines(out, strings.NewReader(data), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "a\nb\n", out.String())
+
+	out.Reset()
+	_, err = CopyLines(out, strings.NewReader(data), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, data, out.String())
+
+	out.Reset()
+	_, err = CopyLines(out, strings.NewReader(data), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "", out.String())
+}
+
+func testGetTailLinesOffset(t *testing.T) {
+	data := "a\nb\nc\nd\n"
+	r := strings.NewReader(data)
+
+	offset, err := GetTailLinesOffset(r, int64(len(data)), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "c\nd\n", data[offset:])
+
+	offset, err = GetTailLinesOffset(r, int64(len(data)), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), offset)
+
+	data2 := "a\nb\nc"
+	offset, err = GetTailLinesOffset(strings.NewReader(data2), int64(len(data2)), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "c", data2[offset:])
+
+	offset, err = GetTailLinesOffset(strings.NewReader(""), 0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), offset)
+
+	// spans multiple read chunks
+	long := strings.Repeat("x", ReadBufferSizeDefault) + "\n" + strings.Repeat("y", ReadBufferSizeDefault) + "\nlast\n"
+	offset, err = GetTailLinesOffset(strings.NewReader(long), int64(len(long)), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(ReadBufferSizeDefault+1), offset)
+}
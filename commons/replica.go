@@ -0,0 +1,120 @@
+package commons
+
+import (
+	"encoding/xml"
+	"strings"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_common "github.com/cyverse/go-irodsclient/irods/common"
+	irodsclient_connection "github.com/cyverse/go-irodsclient/irods/connection"
+	irodsclient_message "github.com/cyverse/go-irodsclient/irods/message"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"golang.org/x/xerrors"
+)
+
+// TrimReplicas trims replicas of the data object.
+// If resource is given, replicas on the resource are trimmed. minCopies replicas are kept at least.
+func TrimReplicas(fs *irodsclient_fs.FileSystem, path string, resource string, minCopies int) error {
+	// TrimDataObject in go-irodsclient always sets a resource, so build the request here
+	request := irodsclient_message.NewIRODSMessageTrimDataObjectRequest(path, resource, minCopies, 0)
+	response := irodsclient_message.IRODSMessageTrimDataObjectResponse{}
+
+	err := requestDataObjectOperation(fs, path, request, &response)
+	if err != nil {
+		return xerrors.Errorf("failed to trim replicas of %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// MoveReplica moves a replica of the data object from source resource to destination resource
+func MoveReplica(fs *irodsclient_fs.FileSystem, path string, sourceResource string, destResource string) error {
+	request := &phymvRequest{
+		Path: path,
+		Size: -1,
+		KeyVals: irodsclient_message.IRODSMessageSSKeyVal{
+			Length: 0,
+		},
+	}
+
+	if len(sourceResource) > 0 {
+		request.KeyVals.Add(string(irodsclient_common.RESC_NAME_KW), sourceResource)
+	}
+	request.KeyVals.Add(string(irodsclient_common.DEST_RESC_NAME_KW), destResource)
+
+	// response only has a result code like trim
+	response := irodsclient_message.IRODSMessageTrimDataObjectResponse{}
+
+	err := requestDataObjectOperation(fs, path, request, &response)
+	if err != nil {
+		return xerrors.Errorf("failed to move replica of %s from %s to %s: %w", path, sourceResource, destResource, err)
+	}
+
+	return nil
+}
+
+func requestDataObjectOperation(fs *irodsclient_fs.FileSystem, path string, request irodsclient_connection.Request, response irodsclient_connection.CheckErrorResponse) error {
+	connection, err := fs.GetIOConnection()
+	if err != nil {
+		return xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnIOConnection(connection)
+
+	connection.Lock()
+	defer connection.Unlock()
+
+	err = connection.RequestAndCheck(request, response, nil)
+	if err != nil {
+		if irodsclient_types.GetIRODSErrorCode(err) == irodsclient_common.CAT_NO_ROWS_FOUND {
+			return irodsclient_types.NewFileNotFoundError(path)
+		}
+		return err
+	}
+
+	// replica info is cached in the file system
+	fs.ClearCache()
+	return nil
+}
+
+// phymvRequest is a data object physical move request, go-irodsclient does not provide it
+type phymvRequest irodsclient_message.IRODSMessageDataObjectRequest
+
+func (msg *phymvRequest) GetMessage() (*irodsclient_message.IRODSMessage, error) {
+	bytes, err := xml.Marshal(msg)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal irods message to xml: %w", err)
+	}
+
+	msgBody := irodsclient_message.IRODSMessageBody{
+		Type:    irodsclient_message.RODS_MESSAGE_API_REQ_TYPE,
+		Message: bytes,
+		Error:   nil,
+		Bs:      nil,
+		IntInfo: int32(irodsclient_common.DATA_OBJ_PHYMV_AN),
+	}
+
+	msgHeader, err := msgBody.BuildHeader()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to build header from irods message: %w", err)
+	}
+
+	return &irodsclient_message.IRODSMessage{
+		Header: msgHeader,
+		Body:   &msgBody,
+	}, nil
+}
+
+// IsReplicaInResource checks if the replica is stored in the resource or in its child resources
+func IsReplicaInResource(replica *irodsclient_types.IRODSReplica, resource string) bool {
+	if replica.ResourceName == resource {
+		return true
+	}
+
+	for _, resourceName := range strings.Split(replica.ResourceHierarchy, ";") {
+		if resourceName == resource {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,241 @@
+package commons
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_common "github.com/cyverse/go-irodsclient/irods/common"
+	irodsclient_message "github.com/cyverse/go-irodsclient/irods/message"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	irodsclient_util "github.com/cyverse/go-irodsclient/irods/util"
+	"golang.org/x/xerrors"
+)
+
+// ResourceStatusDown is the status of a resource marked down by admins, an empty status means up
+const ResourceStatusDown string = "down"
+
+// Resource is a storage resource, go-irodsclient's IRODSResource does not have status, free space and hierarchy
+type Resource struct {
+	ID         int64
+	Name       string
+	Zone       string
+	Type       string
+	Class      string
+	Location   string
+	Path       string
+	FreeSpace  string
+	Info       string
+	Comment    string
+	Status     string
+	Context    string
+	Parent     string
+	CreateTime time.Time
+	ModifyTime time.Time
+
+	Children []*Resource
+}
+
+// GetFreeSpace returns free space in bytes, it returns -1 if free space is not set
+func (resc *Resource) GetFreeSpace() int64 {
+	freeSpace, err := strconv.ParseInt(resc.FreeSpace, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return freeSpace
+}
+
+// IsRoot checks if the resource has no parent
+func (resc *Resource) IsRoot() bool {
+	return len(resc.Parent) == 0
+}
+
+// ListResources lists all resources in the zone, children of each resource are filled
+func ListResources(fs *irodsclient_fs.FileSystem) ([]*Resource, error) {
+	connection, err := fs.GetMetadataConnection()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnMetadataConnection(connection)
+
+	connection.Lock()
+	defer connection.Unlock()
+
+	resources := []*Resource{}
+
+	continueIndex := 0
+	for {
+		query := irodsclient_message.NewIRODSMessageQueryRequest(irodsclient_common.MaxQueryRows, continueIndex, 0, 0)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_RESC_ID, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_RESC_NAME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_ZONE_NAME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_TYPE_NAME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_CLASS_NAME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_LOC, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_VAULT_PATH, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_FREE_SPACE, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_RESC_INFO, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_RESC_COMMENT, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_RESC_STATUS, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_RESC_CONTEXT, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_RESC_PARENT, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_CREATE_TIME, 1)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_R_MODIFY_TIME, 1)
+
+		queryResult := irodsclient_message.IRODSMessageQueryResponse{}
+		err = connection.Request(query, &queryResult, nil)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to receive a resource query result message: %w", err)
+		}
+
+		err = queryResult.CheckError()
+		if err != nil {
+			if irodsclient_types.GetIRODSErrorCode(err) == irodsclient_common.CAT_NO_ROWS_FOUND {
+				// empty
+				break
+			}
+			return nil, xerrors.Errorf("received a resource query error: %w", err)
+		}
+
+		pagenatedResources := make([]*Resource, queryResult.RowCount)
+		for row := 0; row < queryResult.RowCount; row++ {
+			pagenatedResources[row] = &Resource{
+				ID:       -1,
+				Children: []*Resource{},
+			}
+		}
+
+		for _, sqlResult := range queryResult.SQLResult {
+			if len(sqlResult.Values) != queryResult.RowCount {
+				return nil, xerrors.Errorf("failed to receive resource rows - requires %d, but received %d attributes", queryResult.RowCount, len(sqlResult.Values))
+			}
+
+			for row, value := range sqlResult.Values {
+				switch sqlResult.AttributeIndex {
+				case int(irodsclient_common.ICAT_COLUMN_R_RESC_ID):
+					rescID, err := strconv.ParseInt(value, 10, 64)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse resource id '%s': %w", value, err)
+					}
+					pagenatedResources[row].ID = rescID
+				case int(irodsclient_common.ICAT_COLUMN_R_RESC_NAME):
+					pagenatedResources[row].Name = value
+				case int(irodsclient_common.ICAT_COLUMN_R_ZONE_NAME):
+					pagenatedResources[row].Zone = value
+				case int(irodsclient_common.ICAT_COLUMN_R_TYPE_NAME):
+					pagenatedResources[row].Type = value
+				case int(irodsclient_common.ICAT_COLUMN_R_CLASS_NAME):
+					pagenatedResources[row].Class = value
+				case int(irodsclient_common.ICAT_COLUMN_R_LOC):
+					pagenatedResources[row].Location = value
+				case int(irodsclient_common.ICAT_COLUMN_R_VAULT_PATH):
+					pagenatedResources[row].Path = value
+				case int(irodsclient_common.ICAT_COLUMN_R_FREE_SPACE):
+					pagenatedResources[row].FreeSpace = value
+				case int(irodsclient_common.ICAT_COLUMN_R_RESC_INFO):
+					pagenatedResources[row].Info = value
+				case int(irodsclient_common.ICAT_COLUMN_R_RESC_COMMENT):
+					pagenatedResources[row].Comment = value
+				case int(irodsclient_common.ICAT_COLUMN_R_RESC_STATUS):
+					pagenatedResources[row].Status = value
+				case int(irodsclient_common.ICAT_COLUMN_R_RESC_CONTEXT):
+					pagenatedResources[row].Context = value
+				case int(irodsclient_common.ICAT_COLUMN_R_RESC_PARENT):
+					pagenatedResources[row].Parent = value
+				case int(irodsclient_common.ICAT_COLUMN_R_CREATE_TIME):
+					cT, err := irodsclient_util.GetIRODSDateTime(value)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse create time '%s': %w", value, err)
+					}
+					pagenatedResources[row].CreateTime = cT
+				case int(irodsclient_common.ICAT_COLUMN_R_MODIFY_TIME):
+					mT, err := irodsclient_util.GetIRODSDateTime(value)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse modify time '%s': %w", value, err)
+					}
+					pagenatedResources[row].ModifyTime = mT
+				}
+			}
+		}
+
+		resources = append(resources, pagenatedResources...)
+
+		continueIndex = queryResult.ContinueIndex
+		if continueIndex == 0 {
+			break
+		}
+	}
+
+	sort.SliceStable(resources, func(i int, j int) bool {
+		return resources[i].Name < resources[j].Name
+	})
+
+	linkResourceChildren(resources)
+
+	return resources, nil
+}
+
+// linkResourceChildren fills children of resources.
+// iRODS 4.2+ stores the parent resource id, older versions store the parent resource name.
+func linkResourceChildren(resources []*Resource) {
+	resourcesByID := map[string]*Resource{}
+	resourcesByName := map[string]*Resource{}
+	for _, resource := range resources {
+		resourcesByID[strconv.FormatInt(resource.ID, 10)] = resource
+		resourcesByName[resource.Name] = resource
+	}
+
+	for _, resource := range resources {
+		if resource.IsRoot() {
+			continue
+		}
+
+		parent, ok := resourcesByID[resource.Parent]
+		if !ok {
+			parent, ok = resourcesByName[resource.Parent]
+		}
+
+		if ok {
+			parent.Children = append(parent.Children, resource)
+		}
+	}
+}
+
+// GetResourceParent returns the parent resource of the given resource
+func GetResourceParent(resources []*Resource, resource *Resource) *Resource {
+	if resource.IsRoot() {
+		return nil
+	}
+
+	for _, parent := range resources {
+		for _, child := range parent.Children {
+			if child == resource {
+				return parent
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetRootResources returns resources that do not have a parent
+func GetRootResources(resources []*Resource) []*Resource {
+	roots := []*Resource{}
+	for _, resource := range resources {
+		if GetResourceParent(resources, resource) == nil {
+			roots = append(roots, resource)
+		}
+	}
+	return roots
+}
+
+// FindResource returns the resource with the given name
+func FindResource(resources []*Resource, name string) *Resource {
+	for _, resource := range resources {
+		if resource.Name == name {
+			return resource
+		}
+	}
+	return nil
+}
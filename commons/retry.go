@@ -1,135 +1,140 @@
 package commons
 
 import (
-	"fmt"
-	"os"
-	"os/exec"
-	"strings"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"syscall"
 	"time"
 
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/xerrors"
 )
 
-func RunWithRetry(retry int, retryInterval int) error {
-	logger := log.WithFields(log.Fields{
-		"package":  "commons",
-		"function": "RunWithRetry",
-	})
-
-	var err error
-	for i := 0; i < retry; i++ {
-		logger.Infof("running child process for retry #%d", i)
-		err = runChild()
-		if err == nil {
-			logger.Debug("completed child process successfully")
-			return nil
-		}
+// default values
+const (
+	RetryIntervalMaxDefault time.Duration = 5 * time.Minute
+)
 
-		logger.Errorf("%+v", err)
-		fmt.Fprintf(os.Stderr, "Error: %+v\n", err)
+var (
+	retryRand      *rand.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	retryRandMutex sync.Mutex
+)
 
-		logger.Errorf("Waiting %d seconds for next try...", retryInterval)
-		fmt.Fprintf(os.Stderr, "Waiting %d seconds for next try...", retryInterval)
+// RetryPolicy retries tasks failed with transient errors, with exponential backoff and jitter
+type RetryPolicy struct {
+	retryNumber     int
+	initialInterval time.Duration
+	maxInterval     time.Duration
+}
 
-		sleepTime := time.Duration(retryInterval * int(time.Second))
-		time.Sleep(sleepTime)
+// NewRetryPolicy creates a new RetryPolicy, the interval is doubled on each retry
+func NewRetryPolicy(retryNumber int, initialIntervalSeconds int) *RetryPolicy {
+	if retryNumber < 0 {
+		retryNumber = 0
 	}
 
-	if err != nil {
-		err = xerrors.Errorf("failed to run after %d retries, job failed: %w", retry, err)
-		logger.Errorf("%+v", err)
-		return err
+	initialInterval := time.Duration(initialIntervalSeconds) * time.Second
+	if initialInterval <= 0 {
+		initialInterval = time.Second
 	}
 
-	logger.Info("completed child process successfully")
-	return nil
+	return &RetryPolicy{
+		retryNumber:     retryNumber,
+		initialInterval: initialInterval,
+		maxInterval:     RetryIntervalMaxDefault,
+	}
 }
 
-func runChild() error {
-	envManager := GetEnvironmentManager()
-	env := envManager.Environment
-
-	configTypeIn := &ConfigTypeIn{
-		Host:     env.Host,
-		Port:     env.Port,
-		Zone:     env.Zone,
-		Username: env.Username,
-		Password: envManager.Password,
+// GetInterval returns the interval to wait before the retry, retry starts from 0.
+// The interval is picked randomly between the half and the full of the backoff, to not retry all at once.
+func (policy *RetryPolicy) GetInterval(retry int) time.Duration {
+	backoff := policy.initialInterval
+	for i := 0; i < retry && backoff < policy.maxInterval; i++ {
+		backoff *= 2
 	}
 
-	configTypeInYamlBytes, err := configTypeIn.ToYAML()
-	if err != nil {
-		yamlErr := xerrors.Errorf("failed to get config yaml: %w", err)
-		return yamlErr
+	if backoff > policy.maxInterval {
+		backoff = policy.maxInterval
 	}
 
-	bin := os.Args[0]
+	retryRandMutex.Lock()
+	jitter := time.Duration(retryRand.Int63n(int64(backoff/2) + 1))
+	retryRandMutex.Unlock()
 
-	newArgs := []string{}
-	hasForce := false
-	hasRetryChild := false
-	ignoreArgs := 0
-	for _, arg := range os.Args[1:] {
-		if ignoreArgs > 0 {
-			ignoreArgs--
-			continue
-		}
-		if strings.HasPrefix(arg, "--retry=") {
-			// ignore
-			continue
-		}
-		if arg == "--retry" {
-			ignoreArgs = 1
-			continue
-		}
-		if arg == "--force" || arg == "-f" {
-			hasForce = true
-		}
-		if arg == "--retry_child" {
-			hasRetryChild = true
-		}
+	return backoff/2 + jitter
+}
 
-		newArgs = append(newArgs, arg)
+// Run runs the task, and retries it if it fails with a transient error. A nil policy runs the task once.
+// Connections broken are discarded by the connection pool, so the task gets new connections on retry.
+func (policy *RetryPolicy) Run(name string, task func() error) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"struct":   "RetryPolicy",
+		"function": "Run",
+	})
+
+	err := task()
+	if policy == nil {
+		return err
 	}
 
-	if !hasRetryChild {
-		newArgs = append(newArgs, "--retry_child")
+	for retry := 0; retry < policy.retryNumber; retry++ {
+		if err == nil || !IsTransientError(err) {
+			return err
+		}
+
+		interval := policy.GetInterval(retry)
+		logger.WithError(err).Warnf("failed to run %s, retrying #%d in %s", name, retry+1, interval)
+
+		time.Sleep(interval)
+		err = task()
 	}
-	if !hasForce {
-		newArgs = append(newArgs, "--force")
+
+	if err != nil && policy.retryNumber > 0 && IsTransientError(err) {
+		return xerrors.Errorf("failed to run %s after %d retries: %w", name, policy.retryNumber, err)
 	}
 
-	cmd := exec.Command(bin, newArgs...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	return err
+}
+
+var (
+	// socket errors that may not occur again on a new connection
+	transientErrnos []syscall.Errno = []syscall.Errno{syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED, syscall.EPIPE, syscall.ETIMEDOUT, syscall.EHOSTUNREACH, syscall.ENETUNREACH}
+)
+
+// IsTransientError checks if the error is caused by a connection failure or timeout, that may not occur again
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
 
-	stdinPipe, err := cmd.StdinPipe()
-	if err != nil {
-		pipeErr := xerrors.Errorf("failed to get stdin pipe: %w", err)
-		return pipeErr
+	if irodsclient_types.IsPermanantFailure(err) {
+		return false
 	}
 
-	// start
-	err = cmd.Start()
-	if err != nil {
-		cmdErr := xerrors.Errorf("failed to start the child process: %w", err)
-		return cmdErr
+	if irodsclient_types.IsConnectionError(err) || irodsclient_types.IsConnectionPoolFullError(err) {
+		return true
 	}
 
-	// send config to stdin
-	_, err = stdinPipe.Write(configTypeInYamlBytes)
-	if err != nil {
-		writeErr := xerrors.Errorf("failed to send config yaml to stdin pipe: %w", err)
-		return writeErr
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
 	}
 
-	stdinPipe.Close()
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		return true
+	}
 
-	err = cmd.Wait()
-	if err != nil {
-		cmdErr := xerrors.Errorf("failed to wait the child process: %w", err)
-		return cmdErr
+	// errors are matched by their types, not by messages, so errors without a cause are not retried
+	for _, errno := range transientErrnos {
+		if errors.Is(err, errno) {
+			return true
+		}
 	}
-	return nil
+
+	return false
 }
@@ -0,0 +1,88 @@
+package commons
+
+import (
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestRetry(t *testing.T) {
+	t.Run("test IsTransientError", testIsTransientError)
+	t.Run("test RetryPolicy", testRetryPolicy)
+}
+
+func testIsTransientError(t *testing.T) {
+	assert.False(t, IsTransientError(nil))
+	assert.True(t, IsTransientError(xerrors.Errorf("failed to connect: %w", irodsclient_types.NewConnectionError())))
+	assert.True(t, IsTransientError(xerrors.Errorf("failed to read: %w", io.ErrUnexpectedEOF)))
+	assert.False(t, IsTransientError(xerrors.Errorf("failed to login: %w", irodsclient_types.NewAuthError(&irodsclient_types.IRODSAccount{}))))
+	assert.False(t, IsTransientError(irodsclient_types.NewFileNotFoundError("/zone/home/user/file.txt")))
+
+	// socket errors are matched by errno, not by messages
+	opErr := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	assert.True(t, IsTransientError(xerrors.Errorf("failed to receive data: %w", opErr)))
+	assert.True(t, IsTransientError(xerrors.Errorf("failed to send data: %w", net.ErrClosed)))
+	assert.False(t, IsTransientError(xerrors.Errorf("failed to send data - socket closed")))
+}
+
+func testRetryPolicy(t *testing.T) {
+	policy := &RetryPolicy{
+		retryNumber:     3,
+		initialInterval: time.Millisecond,
+		maxInterval:     4 * time.Millisecond,
+	}
+
+	for retry := 0; retry < 5; retry++ {
+		interval := policy.GetInterval(retry)
+		assert.LessOrEqual(t, interval, 4*time.Millisecond)
+	}
+	assert.GreaterOrEqual(t, policy.GetInterval(10), 2*time.Millisecond)
+
+	// transient errors are retried
+	calls := 0
+	err := policy.Run("test", func() error {
+		calls++
+		if calls < 3 {
+			return irodsclient_types.NewConnectionError()
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// gives up after retries
+	calls = 0
+	err = policy.Run("test", func() error {
+		calls++
+		return irodsclient_types.NewConnectionError()
+	})
+	assert.Error(t, err)
+	assert.True(t, irodsclient_types.IsConnectionError(err))
+	assert.Equal(t, 4, calls)
+
+	// other errors are not retried
+	calls = 0
+	err = policy.Run("test", func() error {
+		calls++
+		return irodsclient_types.NewFileNotFoundError("/zone/home/user/file.txt")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	// nil policy runs once
+	var nilPolicy *RetryPolicy
+	calls = 0
+	err = nilPolicy.Run("test", func() error {
+		calls++
+		return irodsclient_types.NewConnectionError()
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
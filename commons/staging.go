@@ -1,6 +1,7 @@
 package commons
 
 import (
+	"encoding/hex"
 	"fmt"
 	"path"
 	"strings"
@@ -12,17 +13,44 @@ import (
 )
 
 func GetBundleFilename(hash string) string {
-	return fmt.Sprintf("bundle_%s.tar", hash)
+	return GetBundleFilenameForFormat(hash, BundleFormatTar)
+}
+
+func GetBundleFilenameForFormat(hash string, format string) string {
+	return fmt.Sprintf("bundle_%s%s", hash, GetBundleFileExtension(format))
 }
 
 func IsBundleFilename(p string) bool {
-	if strings.HasPrefix(p, "bundle_") && strings.HasPrefix(p, ".tar") {
-		return true
+	if !strings.HasPrefix(p, "bundle_") {
+		return false
+	}
+
+	for _, format := range []string{BundleFormatTar, BundleFormatTgz, BundleFormatTbz2, BundleFormatZip} {
+		if strings.HasSuffix(p, GetBundleFileExtension(format)) {
+			return true
+		}
 	}
 	return false
 }
 
-func ValidateStagingDir(fs *irodsclient_fs.FileSystem, targetPath string, stagingPath string) (bool, error) {
+// IsBundleStagingDirname checks if the name is a name of a staging collection, data-objects are copied to it to bundle them in iRODS for downloads
+func IsBundleStagingDirname(p string) bool {
+	if !strings.HasPrefix(p, "bundle_") {
+		return false
+	}
+
+	hash := strings.TrimPrefix(p, "bundle_")
+	if len(hash) != 32 {
+		return false
+	}
+
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+// ValidateStagingDir checks if the staging dir is in the same root resource as the target path.
+// It also returns root resources that can be used for staging, to tell users where to put the staging dir.
+func ValidateStagingDir(fs *irodsclient_fs.FileSystem, targetPath string, stagingPath string) (bool, []string, error) {
 	logger := log.WithFields(log.Fields{
 		"package":  "commons",
 		"function": "ValidateStagingDir",
@@ -30,14 +58,14 @@ func ValidateStagingDir(fs *irodsclient_fs.FileSystem, targetPath string, stagin
 
 	stagingResourceServers, err := GetResourceServers(fs, stagingPath)
 	if err != nil {
-		return false, xerrors.Errorf("failed to get resource servers for %s: %w", stagingPath, err)
+		return false, nil, xerrors.Errorf("failed to get resource servers for %s: %w", stagingPath, err)
 	}
 
 	logger.Debugf("staging resource servers - %v", stagingResourceServers)
 
 	targetResourceServers, err := GetResourceServers(fs, targetPath)
 	if err != nil {
-		return false, xerrors.Errorf("failed to get resource servers for %s: %w", targetPath, err)
+		return false, nil, xerrors.Errorf("failed to get resource servers for %s: %w", targetPath, err)
 	}
 
 	logger.Debugf("target resource servers - %v", targetResourceServers)
@@ -46,12 +74,52 @@ func ValidateStagingDir(fs *irodsclient_fs.FileSystem, targetPath string, stagin
 		for _, targetResourceServer := range targetResourceServers {
 			if stagingResourceServer == targetResourceServer {
 				// same resource server
-				return true, nil
+				return true, targetResourceServers, nil
 			}
 		}
 	}
 
-	return false, nil
+	stagingResources, err := GetStagingResources(fs, targetResourceServers)
+	if err != nil {
+		return false, nil, xerrors.Errorf("failed to get staging resources for %s: %w", targetPath, err)
+	}
+
+	return false, stagingResources, nil
+}
+
+// GetStagingResources returns root resources that bundles can be staged in for the target resource servers.
+// Bundles are extracted in the root resource of the target, so only the roots that are up are returned.
+func GetStagingResources(fs *irodsclient_fs.FileSystem, targetResourceServers []string) ([]string, error) {
+	resources, err := ListResources(fs)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to list resources: %w", err)
+	}
+
+	stagingResources := []string{}
+	for _, targetResourceServer := range targetResourceServers {
+		resource := FindResource(resources, targetResourceServer)
+		if resource == nil || !resource.IsRoot() {
+			continue
+		}
+
+		if resource.Status == ResourceStatusDown {
+			continue
+		}
+
+		found := false
+		for _, stagingResource := range stagingResources {
+			if stagingResource == resource.Name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			stagingResources = append(stagingResources, resource.Name)
+		}
+	}
+
+	return stagingResources, nil
 }
 
 func GetDefaultStagingDirInTargetPath(targetPath string) string {
@@ -100,6 +168,11 @@ func GetDefaultStagingDir(targetPath string) string {
 	return GetDefaultStagingDirInTargetPath(targetPath)
 }
 
+// GetDefaultStagingDirForDownload returns a staging dir in the home dir, as the source collection may not be writable
+func GetDefaultStagingDirForDownload() string {
+	return GetDefaultStagingDirInTargetPath(GetHomeDir())
+}
+
 func GetResourceServers(fs *irodsclient_fs.FileSystem, targetDir string) ([]string, error) {
 	connection, err := fs.GetMetadataConnection()
 	if err != nil {
@@ -0,0 +1,178 @@
+package commons
+
+import (
+	"time"
+
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+)
+
+const (
+	EntryInfoTypeDataObject string = "dataObject"
+	EntryInfoTypeCollection string = "collection"
+)
+
+// DataObjectInfo has complete information of a data object for machine-readable output
+type DataObjectInfo struct {
+	Type         string         `json:"type"`
+	ID           int64          `json:"id"`
+	CollectionID int64          `json:"collectionId"`
+	Path         string         `json:"path"`
+	Name         string         `json:"name"`
+	Size         int64          `json:"size"`
+	DataType     string         `json:"dataType"`
+	Owner        string         `json:"owner"`
+	CreateTime   time.Time      `json:"createTime"`
+	ModifyTime   time.Time      `json:"modifyTime"`
+	Replicas     []*ReplicaInfo `json:"replicas"`
+	ACL          []*AccessInfo  `json:"acl,omitempty"`
+	Metadata     []*MetaInfo    `json:"metadata,omitempty"`
+}
+
+// ReplicaInfo has complete information of a data object replica for machine-readable output
+type ReplicaInfo struct {
+	Number            int64     `json:"number"`
+	Owner             string    `json:"owner"`
+	Status            string    `json:"status"`
+	ResourceName      string    `json:"resourceName"`
+	ResourceHierarchy string    `json:"resourceHierarchy"`
+	PhysicalPath      string    `json:"physicalPath"`
+	Checksum          string    `json:"checksum"`
+	ChecksumAlgorithm string    `json:"checksumAlgorithm"`
+	CreateTime        time.Time `json:"createTime"`
+	ModifyTime        time.Time `json:"modifyTime"`
+}
+
+// CollectionInfo has complete information of a collection for machine-readable output
+type CollectionInfo struct {
+	Type       string        `json:"type"`
+	ID         int64         `json:"id"`
+	Path       string        `json:"path"`
+	Name       string        `json:"name"`
+	Owner      string        `json:"owner"`
+	CreateTime time.Time     `json:"createTime"`
+	ModifyTime time.Time     `json:"modifyTime"`
+	ACL        []*AccessInfo `json:"acl,omitempty"`
+	Metadata   []*MetaInfo   `json:"metadata,omitempty"`
+}
+
+// AccessInfo is an access control entry for machine-readable output
+type AccessInfo struct {
+	UserName    string `json:"userName"`
+	UserZone    string `json:"userZone"`
+	UserType    string `json:"userType"`
+	AccessLevel string `json:"accessLevel"`
+}
+
+// MetaInfo is an AVU for machine-readable output
+type MetaInfo struct {
+	ID        int64  `json:"id"`
+	Attribute string `json:"attribute"`
+	Value     string `json:"value"`
+	Units     string `json:"units"`
+}
+
+// NewDataObjectInfo creates DataObjectInfo, accesses and metas can be nil.
+// The owner is the owner of the first replica, create and modify times are the earliest and the latest times of replicas.
+func NewDataObjectInfo(entry *irodsclient_types.IRODSDataObject, accesses []*irodsclient_types.IRODSAccess, metas []*irodsclient_types.IRODSMeta) *DataObjectInfo {
+	owner := ""
+	createTime := time.Time{}
+	modifyTime := time.Time{}
+
+	replicas := []*ReplicaInfo{}
+	for idx, replica := range entry.Replicas {
+		if idx == 0 {
+			owner = replica.Owner
+		}
+
+		if createTime.IsZero() || replica.CreateTime.Before(createTime) {
+			createTime = replica.CreateTime
+		}
+
+		if replica.ModifyTime.After(modifyTime) {
+			modifyTime = replica.ModifyTime
+		}
+
+		replicaInfo := &ReplicaInfo{
+			Number:            replica.Number,
+			Owner:             replica.Owner,
+			Status:            replica.Status,
+			ResourceName:      replica.ResourceName,
+			ResourceHierarchy: replica.ResourceHierarchy,
+			PhysicalPath:      replica.Path,
+			CreateTime:        replica.CreateTime,
+			ModifyTime:        replica.ModifyTime,
+		}
+
+		if replica.Checksum != nil {
+			replicaInfo.Checksum = replica.Checksum.OriginalChecksum
+			replicaInfo.ChecksumAlgorithm = string(replica.Checksum.Algorithm)
+		}
+
+		replicas = append(replicas, replicaInfo)
+	}
+
+	return &DataObjectInfo{
+		Type:         EntryInfoTypeDataObject,
+		ID:           entry.ID,
+		CollectionID: entry.CollectionID,
+		Path:         entry.Path,
+		Name:         entry.Name,
+		Size:         entry.Size,
+		DataType:     entry.DataType,
+		Owner:        owner,
+		CreateTime:   createTime,
+		ModifyTime:   modifyTime,
+		Replicas:     replicas,
+		ACL:          newAccessInfos(accesses),
+		Metadata:     newMetaInfos(metas),
+	}
+}
+
+// NewCollectionInfo creates CollectionInfo, accesses and metas can be nil
+func NewCollectionInfo(entry *irodsclient_types.IRODSCollection, accesses []*irodsclient_types.IRODSAccess, metas []*irodsclient_types.IRODSMeta) *CollectionInfo {
+	return &CollectionInfo{
+		Type:       EntryInfoTypeCollection,
+		ID:         entry.ID,
+		Path:       entry.Path,
+		Name:       entry.Name,
+		Owner:      entry.Owner,
+		CreateTime: entry.CreateTime,
+		ModifyTime: entry.ModifyTime,
+		ACL:        newAccessInfos(accesses),
+		Metadata:   newMetaInfos(metas),
+	}
+}
+
+func newAccessInfos(accesses []*irodsclient_types.IRODSAccess) []*AccessInfo {
+	if accesses == nil {
+		return nil
+	}
+
+	accessInfos := []*AccessInfo{}
+	for _, access := range accesses {
+		accessInfos = append(accessInfos, &AccessInfo{
+			UserName:    access.UserName,
+			UserZone:    access.UserZone,
+			UserType:    string(access.UserType),
+			AccessLevel: string(access.AccessLevel),
+		})
+	}
+	return accessInfos
+}
+
+func newMetaInfos(metas []*irodsclient_types.IRODSMeta) []*MetaInfo {
+	if metas == nil {
+		return nil
+	}
+
+	metaInfos := []*MetaInfo{}
+	for _, meta := range metas {
+		metaInfos = append(metaInfos, &MetaInfo{
+			ID:        meta.AVUID,
+			Attribute: meta.Name,
+			Value:     meta.Value,
+			Units:     meta.Units,
+		})
+	}
+	return metaInfos
+}
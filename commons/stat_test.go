@@ -0,0 +1,37 @@
+package commons
+
+import (
+	"testing"
+	"time"
+
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStat(t *testing.T) {
+	t.Run("test NewDataObjectInfo", testNewDataObjectInfo)
+}
+
+func testNewDataObjectInfo(t *testing.T) {
+	time1 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	time2 := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	time3 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	entry := &irodsclient_types.IRODSDataObject{
+		ID:   1,
+		Path: "/zone/home/user/a.txt",
+		Name: "a.txt",
+		Size: 10,
+		Replicas: []*irodsclient_types.IRODSReplica{
+			{Number: 0, Owner: "user", CreateTime: time2, ModifyTime: time2},
+			{Number: 1, Owner: "admin", CreateTime: time1, ModifyTime: time3},
+		},
+	}
+
+	info := NewDataObjectInfo(entry, nil, nil)
+	assert.Equal(t, "user", info.Owner)
+	assert.Equal(t, time1, info.CreateTime)
+	assert.Equal(t, time3, info.ModifyTime)
+	assert.Len(t, info.Replicas, 2)
+	assert.Nil(t, info.ACL)
+}
@@ -0,0 +1,100 @@
+package commons
+
+import (
+	"io"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_irodsfs "github.com/cyverse/go-irodsclient/irods/fs"
+	"golang.org/x/xerrors"
+)
+
+// UploadFromReader writes all data from the reader to a data object, existing data object is overwritten.
+// The size of data is not known in advance, so data is written in large buffered chunks.
+func UploadFromReader(fs *irodsclient_fs.FileSystem, r io.Reader, targetPath string, resource string) (int64, error) {
+	return uploadFromReader(fs, r, targetPath, resource, -1, nil)
+}
+
+// uploadFromReader writes all data from the reader to a data object, total is only for callback, -1 if unknown
+func uploadFromReader(fs *irodsclient_fs.FileSystem, r io.Reader, targetPath string, resource string, total int64, callback TrackerCallBack) (int64, error) {
+	fh, err := fs.CreateFile(targetPath, resource, "w")
+	if err != nil {
+		return 0, xerrors.Errorf("failed to create file %s: %w", targetPath, err)
+	}
+
+	if callback != nil {
+		callback(0, total)
+	}
+
+	buf := make([]byte, ReadBufferSizeDefault)
+	totalWritten := int64(0)
+	for {
+		// fill the buffer to make writes large
+		readLen, readErr := io.ReadFull(r, buf)
+		if readLen > 0 {
+			_, err := fh.Write(buf[:readLen])
+			if err != nil {
+				fh.Close()
+				return totalWritten, xerrors.Errorf("failed to write to %s: %w", targetPath, err)
+			}
+
+			totalWritten += int64(readLen)
+
+			if callback != nil {
+				callback(totalWritten, total)
+			}
+		}
+
+		if readErr != nil {
+			if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+				break
+			}
+
+			fh.Close()
+			return totalWritten, xerrors.Errorf("failed to read: %w", readErr)
+		}
+	}
+
+	err = fh.Close()
+	if err != nil {
+		return totalWritten, xerrors.Errorf("failed to close file %s: %w", targetPath, err)
+	}
+
+	return totalWritten, nil
+}
+
+// DownloadToWriter writes all data of a data object to the writer
+func DownloadToWriter(fs *irodsclient_fs.FileSystem, sourcePath string, w io.Writer) (int64, error) {
+	fh, err := fs.OpenFile(sourcePath, "", "r")
+	if err != nil {
+		return 0, xerrors.Errorf("failed to open file %s: %w", sourcePath, err)
+	}
+
+	defer fh.Close()
+
+	written, err := CopyRange(w, fh, 0, -1)
+	if err != nil {
+		return written, xerrors.Errorf("failed to read %s: %w", sourcePath, err)
+	}
+
+	return written, nil
+}
+
+// GetDownloadResumedSize returns the size downloaded before for the resumable download to the local path
+func GetDownloadResumedSize(localPath string, size int64) int64 {
+	transferStatusLocal, err := irodsclient_irodsfs.GetDataObjectTransferStatusLocal(localPath)
+	if err != nil {
+		return 0
+	}
+
+	transferStatus := transferStatusLocal.GetStatus()
+	if !transferStatus.Validate(localPath, size) {
+		return 0
+	}
+
+	resumedSize := int64(0)
+	for _, transferStatusEntry := range transferStatus.StatusMap {
+		resumedSize += transferStatusEntry.CompletedLength
+	}
+
+	return resumedSize
+}
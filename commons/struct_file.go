@@ -0,0 +1,85 @@
+package commons
+
+import (
+	irodsclient_common "github.com/cyverse/go-irodsclient/irods/common"
+	irodsclient_conn "github.com/cyverse/go-irodsclient/irods/connection"
+	irodsclient_message "github.com/cyverse/go-irodsclient/irods/message"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"golang.org/x/xerrors"
+)
+
+// structFileBundleRequest is a request to bundle a collection into a struct file.
+// It has the same body as the extraction request, but a different API number.
+type structFileBundleRequest struct {
+	*irodsclient_message.IRODSMessageExtractStructFileRequest
+}
+
+// GetMessage builds a message
+func (request *structFileBundleRequest) GetMessage() (*irodsclient_message.IRODSMessage, error) {
+	bytes, err := request.GetBytes()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get bytes from irods message: %w", err)
+	}
+
+	msgBody := irodsclient_message.IRODSMessageBody{
+		Type:    irodsclient_message.RODS_MESSAGE_API_REQ_TYPE,
+		Message: bytes,
+		Error:   nil,
+		Bs:      nil,
+		IntInfo: int32(irodsclient_common.STRUCT_FILE_BUNDLE_AN),
+	}
+
+	msgHeader, err := msgBody.BuildHeader()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to build header from irods message: %w", err)
+	}
+
+	return &irodsclient_message.IRODSMessage{
+		Header: msgHeader,
+		Body:   &msgBody,
+	}, nil
+}
+
+// CreateStructFile bundles all data-objects in the collection into a struct file at the path on the server side, like "ibun -c".
+// A new connection is used because the server may take long to create the struct file.
+func CreateStructFile(account *irodsclient_types.IRODSAccount, path string, sourceCollection string, resource string, dataType irodsclient_types.DataType, force bool) error {
+	switch dataType {
+	case irodsclient_types.TAR_FILE_DT, irodsclient_types.GZIP_TAR_DT, irodsclient_types.BZIP2_TAR_DT, irodsclient_types.ZIP_FILE_DT:
+		// pass
+	default:
+		return xerrors.Errorf("failed to create a struct file of unsupported data type %s", dataType)
+	}
+
+	conn, err := GetIRODSConnection(account)
+	if err != nil {
+		return xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Disconnect()
+
+	return createStructFile(conn, path, sourceCollection, resource, dataType, force)
+}
+
+func createStructFile(conn *irodsclient_conn.IRODSConnection, path string, sourceCollection string, resource string, dataType irodsclient_types.DataType, force bool) error {
+	conn.Lock()
+	defer conn.Unlock()
+
+	// use default resource when resource param is empty
+	if len(resource) == 0 {
+		resource = conn.GetAccount().DefaultResource
+	}
+
+	request := &structFileBundleRequest{
+		IRODSMessageExtractStructFileRequest: irodsclient_message.NewIRODSMessageExtractStructFileRequest(path, sourceCollection, resource, dataType, force, false),
+	}
+	response := irodsclient_message.IRODSMessageExtractStructFileResponse{}
+	err := conn.RequestAndCheck(request, &response, nil)
+	if err != nil {
+		if irodsclient_types.GetIRODSErrorCode(err) == irodsclient_common.CAT_NO_ROWS_FOUND {
+			return xerrors.Errorf("failed to find the collection %s: %w", sourceCollection, irodsclient_types.NewFileNotFoundError(sourceCollection))
+		}
+
+		return xerrors.Errorf("received create struct file error: %w", err)
+	}
+
+	return nil
+}
@@ -2,11 +2,15 @@ package commons
 
 import (
 	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/dsnet/compress/bzip2"
 	"golang.org/x/xerrors"
 )
 
@@ -25,6 +29,18 @@ func NewTarEntry(source string, target string) *TarEntry {
 }
 
 func Tar(baseDir string, sources []string, target string, callback TrackerCallBack) error {
+	_, err := TarWithHash(baseDir, sources, target, nil, callback)
+	return err
+}
+
+// TarWithHash is the same as Tar, but also computes hashes of source files while reading them if hashAlgs are given.
+// Returns a map of source file path to its hashes, a map of hash algorithm to hash in hex string.
+func TarWithHash(baseDir string, sources []string, target string, hashAlgs []string, callback TrackerCallBack) (map[string]map[string]string, error) {
+	return ArchiveWithHash(baseDir, sources, target, BundleFormatTar, hashAlgs, callback)
+}
+
+// ArchiveWithHash is the same as TarWithHash, but creates an archive in the given bundle format, compressed while it is written
+func ArchiveWithHash(baseDir string, sources []string, target string, format string, hashAlgs []string, callback TrackerCallBack) (map[string]map[string]string, error) {
 	entries := []*TarEntry{}
 
 	createdDirs := map[string]bool{}
@@ -33,15 +49,15 @@ func Tar(baseDir string, sources []string, target string, callback TrackerCallBa
 		sourceStat, err := os.Stat(source)
 		if err != nil {
 			if os.IsNotExist(err) {
-				return irodsclient_types.NewFileNotFoundError(source)
+				return nil, irodsclient_types.NewFileNotFoundError(source)
 			}
 
-			return xerrors.Errorf("failed to stat %s: %w", source, err)
+			return nil, xerrors.Errorf("failed to stat %s: %w", source, err)
 		}
 
 		rel, err := filepath.Rel(baseDir, source)
 		if err != nil {
-			return xerrors.Errorf("failed to compute relative path %s to %s: %w", source, baseDir, err)
+			return nil, xerrors.Errorf("failed to compute relative path %s to %s: %w", source, baseDir, err)
 		}
 
 		pdirs := GetParentLocalDirs(rel)
@@ -64,20 +80,22 @@ func Tar(baseDir string, sources []string, target string, callback TrackerCallBa
 		}
 	}
 
-	return makeTar(entries, target, callback)
+	return makeArchive(entries, target, format, hashAlgs, callback)
 }
 
-func makeTar(entries []*TarEntry, target string, callback TrackerCallBack) error {
+func makeArchive(entries []*TarEntry, target string, format string, hashAlgs []string, callback TrackerCallBack) (map[string]map[string]string, error) {
+	hashes := map[string]map[string]string{}
+
 	totalSize := int64(0)
 	currentSize := int64(0)
 	for _, entry := range entries {
 		sourceStat, err := os.Stat(entry.source)
 		if err != nil {
 			if os.IsNotExist(err) {
-				return irodsclient_types.NewFileNotFoundError(entry.source)
+				return nil, irodsclient_types.NewFileNotFoundError(entry.source)
 			}
 
-			return xerrors.Errorf("failed to stat %s: %w", entry.source, err)
+			return nil, xerrors.Errorf("failed to stat %s: %w", entry.source, err)
 		}
 
 		if !sourceStat.IsDir() {
@@ -89,50 +107,63 @@ func makeTar(entries []*TarEntry, target string, callback TrackerCallBack) error
 		callback(0, totalSize)
 	}
 
-	tarfile, err := os.Create(target)
+	archiveFile, err := os.Create(target)
 	if err != nil {
-		return xerrors.Errorf("failed to create file %s: %w", target, err)
+		return nil, xerrors.Errorf("failed to create file %s: %w", target, err)
 	}
 
-	defer tarfile.Close()
+	defer archiveFile.Close()
 
-	tarWriter := tar.NewWriter(tarfile)
-	defer tarWriter.Close()
+	archiveWriter, err := newArchiveWriter(archiveFile, format)
+	if err != nil {
+		return nil, err
+	}
+
+	defer archiveWriter.Close()
 
 	for _, entry := range entries {
 		sourceStat, err := os.Stat(entry.source)
 		if err != nil {
 			if os.IsNotExist(err) {
-				return irodsclient_types.NewFileNotFoundError(entry.source)
+				return nil, irodsclient_types.NewFileNotFoundError(entry.source)
 			}
 
-			return xerrors.Errorf("failed to stat %s: %w", entry.source, err)
+			return nil, xerrors.Errorf("failed to stat %s: %w", entry.source, err)
 		}
 
-		header, err := tar.FileInfoHeader(sourceStat, sourceStat.Name())
+		entryWriter, err := archiveWriter.WriteEntry(sourceStat, entry.target)
 		if err != nil {
-			return xerrors.Errorf("failed to create tar file info header: %w", err)
-		}
-
-		header.Name = entry.target
-
-		err = tarWriter.WriteHeader(header)
-		if err != nil {
-			return xerrors.Errorf("failed to write tar header: %w", err)
+			return nil, err
 		}
 
 		if !sourceStat.IsDir() {
 			// add file content
 			file, err := os.Open(entry.source)
 			if err != nil {
-				return xerrors.Errorf("failed to open tar file %s: %w", entry.source, err)
+				return nil, xerrors.Errorf("failed to open tar file %s: %w", entry.source, err)
 			}
 
 			defer file.Close()
 
-			_, err = io.Copy(tarWriter, file)
+			var writer io.Writer = entryWriter
+			var multiHash *MultiHash
+			if len(hashAlgs) > 0 {
+				multiHash, err = NewMultiHash(hashAlgs)
+				if err != nil {
+					return nil, err
+				}
+
+				// hash while reading to not read the file again for verification
+				writer = io.MultiWriter(entryWriter, multiHash)
+			}
+
+			_, err = io.Copy(writer, file)
 			if err != nil {
-				return xerrors.Errorf("failed to write tar file: %w", err)
+				return nil, xerrors.Errorf("failed to write tar file: %w", err)
+			}
+
+			if multiHash != nil {
+				hashes[entry.source] = multiHash.Sums()
 			}
 
 			currentSize += sourceStat.Size()
@@ -143,5 +174,218 @@ func makeTar(entries []*TarEntry, target string, callback TrackerCallBack) error
 		}
 	}
 
+	// flush compressed data
+	err = archiveWriter.Close()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to close archive %s: %w", target, err)
+	}
+
+	return hashes, nil
+}
+
+// archiveWriter writes entries to a TAR or a ZIP file
+type archiveWriter interface {
+	// WriteEntry writes a header of the entry, file content must be written to the returned writer
+	WriteEntry(stat os.FileInfo, name string) (io.Writer, error)
+	Close() error
+}
+
+func newArchiveWriter(w io.Writer, format string) (archiveWriter, error) {
+	switch format {
+	case BundleFormatTar:
+		return &tarArchiveWriter{
+			tarWriter: tar.NewWriter(w),
+		}, nil
+	case BundleFormatTgz:
+		gzipWriter := gzip.NewWriter(w)
+		return &tarArchiveWriter{
+			tarWriter:        tar.NewWriter(gzipWriter),
+			compressedWriter: gzipWriter,
+		}, nil
+	case BundleFormatTbz2:
+		bzip2Writer, err := bzip2.NewWriter(w, nil)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to create bzip2 writer: %w", err)
+		}
+
+		return &tarArchiveWriter{
+			tarWriter:        tar.NewWriter(bzip2Writer),
+			compressedWriter: bzip2Writer,
+		}, nil
+	case BundleFormatZip:
+		return &zipArchiveWriter{
+			zipWriter: zip.NewWriter(w),
+		}, nil
+	default:
+		return nil, xerrors.Errorf("unsupported bundle format %s", format)
+	}
+}
+
+type tarArchiveWriter struct {
+	tarWriter        *tar.Writer
+	compressedWriter io.WriteCloser
+	closed           bool
+}
+
+func (writer *tarArchiveWriter) WriteEntry(stat os.FileInfo, name string) (io.Writer, error) {
+	header, err := tar.FileInfoHeader(stat, stat.Name())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create tar file info header: %w", err)
+	}
+
+	header.Name = name
+
+	err = writer.tarWriter.WriteHeader(header)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to write tar header: %w", err)
+	}
+
+	return writer.tarWriter, nil
+}
+
+func (writer *tarArchiveWriter) Close() error {
+	if writer.closed {
+		return nil
+	}
+
+	writer.closed = true
+
+	err := writer.tarWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	if writer.compressedWriter != nil {
+		return writer.compressedWriter.Close()
+	}
+
+	return nil
+}
+
+type zipArchiveWriter struct {
+	zipWriter *zip.Writer
+	closed    bool
+}
+
+func (writer *zipArchiveWriter) WriteEntry(stat os.FileInfo, name string) (io.Writer, error) {
+	header, err := zip.FileInfoHeader(stat)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create zip file info header: %w", err)
+	}
+
+	header.Name = name
+	if stat.IsDir() {
+		header.Name += "/"
+	} else {
+		header.Method = zip.Deflate
+	}
+
+	entryWriter, err := writer.zipWriter.CreateHeader(header)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to write zip header: %w", err)
+	}
+
+	return entryWriter, nil
+}
+
+func (writer *zipArchiveWriter) Close() error {
+	if writer.closed {
+		return nil
+	}
+
+	writer.closed = true
+	return writer.zipWriter.Close()
+}
+
+// Untar extracts a TAR file to the target dir, existing files are overwritten.
+// Entries that are not regular files or dirs are ignored. Entries escaping the target dir are rejected.
+func Untar(source string, targetDir string, callback TrackerCallBack) error {
+	sourceStat, err := os.Stat(source)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return irodsclient_types.NewFileNotFoundError(source)
+		}
+
+		return xerrors.Errorf("failed to stat %s: %w", source, err)
+	}
+
+	totalSize := sourceStat.Size()
+	currentSize := int64(0)
+
+	if callback != nil {
+		callback(0, totalSize)
+	}
+
+	tarfile, err := os.Open(source)
+	if err != nil {
+		return xerrors.Errorf("failed to open file %s: %w", source, err)
+	}
+
+	defer tarfile.Close()
+
+	tarReader := tar.NewReader(tarfile)
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return xerrors.Errorf("failed to read tar header: %w", err)
+		}
+
+		targetPath := filepath.Join(targetDir, filepath.FromSlash(header.Name))
+		rel, err := filepath.Rel(targetDir, targetPath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return xerrors.Errorf("tar entry %s is out of target dir %s", header.Name, targetDir)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(targetPath, 0755)
+			if err != nil {
+				return xerrors.Errorf("failed to make dir %s: %w", targetPath, err)
+			}
+		case tar.TypeReg:
+			err = untarFile(tarReader, targetPath)
+			if err != nil {
+				return err
+			}
+
+			currentSize += header.Size
+		default:
+			continue
+		}
+
+		if callback != nil {
+			callback(currentSize, totalSize)
+		}
+	}
+
+	if callback != nil {
+		callback(totalSize, totalSize)
+	}
+
+	return nil
+}
+
+func untarFile(tarReader *tar.Reader, targetPath string) error {
+	err := os.MkdirAll(filepath.Dir(targetPath), 0755)
+	if err != nil {
+		return xerrors.Errorf("failed to make dir %s: %w", filepath.Dir(targetPath), err)
+	}
+
+	file, err := os.Create(targetPath)
+	if err != nil {
+		return xerrors.Errorf("failed to create file %s: %w", targetPath, err)
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(file, tarReader)
+	if err != nil {
+		return xerrors.Errorf("failed to write file %s: %w", targetPath, err)
+	}
+
 	return nil
 }
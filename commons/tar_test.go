@@ -0,0 +1,150 @@
+package commons
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTar(t *testing.T) {
+	t.Run("test TarWithHash", testTarWithHash)
+	t.Run("test Untar", testUntar)
+	t.Run("test ArchiveWithHash", testArchiveWithHash)
+}
+
+func testTarWithHash(t *testing.T) {
+	baseDir := t.TempDir()
+
+	sourcePath := filepath.Join(baseDir, "dir", "file.txt")
+	err := os.MkdirAll(filepath.Dir(sourcePath), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(sourcePath, []byte("hello world"), 0644)
+	assert.NoError(t, err)
+
+	targetPath := filepath.Join(t.TempDir(), "bundle.tar")
+	hashes, err := TarWithHash(baseDir, []string{sourcePath}, targetPath, []string{"SHA-256", "MD5"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(hashes))
+
+	expected, err := HashLocalFileHex(sourcePath, "SHA-256")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, GetHashForAlgorithm(hashes[sourcePath], "sha-256"))
+
+	expected, err = HashLocalFileHex(sourcePath, "MD5")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, GetHashForAlgorithm(hashes[sourcePath], "MD5"))
+
+	hashes, err = TarWithHash(baseDir, []string{sourcePath}, targetPath, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(hashes))
+}
+
+func testUntar(t *testing.T) {
+	baseDir := t.TempDir()
+
+	sourcePath := filepath.Join(baseDir, "dir", "file.txt")
+	err := os.MkdirAll(filepath.Dir(sourcePath), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(sourcePath, []byte("hello world"), 0644)
+	assert.NoError(t, err)
+
+	tarPath := filepath.Join(t.TempDir(), "bundle.tar")
+	err = Tar(baseDir, []string{sourcePath}, tarPath, nil)
+	assert.NoError(t, err)
+
+	targetDir := t.TempDir()
+	err = Untar(tarPath, targetDir, nil)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(targetDir, "dir", "file.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(content))
+}
+
+func testArchiveWithHash(t *testing.T) {
+	baseDir := t.TempDir()
+
+	sourcePath := filepath.Join(baseDir, "dir", "file.txt")
+	err := os.MkdirAll(filepath.Dir(sourcePath), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(sourcePath, []byte("hello world"), 0644)
+	assert.NoError(t, err)
+
+	expected, err := HashLocalFileHex(sourcePath, "SHA-256")
+	assert.NoError(t, err)
+
+	// tgz
+	tgzPath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	hashes, err := ArchiveWithHash(baseDir, []string{sourcePath}, tgzPath, BundleFormatTgz, []string{"SHA-256"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, hashes[sourcePath]["SHA-256"])
+
+	tgzFile, err := os.Open(tgzPath)
+	assert.NoError(t, err)
+	defer tgzFile.Close()
+
+	gzipReader, err := gzip.NewReader(tgzFile)
+	assert.NoError(t, err)
+
+	tarReader := tar.NewReader(gzipReader)
+	names := []string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, header.Name)
+	}
+	assert.Equal(t, []string{"dir", "dir/file.txt"}, names)
+
+	// zip
+	zipPath := filepath.Join(t.TempDir(), "bundle.zip")
+	_, err = ArchiveWithHash(baseDir, []string{sourcePath}, zipPath, BundleFormatZip, nil, nil)
+	assert.NoError(t, err)
+
+	zipReader, err := zip.OpenReader(zipPath)
+	assert.NoError(t, err)
+	defer zipReader.Close()
+
+	names = []string{}
+	for _, file := range zipReader.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"dir/", "dir/file.txt"}, names)
+
+	// tbz2
+	tbz2Path := filepath.Join(t.TempDir(), "bundle.tar.bz2")
+	hashes, err = ArchiveWithHash(baseDir, []string{sourcePath}, tbz2Path, BundleFormatTbz2, []string{"SHA-256"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, hashes[sourcePath]["SHA-256"])
+
+	tbz2File, err := os.Open(tbz2Path)
+	assert.NoError(t, err)
+	defer tbz2File.Close()
+
+	tarReader = tar.NewReader(bzip2.NewReader(tbz2File))
+	names = []string{}
+	contents := []string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, header.Name)
+
+		content, err := io.ReadAll(tarReader)
+		assert.NoError(t, err)
+		contents = append(contents, string(content))
+	}
+	assert.Equal(t, []string{"dir", "dir/file.txt"}, names)
+	assert.Equal(t, "hello world", contents[1])
+}
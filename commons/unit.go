@@ -23,6 +23,10 @@ func ParseSize(size string) (int64, error) {
 	size = strings.ToUpper(size)
 	size = strings.TrimSuffix(size, "B")
 
+	if len(size) == 0 {
+		return 0, xerrors.Errorf("failed to parse size, no number is given")
+	}
+
 	sizeNum := int64(0)
 	var err error
 
@@ -40,6 +40,15 @@ func testSize(t *testing.T) {
 	s6 := "256x"
 	_, err = ParseSize(s6)
 	assert.Error(t, err)
+
+	_, err = ParseSize("B")
+	assert.Error(t, err)
+
+	_, err = ParseSize("")
+	assert.Error(t, err)
+
+	_, err = ParseSize("200MiB")
+	assert.Error(t, err)
 }
 
 func testTime(t *testing.T) {
@@ -0,0 +1,717 @@
+package commons
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_common "github.com/cyverse/go-irodsclient/irods/common"
+	irodsclient_connection "github.com/cyverse/go-irodsclient/irods/connection"
+	irodsclient_irodsfs "github.com/cyverse/go-irodsclient/irods/fs"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	irodsclient_util "github.com/cyverse/go-irodsclient/irods/util"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/xerrors"
+)
+
+// default values
+const (
+	UploadChunkSizeDefault int64 = 64 * 1024 * 1024 // 64MB
+)
+
+// uploadStatusHeader is the first line of an upload status file
+type uploadStatusHeader struct {
+	LocalPath string `json:"local_path"`
+	IRODSPath string `json:"irods_path"`
+	Size      int64  `json:"size"`
+	ModTime   int64  `json:"mod_time"`
+	ChunkSize int64  `json:"chunk_size"`
+}
+
+// uploadStatusChunk is a line of an upload status file, appended when a chunk is written completely
+type uploadStatusChunk struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+// uploadStatusReplica is a line of an upload status file, appended when the replica to write is opened
+type uploadStatusReplica struct {
+	ResourceHierarchy string `json:"resource_hierarchy"`
+}
+
+// uploadStatus records chunks written to the data object to resume the upload
+type uploadStatus struct {
+	path              string
+	header            uploadStatusHeader
+	resourceHierarchy string
+	completed         map[int64]int64
+	file              *os.File
+	mutex             sync.Mutex
+}
+
+// GetUploadStatusFilename returns the upload status filename for the upload of the local file to the iRODS path.
+// The local user is a part of the name, as the status dir may be shared by users, e.g., /tmp.
+func GetUploadStatusFilename(localPath string, irodsPath string) (string, error) {
+	hash, err := HashStrings([]string{strconv.Itoa(os.Getuid()), localPath, irodsPath}, string(irodsclient_types.ChecksumAlgorithmMD5))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("upload_status_%s.status", hash), nil
+}
+
+// IsUploadStatusFilename checks if the filename is an upload status filename
+func IsUploadStatusFilename(p string) bool {
+	return strings.HasPrefix(p, "upload_status_") && strings.HasSuffix(p, ".status")
+}
+
+func newUploadStatusHeader(localPath string, irodsPath string, stat os.FileInfo) uploadStatusHeader {
+	return uploadStatusHeader{
+		LocalPath: localPath,
+		IRODSPath: irodsPath,
+		Size:      stat.Size(),
+		ModTime:   stat.ModTime().UnixNano(),
+		ChunkSize: UploadChunkSizeDefault,
+	}
+}
+
+// loadUploadStatus loads chunks recorded in the status file, no chunks are loaded if the file is for a different upload
+func loadUploadStatus(statusDirPath string, header uploadStatusHeader) (*uploadStatus, error) {
+	filename, err := GetUploadStatusFilename(header.LocalPath, header.IRODSPath)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get upload status filename for %s: %w", header.LocalPath, err)
+	}
+
+	status := &uploadStatus{
+		path:              filepath.Join(statusDirPath, filename),
+		header:            header,
+		resourceHierarchy: "",
+		completed:         map[int64]int64{},
+		file:              nil,
+		mutex:             sync.Mutex{},
+	}
+
+	statusFile, err := os.Open(status.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return status, nil
+		}
+
+		return nil, xerrors.Errorf("failed to open upload status file %s: %w", status.path, err)
+	}
+	defer statusFile.Close()
+
+	scanner := bufio.NewScanner(statusFile)
+	if !scanner.Scan() {
+		return status, nil
+	}
+
+	prevHeader := uploadStatusHeader{}
+	err = json.Unmarshal(scanner.Bytes(), &prevHeader)
+	if err != nil || prevHeader != header {
+		// the file or the target has changed
+		return status, nil
+	}
+
+	for scanner.Scan() {
+		line := struct {
+			uploadStatusChunk
+			uploadStatusReplica
+		}{}
+
+		err = json.Unmarshal(scanner.Bytes(), &line)
+		if err != nil {
+			// the last line may be cut when the process was killed
+			break
+		}
+
+		if len(line.ResourceHierarchy) > 0 {
+			status.resourceHierarchy = line.ResourceHierarchy
+		} else if line.Length > 0 {
+			status.completed[line.Offset] = line.Length
+		}
+	}
+
+	return status, nil
+}
+
+// canResume checks if the data object is kept as written in the previous run.
+// The replica written must exist, and the data object must be as large as chunks written but not larger than the file.
+func (status *uploadStatus) canResume(dataObject *irodsclient_types.IRODSDataObject) bool {
+	if len(status.completed) == 0 || len(status.resourceHierarchy) == 0 {
+		return false
+	}
+
+	writtenSize := int64(0)
+	for offset, length := range status.completed {
+		if offset+length > writtenSize {
+			writtenSize = offset + length
+		}
+	}
+
+	if dataObject.Size < writtenSize || dataObject.Size > status.header.Size {
+		return false
+	}
+
+	for _, replica := range dataObject.Replicas {
+		if replica.ResourceHierarchy == status.resourceHierarchy {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getChunks returns all chunks of the file in order
+func (status *uploadStatus) getChunks() []uploadStatusChunk {
+	chunks := []uploadStatusChunk{}
+
+	chunkSize := status.header.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = UploadChunkSizeDefault
+	}
+
+	for offset := int64(0); offset < status.header.Size; offset += chunkSize {
+		length := chunkSize
+		if offset+length > status.header.Size {
+			length = status.header.Size - offset
+		}
+
+		chunks = append(chunks, uploadStatusChunk{
+			Offset: offset,
+			Length: length,
+		})
+	}
+
+	return chunks
+}
+
+// getMissingChunks returns chunks of the file not written completely, and the size of chunks written
+func (status *uploadStatus) getMissingChunks() ([]uploadStatusChunk, int64) {
+	chunks := []uploadStatusChunk{}
+	writtenSize := int64(0)
+
+	for _, chunk := range status.getChunks() {
+		if completedLength, ok := status.completed[chunk.Offset]; ok && completedLength == chunk.Length {
+			writtenSize += chunk.Length
+			continue
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, writtenSize
+}
+
+// HasUploadStatus checks if the upload of the local file to the iRODS path can be resumed
+func HasUploadStatus(statusDirPath string, localPath string, irodsPath string) bool {
+	return GetUploadResumedSize(statusDirPath, localPath, irodsPath) > 0
+}
+
+// GetUploadResumedSize returns the size of chunks uploaded before for the upload of the local file to the iRODS path
+func GetUploadResumedSize(statusDirPath string, localPath string, irodsPath string) int64 {
+	stat, err := os.Stat(localPath)
+	if err != nil {
+		return 0
+	}
+
+	status, err := loadUploadStatus(statusDirPath, newUploadStatusHeader(localPath, irodsPath, stat))
+	if err != nil {
+		return 0
+	}
+
+	resumedSize := int64(0)
+	for _, length := range status.completed {
+		resumedSize += length
+	}
+
+	return resumedSize
+}
+
+// open opens the status file to record chunks, previous chunks are kept if resume is set
+func (status *uploadStatus) open(resume bool) error {
+	if resume {
+		statusFile, err := os.OpenFile(status.path, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return xerrors.Errorf("failed to open upload status file %s: %w", status.path, err)
+		}
+
+		status.file = statusFile
+		return nil
+	}
+
+	status.completed = map[int64]int64{}
+	status.resourceHierarchy = ""
+
+	err := os.MkdirAll(filepath.Dir(status.path), 0700)
+	if err != nil {
+		return xerrors.Errorf("failed to make dir %s: %w", filepath.Dir(status.path), err)
+	}
+
+	statusFile, err := os.OpenFile(status.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return xerrors.Errorf("failed to create upload status file %s: %w", status.path, err)
+	}
+
+	status.file = statusFile
+
+	err = status.writeLine(status.header)
+	if err != nil {
+		status.close()
+		return err
+	}
+
+	return nil
+}
+
+func (status *uploadStatus) writeLine(v interface{}) error {
+	lineBytes, err := json.Marshal(v)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal upload status: %w", err)
+	}
+
+	lineBytes = append(lineBytes, '\n')
+	_, err = status.file.Write(lineBytes)
+	if err != nil {
+		return xerrors.Errorf("failed to write upload status file %s: %w", status.path, err)
+	}
+
+	// the status must survive the process, chunks are large so this is cheap
+	err = status.file.Sync()
+	if err != nil {
+		return xerrors.Errorf("failed to sync upload status file %s: %w", status.path, err)
+	}
+
+	return nil
+}
+
+// markReplica records the replica opened to write chunks
+func (status *uploadStatus) markReplica(resourceHierarchy string) error {
+	status.mutex.Lock()
+	defer status.mutex.Unlock()
+
+	status.resourceHierarchy = resourceHierarchy
+	return status.writeLine(uploadStatusReplica{
+		ResourceHierarchy: resourceHierarchy,
+	})
+}
+
+// markCompleted records the chunk written completely
+func (status *uploadStatus) markCompleted(offset int64, length int64) error {
+	status.mutex.Lock()
+	defer status.mutex.Unlock()
+
+	status.completed[offset] = length
+	return status.writeLine(uploadStatusChunk{
+		Offset: offset,
+		Length: length,
+	})
+}
+
+func (status *uploadStatus) close() {
+	if status.file != nil {
+		status.file.Close()
+		status.file = nil
+	}
+}
+
+func (status *uploadStatus) remove() {
+	status.close()
+	os.Remove(status.path)
+}
+
+// UploadFileParallelResumable uploads a local file to the iRODS path in parallel.
+// Chunks written are recorded in a status file under statusDirPath,
+// so a retry after a failure writes only chunks missing to the existing data object.
+// Files of a single chunk and uploads to servers not supporting parallel upload are not recorded.
+// If hashAlg is given, the file is hashed while it is read for the upload, and the hash is returned in hex string.
+func UploadFileParallelResumable(fs *irodsclient_fs.FileSystem, localPath string, irodsPath string, resource string, taskNum int, statusDirPath string, hashAlg string, callback TrackerCallBack) (string, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "commons",
+		"function": "UploadFileParallelResumable",
+	})
+
+	stat, err := os.Stat(localPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", irodsclient_types.NewFileNotFoundError(localPath)
+		}
+
+		return "", xerrors.Errorf("failed to stat %s: %w", localPath, err)
+	}
+
+	if stat.IsDir() {
+		return "", xerrors.Errorf("failed to upload %s, the path is for a directory", localPath)
+	}
+
+	size := stat.Size()
+
+	if !fs.SupportParallelUpload() || size <= UploadChunkSizeDefault {
+		// a status file is not worth for a single chunk, and old servers do not support parallel upload.
+		// the upload is retried from the start
+		if len(hashAlg) > 0 {
+			return UploadFileWithHash(fs, localPath, irodsPath, resource, hashAlg, callback)
+		}
+
+		if !fs.SupportParallelUpload() {
+			return "", fs.UploadFile(localPath, irodsPath, resource, false, irodsclient_common.TrackerCallBack(callback))
+		}
+
+		return "", fs.UploadFileParallel(localPath, irodsPath, resource, taskNum, false, irodsclient_common.TrackerCallBack(callback))
+	}
+
+	var hashFunc hash.Hash
+	if len(hashAlg) > 0 {
+		hashFunc, err = NewHashFunc(hashAlg)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	numTasks := taskNum
+	if numTasks <= 0 {
+		numTasks = irodsclient_util.GetNumTasksForParallelTransfer(size)
+	}
+
+	status, err := loadUploadStatus(statusDirPath, newUploadStatusHeader(localPath, irodsPath, stat))
+	if err != nil {
+		return "", xerrors.Errorf("failed to load upload status for %s: %w", localPath, err)
+	}
+
+	resume := false
+	if len(status.completed) > 0 {
+		// the data object written previously must be kept as it was
+		dataObject, err := getDataObject(fs, irodsPath)
+		if err == nil && status.canResume(dataObject) {
+			resume = true
+		} else {
+			logger.Debugf("cannot resume uploading %s to %s, the data object has changed", localPath, irodsPath)
+		}
+	}
+
+	err = status.open(resume)
+	if err != nil {
+		return "", err
+	}
+	defer status.close()
+
+	conn, err := fs.GetIOConnection()
+	if err != nil {
+		return "", xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnIOConnection(conn)
+
+	// keep the data written when resuming
+	openMode := string(irodsclient_types.FileOpenModeWriteTruncate)
+	if resume {
+		logger.Debugf("resuming uploading %s to %s, %d chunks done", localPath, irodsPath, len(status.completed))
+		openMode = string(irodsclient_types.FileOpenModeReadWrite)
+
+		if len(resource) == 0 {
+			// open the replica written previously
+			resource = strings.Split(status.resourceHierarchy, ";")[0]
+		}
+	}
+
+	handle, err := irodsclient_irodsfs.OpenDataObjectForPutParallel(conn, irodsPath, resource, openMode, irodsclient_common.OPER_TYPE_NONE, numTasks, size)
+	if err != nil {
+		return "", xerrors.Errorf("failed to open %s: %w", irodsPath, err)
+	}
+
+	// data object info is cached in the file system
+	defer fs.ClearCache()
+
+	replicaToken, resourceHierarchy, err := irodsclient_irodsfs.GetReplicaAccessInfo(conn, handle)
+	if err != nil {
+		irodsclient_irodsfs.CloseDataObject(conn, handle)
+		return "", xerrors.Errorf("failed to get replica access info for %s: %w", irodsPath, err)
+	}
+
+	if !resume {
+		err = status.markReplica(resourceHierarchy)
+		if err != nil {
+			irodsclient_irodsfs.CloseDataObject(conn, handle)
+			return "", err
+		}
+	} else if resourceHierarchy != status.resourceHierarchy {
+		// chunks written are in another replica, upload all chunks on retry
+		irodsclient_irodsfs.CloseDataObject(conn, handle)
+		status.remove()
+		return "", xerrors.Errorf("failed to resume uploading %s, replica %s is opened instead of %s", irodsPath, resourceHierarchy, status.resourceHierarchy)
+	}
+
+	chunks, totalUploaded := status.getMissingChunks()
+
+	if callback != nil {
+		callback(totalUploaded, size)
+	}
+
+	// the file is read once from the start to the end, so it is hashed in order while tasks write segments read in parallel.
+	// buffers are reused to bound memory used by segments read but not written yet
+	segmentChan := make(chan *uploadSegment, numTasks)
+	bufferChan := make(chan []byte, numTasks*2)
+	for i := 0; i < numTasks*2; i++ {
+		bufferChan <- make([]byte, irodsclient_common.ReadWriteBufferSize)
+	}
+
+	stopChan := make(chan bool)
+	stopOnce := sync.Once{}
+	errChan := make(chan error, numTasks+1)
+
+	fail := func(err error) {
+		errChan <- err
+		stopOnce.Do(func() {
+			close(stopChan)
+		})
+	}
+
+	readSegments := func() error {
+		defer close(segmentChan)
+
+		f, err := os.Open(localPath)
+		if err != nil {
+			return xerrors.Errorf("failed to open file %s: %w", localPath, err)
+		}
+		defer f.Close()
+
+		missingChunks := map[int64]*uploadChunkProgress{}
+		for _, chunk := range chunks {
+			missingChunks[chunk.Offset] = &uploadChunkProgress{
+				uploadStatusChunk: chunk,
+				remaining:         chunk.Length,
+			}
+		}
+
+		// chunks written are read only for the hash
+		readChunks := chunks
+		if hashFunc != nil {
+			readChunks = status.getChunks()
+		}
+
+		hashBuffer := make([]byte, irodsclient_common.ReadWriteBufferSize)
+		for _, chunk := range readChunks {
+			chunkProgress, missing := missingChunks[chunk.Offset]
+
+			for offset := chunk.Offset; offset < chunk.Offset+chunk.Length; {
+				buffer := hashBuffer
+				if missing {
+					select {
+					case buffer = <-bufferChan:
+					case <-stopChan:
+						return nil
+					}
+				}
+
+				bufferLen := int64(len(buffer))
+				if chunk.Offset+chunk.Length-offset < bufferLen {
+					bufferLen = chunk.Offset + chunk.Length - offset
+				}
+
+				bytesRead, err := f.ReadAt(buffer[:bufferLen], offset)
+				if err != nil && !(err == io.EOF && int64(bytesRead) == bufferLen) {
+					return xerrors.Errorf("failed to read %s: %w", localPath, err)
+				}
+
+				if hashFunc != nil {
+					hashFunc.Write(buffer[:bytesRead])
+				}
+
+				if missing {
+					select {
+					case segmentChan <- &uploadSegment{chunk: chunkProgress, offset: offset, data: buffer[:bytesRead]}:
+					case <-stopChan:
+						return nil
+					}
+				}
+
+				offset += int64(bytesRead)
+			}
+		}
+
+		return nil
+	}
+
+	writeSegments := func(taskConn *irodsclient_connection.IRODSConnection, taskHandle *irodsclient_types.IRODSFileHandle) error {
+		currentOffset := int64(-1)
+		for segment := range segmentChan {
+			if currentOffset != segment.offset {
+				_, err := irodsclient_irodsfs.SeekDataObject(taskConn, taskHandle, segment.offset, irodsclient_types.SeekSet)
+				if err != nil {
+					return xerrors.Errorf("failed to seek %s to offset %d: %w", irodsPath, segment.offset, err)
+				}
+			}
+
+			err := irodsclient_irodsfs.WriteDataObject(taskConn, taskHandle, segment.data)
+			if err != nil {
+				return xerrors.Errorf("failed to write to %s: %w", irodsPath, err)
+			}
+
+			dataLen := int64(len(segment.data))
+			currentOffset = segment.offset + dataLen
+			bufferChan <- segment.data[:cap(segment.data)]
+
+			uploaded := atomic.AddInt64(&totalUploaded, dataLen)
+			if callback != nil {
+				callback(uploaded, size)
+			}
+
+			if atomic.AddInt64(&segment.chunk.remaining, -dataLen) == 0 {
+				err = status.markCompleted(segment.chunk.Offset, segment.chunk.Length)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	taskWaitGroup := sync.WaitGroup{}
+
+	runTask := func(task func() error) {
+		defer taskWaitGroup.Done()
+
+		taskErr := task()
+		if taskErr != nil {
+			fail(taskErr)
+
+			// drain segments to not block the reader, chunks left are written on retry
+			for segment := range segmentChan {
+				bufferChan <- segment.data[:cap(segment.data)]
+			}
+		}
+	}
+
+	taskWaitGroup.Add(1)
+	go runTask(readSegments)
+
+	// the first task writes through the handle opened
+	taskWaitGroup.Add(1)
+	go runTask(func() error {
+		return writeSegments(conn, handle)
+	})
+
+	for i := 1; i < numTasks; i++ {
+		taskWaitGroup.Add(1)
+		go runTask(func() error {
+			taskConn, taskErr := fs.GetIOConnection()
+			if taskErr != nil {
+				return xerrors.Errorf("failed to get connection: %w", taskErr)
+			}
+			defer fs.ReturnIOConnection(taskConn)
+
+			taskHandle, _, taskErr := irodsclient_irodsfs.OpenDataObjectWithReplicaToken(taskConn, irodsPath, resource, string(irodsclient_types.FileOpenModeWriteOnly), replicaToken, resourceHierarchy, numTasks, size)
+			if taskErr != nil {
+				return xerrors.Errorf("failed to open %s with replica token: %w", irodsPath, taskErr)
+			}
+
+			taskErr = writeSegments(taskConn, taskHandle)
+			closeErr := irodsclient_irodsfs.CloseDataObjectReplica(taskConn, taskHandle)
+			if taskErr != nil {
+				return taskErr
+			}
+
+			if closeErr != nil {
+				return xerrors.Errorf("failed to close %s: %w", irodsPath, closeErr)
+			}
+			return nil
+		})
+	}
+
+	taskWaitGroup.Wait()
+
+	if len(errChan) > 0 {
+		// data written is kept to resume
+		irodsclient_irodsfs.CloseDataObject(conn, handle)
+		return "", <-errChan
+	}
+
+	err = irodsclient_irodsfs.CloseDataObject(conn, handle)
+	if err != nil {
+		return "", xerrors.Errorf("failed to close %s: %w", irodsPath, err)
+	}
+
+	status.remove()
+
+	if hashFunc == nil {
+		return "", nil
+	}
+
+	return hex.EncodeToString(hashFunc.Sum(nil)), nil
+}
+
+// uploadChunkProgress is a chunk being written, it is completed when no bytes remain
+type uploadChunkProgress struct {
+	uploadStatusChunk
+	remaining int64
+}
+
+// uploadSegment is a part of a chunk read from the local file, to be written by a task
+type uploadSegment struct {
+	chunk  *uploadChunkProgress
+	offset int64
+	data   []byte
+}
+
+// UploadFileWithHash uploads a local file to the iRODS path, hashing the data read for the upload.
+// Returns the hash in hex string.
+func UploadFileWithHash(fs *irodsclient_fs.FileSystem, localPath string, irodsPath string, resource string, hashAlg string, callback TrackerCallBack) (string, error) {
+	hashFunc, err := NewHashFunc(hashAlg)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", irodsclient_types.NewFileNotFoundError(localPath)
+		}
+
+		return "", xerrors.Errorf("failed to open file %s: %w", localPath, err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return "", xerrors.Errorf("failed to stat %s: %w", localPath, err)
+	}
+
+	_, err = uploadFromReader(fs, io.TeeReader(f, hashFunc), irodsPath, resource, stat.Size(), callback)
+	if err != nil {
+		return "", xerrors.Errorf("failed to upload %s to %s: %w", localPath, irodsPath, err)
+	}
+
+	return hex.EncodeToString(hashFunc.Sum(nil)), nil
+}
+
+// getDataObject returns the data object with its replicas
+func getDataObject(fs *irodsclient_fs.FileSystem, irodsPath string) (*irodsclient_types.IRODSDataObject, error) {
+	connection, err := fs.GetMetadataConnection()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnMetadataConnection(connection)
+
+	collection, err := irodsclient_irodsfs.GetCollection(connection, path.Dir(irodsPath))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get collection %s: %w", path.Dir(irodsPath), err)
+	}
+
+	dataObject, err := irodsclient_irodsfs.GetDataObject(connection, collection, path.Base(irodsPath))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get data object %s: %w", irodsPath, err)
+	}
+
+	return dataObject, nil
+}
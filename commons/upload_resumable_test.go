@@ -0,0 +1,143 @@
+package commons
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadResumable(t *testing.T) {
+	t.Run("test UploadStatus", testUploadStatus)
+	t.Run("test UploadStatusMissingChunks", testUploadStatusMissingChunks)
+	t.Run("test UploadStatusCanResume", testUploadStatusCanResume)
+}
+
+func testUploadStatus(t *testing.T) {
+	tempDir := t.TempDir()
+	localPath := filepath.Join(tempDir, "data.bin")
+	irodsPath := "/zone/home/user/data.bin"
+
+	err := os.WriteFile(localPath, []byte("test data"), 0644)
+	assert.NoError(t, err)
+
+	stat, err := os.Stat(localPath)
+	assert.NoError(t, err)
+
+	header := newUploadStatusHeader(localPath, irodsPath, stat)
+	assert.False(t, HasUploadStatus(tempDir, localPath, irodsPath))
+
+	status, err := loadUploadStatus(tempDir, header)
+	assert.NoError(t, err)
+	assert.True(t, IsUploadStatusFilename(filepath.Base(status.path)))
+
+	err = status.open(false)
+	assert.NoError(t, err)
+	assert.NoError(t, status.markReplica("demoResc;leaf1"))
+	assert.NoError(t, status.markCompleted(0, UploadChunkSizeDefault))
+	assert.NoError(t, status.markCompleted(2*UploadChunkSizeDefault, UploadChunkSizeDefault))
+	status.close()
+
+	// cut last line like a killed process
+	statusFile, err := os.OpenFile(status.path, os.O_WRONLY|os.O_APPEND, 0644)
+	assert.NoError(t, err)
+	_, err = statusFile.WriteString("{\"offset\":")
+	assert.NoError(t, err)
+	statusFile.Close()
+
+	assert.True(t, HasUploadStatus(tempDir, localPath, irodsPath))
+
+	status, err = loadUploadStatus(tempDir, header)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int64{0: UploadChunkSizeDefault, 2 * UploadChunkSizeDefault: UploadChunkSizeDefault}, status.completed)
+	assert.Equal(t, "demoResc;leaf1", status.resourceHierarchy)
+
+	// another target does not resume
+	assert.False(t, HasUploadStatus(tempDir, localPath, "/zone/home/user/other.bin"))
+
+	// a changed file does not resume
+	otherHeader := header
+	otherHeader.Size++
+	status, err = loadUploadStatus(tempDir, otherHeader)
+	assert.NoError(t, err)
+	assert.Empty(t, status.completed)
+
+	status.remove()
+	assert.False(t, HasUploadStatus(tempDir, localPath, irodsPath))
+}
+
+func testUploadStatusMissingChunks(t *testing.T) {
+	status := &uploadStatus{
+		header: uploadStatusHeader{
+			Size:      250,
+			ChunkSize: 100,
+		},
+		completed: map[int64]int64{
+			0: 100,
+			// a chunk recorded with a different length is written again
+			100: 50,
+			200: 50,
+		},
+	}
+
+	chunks, writtenSize := status.getMissingChunks()
+	assert.Equal(t, []uploadStatusChunk{{Offset: 100, Length: 100}}, chunks)
+	assert.Equal(t, int64(150), writtenSize)
+
+	// all chunks are read for the hash when resuming
+	assert.Equal(t, []uploadStatusChunk{{Offset: 0, Length: 100}, {Offset: 100, Length: 100}, {Offset: 200, Length: 50}}, status.getChunks())
+
+	// nothing written
+	status.completed = map[int64]int64{}
+	chunks, writtenSize = status.getMissingChunks()
+	assert.Equal(t, []uploadStatusChunk{{Offset: 0, Length: 100}, {Offset: 100, Length: 100}, {Offset: 200, Length: 50}}, chunks)
+	assert.Equal(t, int64(0), writtenSize)
+
+	// empty file
+	status.header.Size = 0
+	chunks, writtenSize = status.getMissingChunks()
+	assert.Empty(t, chunks)
+	assert.Equal(t, int64(0), writtenSize)
+}
+
+func testUploadStatusCanResume(t *testing.T) {
+	status := &uploadStatus{
+		header: uploadStatusHeader{
+			Size:      250,
+			ChunkSize: 100,
+		},
+		resourceHierarchy: "demoResc;leaf1",
+		completed: map[int64]int64{
+			0:   100,
+			200: 50,
+		},
+	}
+
+	dataObject := &irodsclient_types.IRODSDataObject{
+		Size: 250,
+		Replicas: []*irodsclient_types.IRODSReplica{
+			{ResourceHierarchy: "demoResc;leaf2"},
+			{ResourceHierarchy: "demoResc;leaf1"},
+		},
+	}
+	assert.True(t, status.canResume(dataObject))
+
+	// the replica written is gone
+	dataObject.Replicas = dataObject.Replicas[:1]
+	assert.False(t, status.canResume(dataObject))
+
+	// truncated or overwritten by others
+	dataObject.Replicas = []*irodsclient_types.IRODSReplica{{ResourceHierarchy: "demoResc;leaf1"}}
+	dataObject.Size = 200
+	assert.False(t, status.canResume(dataObject))
+
+	dataObject.Size = 300
+	assert.False(t, status.canResume(dataObject))
+
+	// the replica was not recorded
+	dataObject.Size = 250
+	status.resourceHierarchy = ""
+	assert.False(t, status.canResume(dataObject))
+}
@@ -0,0 +1,212 @@
+package commons
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_common "github.com/cyverse/go-irodsclient/irods/common"
+	irodsclient_message "github.com/cyverse/go-irodsclient/irods/message"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"golang.org/x/xerrors"
+)
+
+// GenQuery select options for aggregation, go-irodsclient does not define them
+const (
+	querySelectSum   int = 4
+	querySelectCount int = 6
+)
+
+// CollectionUsage is a total size and a number of data-objects in a collection
+type CollectionUsage struct {
+	Path string
+	// Resource is a resource hierarchy of replicas, it is empty if usages are not split by resource
+	Resource string
+	Size     int64
+	Count    int64
+}
+
+// GetCollectionUsages returns usages of collections in the collection tree, computed by the server.
+// Each usage only counts data-objects directly in the collection.
+// A data-object is counted once by its first replica (replica number 0), unless usages are split by resource, then replicas in each resource are counted.
+func GetCollectionUsages(fs *irodsclient_fs.FileSystem, collectionPath string, byResource bool) ([]*CollectionUsage, error) {
+	connection, err := fs.GetMetadataConnection()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get connection: %w", err)
+	}
+	defer fs.ReturnMetadataConnection(connection)
+
+	connection.Lock()
+	defer connection.Unlock()
+
+	collCondition := GetCollectionTreeCondition(collectionPath)
+
+	usages := []*CollectionUsage{}
+
+	continueIndex := 0
+	for {
+		// non-aggregated columns are used for grouping
+		query := irodsclient_message.NewIRODSMessageQueryRequest(irodsclient_common.MaxQueryRows, continueIndex, 0, 0)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_COLL_NAME, 1)
+		if byResource {
+			query.AddSelect(irodsclient_common.ICAT_COLUMN_D_RESC_HIER, 1)
+		}
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_DATA_SIZE, querySelectSum)
+		query.AddSelect(irodsclient_common.ICAT_COLUMN_D_DATA_ID, querySelectCount)
+		query.AddCondition(collCondition.Column, collCondition.Condition)
+		if !byResource {
+			// count a data-object once regardless of its replicas
+			query.AddCondition(irodsclient_common.ICAT_COLUMN_DATA_REPL_NUM, "= '0'")
+		}
+
+		queryResult := irodsclient_message.IRODSMessageQueryResponse{}
+		err = connection.Request(query, &queryResult, nil)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to receive a collection usage query result message: %w", err)
+		}
+
+		err = queryResult.CheckError()
+		if err != nil {
+			if irodsclient_types.GetIRODSErrorCode(err) == irodsclient_common.CAT_NO_ROWS_FOUND {
+				// empty
+				break
+			}
+			return nil, xerrors.Errorf("received a collection usage query error: %w", err)
+		}
+
+		pagenatedUsages := make([]*CollectionUsage, queryResult.RowCount)
+		for row := 0; row < queryResult.RowCount; row++ {
+			pagenatedUsages[row] = &CollectionUsage{}
+		}
+
+		for _, sqlResult := range queryResult.SQLResult {
+			if len(sqlResult.Values) != queryResult.RowCount {
+				return nil, xerrors.Errorf("failed to receive collection usage rows - requires %d, but received %d attributes", queryResult.RowCount, len(sqlResult.Values))
+			}
+
+			for row, value := range sqlResult.Values {
+				switch sqlResult.AttributeIndex {
+				case int(irodsclient_common.ICAT_COLUMN_COLL_NAME):
+					pagenatedUsages[row].Path = value
+				case int(irodsclient_common.ICAT_COLUMN_D_RESC_HIER):
+					pagenatedUsages[row].Resource = value
+				case int(irodsclient_common.ICAT_COLUMN_DATA_SIZE):
+					size, err := parseAggregatedInt(value)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse data object size sum '%s': %w", value, err)
+					}
+					pagenatedUsages[row].Size = size
+				case int(irodsclient_common.ICAT_COLUMN_D_DATA_ID):
+					count, err := parseAggregatedInt(value)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to parse data object count '%s': %w", value, err)
+					}
+					pagenatedUsages[row].Count = count
+				}
+			}
+		}
+
+		usages = append(usages, pagenatedUsages...)
+
+		continueIndex = queryResult.ContinueIndex
+		if continueIndex == 0 {
+			break
+		}
+	}
+
+	return usages, nil
+}
+
+// parseAggregatedInt parses an aggregated value, some databases return sums in a decimal form
+func parseAggregatedInt(value string) (int64, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	if idx := strings.Index(value, "."); idx >= 0 {
+		value = value[:idx]
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// SummarizeCollectionUsages accumulates usages of sub-collections into their ancestors up to maxDepth levels below the root collection.
+// Negative maxDepth means no limit. Returned usages are sorted by path and resource.
+func SummarizeCollectionUsages(rootPath string, usages []*CollectionUsage, maxDepth int) []*CollectionUsage {
+	type usageKey struct {
+		Path     string
+		Resource string
+	}
+
+	summaryMap := map[usageKey]*CollectionUsage{}
+	for _, usage := range usages {
+		depth := getCollectionDepth(rootPath, usage.Path)
+		if depth < 0 {
+			continue
+		}
+
+		// walk up to the root, adding the usage to all ancestors within maxDepth
+		collPath := usage.Path
+		for {
+			if maxDepth < 0 || depth <= maxDepth {
+				key := usageKey{
+					Path:     collPath,
+					Resource: usage.Resource,
+				}
+
+				summary, ok := summaryMap[key]
+				if !ok {
+					summary = &CollectionUsage{
+						Path:     collPath,
+						Resource: usage.Resource,
+					}
+					summaryMap[key] = summary
+				}
+
+				summary.Size += usage.Size
+				summary.Count += usage.Count
+			}
+
+			if depth == 0 {
+				break
+			}
+
+			collPath = path.Dir(collPath)
+			depth--
+		}
+	}
+
+	summaries := make([]*CollectionUsage, 0, len(summaryMap))
+	for _, summary := range summaryMap {
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i int, j int) bool {
+		if summaries[i].Path != summaries[j].Path {
+			return summaries[i].Path < summaries[j].Path
+		}
+		return summaries[i].Resource < summaries[j].Resource
+	})
+
+	return summaries
+}
+
+// getCollectionDepth returns how many levels the collection is below the root collection, -1 if it is not under the root
+func getCollectionDepth(rootPath string, collectionPath string) int {
+	if collectionPath == rootPath {
+		return 0
+	}
+
+	prefix := rootPath + "/"
+	if rootPath == "/" {
+		prefix = "/"
+	}
+
+	if !strings.HasPrefix(collectionPath, prefix) {
+		return -1
+	}
+
+	return len(strings.Split(collectionPath[len(prefix):], "/"))
+}
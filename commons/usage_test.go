@@ -0,0 +1,57 @@
+package commons
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsage(t *testing.T) {
+	t.Run("test SummarizeCollectionUsages", testSummarizeCollectionUsages)
+}
+
+func testSummarizeCollectionUsages(t *testing.T) {
+	usages := []*CollectionUsage{
+		{Path: "/zone/home/user/a", Size: 100, Count: 2},
+		{Path: "/zone/home/user/a/b", Size: 10, Count: 1},
+		{Path: "/zone/home/user/a/b/c", Size: 1, Count: 1},
+		{Path: "/zone/home/user/a/d", Size: 5, Count: 1},
+		{Path: "/zone/home/user/ab", Size: 1000, Count: 1},
+	}
+
+	summaries := SummarizeCollectionUsages("/zone/home/user/a", usages, -1)
+	assert.Equal(t, 4, len(summaries))
+	assert.Equal(t, "/zone/home/user/a", summaries[0].Path)
+	assert.Equal(t, int64(116), summaries[0].Size)
+	assert.Equal(t, int64(5), summaries[0].Count)
+	assert.Equal(t, "/zone/home/user/a/b", summaries[1].Path)
+	assert.Equal(t, int64(11), summaries[1].Size)
+	assert.Equal(t, "/zone/home/user/a/b/c", summaries[2].Path)
+	assert.Equal(t, "/zone/home/user/a/d", summaries[3].Path)
+
+	summaries = SummarizeCollectionUsages("/zone/home/user/a", usages, 0)
+	assert.Equal(t, 1, len(summaries))
+	assert.Equal(t, int64(116), summaries[0].Size)
+
+	summaries = SummarizeCollectionUsages("/zone/home/user/a", usages, 1)
+	assert.Equal(t, 3, len(summaries))
+	assert.Equal(t, int64(11), summaries[1].Size)
+	assert.Equal(t, int64(2), summaries[1].Count)
+
+	resourceUsages := []*CollectionUsage{
+		{Path: "/zone/home/user/a", Resource: "rootResc;leaf1", Size: 100, Count: 2},
+		{Path: "/zone/home/user/a", Resource: "rootResc;leaf2", Size: 100, Count: 2},
+		{Path: "/zone/home/user/a/b", Resource: "rootResc;leaf1", Size: 10, Count: 1},
+	}
+
+	summaries = SummarizeCollectionUsages("/zone/home/user/a", resourceUsages, 0)
+	assert.Equal(t, 2, len(summaries))
+	assert.Equal(t, "rootResc;leaf1", summaries[0].Resource)
+	assert.Equal(t, int64(110), summaries[0].Size)
+	assert.Equal(t, "rootResc;leaf2", summaries[1].Resource)
+	assert.Equal(t, int64(100), summaries[1].Size)
+
+	summaries = SummarizeCollectionUsages("/", usages, 0)
+	assert.Equal(t, 1, len(summaries))
+	assert.Equal(t, int64(1116), summaries[0].Size)
+}